@@ -0,0 +1,188 @@
+// Package cluster spreads the computation of a range of Fibonacci numbers
+// across remote worker processes, each serving the gRPC API of
+// server/fibpb (massjunk serve -grpc-addr), and merges what they send back
+// into one set of results and, optionally, a local cache.
+//
+// The range is cut into one contiguous shard per worker. With the memoized
+// algorithm a worker computes every value below its shard too, so the
+// shards are far from equal in cost; what the cluster buys is throughput
+// across machines for workloads dominated by simulated work per value.
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/server/fibpb"
+)
+
+// Coordinator hands out shards of a range to a fixed set of workers.
+type Coordinator struct {
+	addrs   []string
+	conns   []*grpc.ClientConn
+	clients []fibpb.FibonacciClient
+}
+
+// Dial returns a Coordinator for the workers at the given addresses.
+// Connections are made lazily, so an unreachable worker is only reported
+// when a run needs it.
+func Dial(addrs []string) (*Coordinator, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("cluster: no workers")
+	}
+	c := &Coordinator{addrs: addrs}
+	for _, addr := range addrs {
+		cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("cluster: %s: %w", addr, err)
+		}
+		c.conns = append(c.conns, cc)
+		c.clients = append(c.clients, fibpb.NewFibonacciClient(cc))
+	}
+	return c, nil
+}
+
+// Close closes the connections to the workers.
+func (c *Coordinator) Close() error {
+	var errs []error
+	for _, cc := range c.conns {
+		errs = append(errs, cc.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Shard is the part of a run given to one worker.
+type Shard struct {
+	Worker string `json:"worker"`
+	From   int    `json:"from"`
+	To     int    `json:"to"`
+	// Results counts the results the worker sent back.
+	Results int           `json:"results"`
+	Elapsed time.Duration `json:"elapsed_ns"`
+	Err     error         `json:"-"`
+}
+
+// Report is the outcome of a distributed run.
+type Report struct {
+	// Results holds one Result per n, indexed by n. Those a worker never
+	// sent carry the error of its shard.
+	Results []fib.Result
+	Shards  []Shard
+	Elapsed time.Duration
+}
+
+// Split cuts [0, maxN] into at most parts contiguous shards of as equal a
+// size as possible, returning their bounds.
+func Split(maxN, parts int) [][2]int {
+	total := maxN + 1
+	parts = max(min(parts, total), 1)
+	shards := make([][2]int, parts)
+	for i := range parts {
+		shards[i] = [2]int{i * total / parts, (i+1)*total/parts - 1}
+	}
+	return shards
+}
+
+// Run computes F(0) through F(maxN) across the workers, one shard each,
+// and stores every value they return in cache, if it is not nil. The
+// error joins those of the shards that failed; the results of the others
+// are kept either way.
+func (c *Coordinator) Run(ctx context.Context, maxN int, cache fib.Cache) (Report, error) {
+	if maxN < 0 {
+		return Report{}, fmt.Errorf("cluster: maxN must be non-negative, got %d", maxN)
+	}
+	start := time.Now()
+	bounds := Split(maxN, len(c.clients))
+	rep := Report{Results: make([]fib.Result, maxN+1), Shards: make([]Shard, len(bounds))}
+	var wg sync.WaitGroup
+	for i, b := range bounds {
+		rep.Shards[i] = Shard{Worker: c.addrs[i], From: b[0], To: b[1]}
+		wg.Go(func() {
+			// Each shard writes only its own slots of Results.
+			c.runShard(ctx, c.clients[i], &rep.Shards[i], rep.Results, cache)
+		})
+	}
+	wg.Wait()
+	rep.Elapsed = time.Since(start)
+
+	var errs []error
+	for _, s := range rep.Shards {
+		if s.Err == nil {
+			continue
+		}
+		errs = append(errs, s.Err)
+		for n := s.From; n <= s.To; n++ {
+			if r := &rep.Results[n]; r.Value == nil && r.Err == nil {
+				*r = fib.Result{N: n, Err: s.Err}
+			}
+		}
+	}
+	return rep, errors.Join(errs...)
+}
+
+// runShard streams s's results from a worker into results.
+func (c *Coordinator) runShard(ctx context.Context, client fibpb.FibonacciClient, s *Shard, results []fib.Result, cache fib.Cache) {
+	start := time.Now()
+	defer func() { s.Elapsed = time.Since(start) }()
+	fail := func(err error) { s.Err = fmt.Errorf("cluster: %s: %w", s.Worker, err) }
+
+	stream, err := client.ComputeRange(ctx, &fibpb.ComputeRangeRequest{From: int64(s.From), To: int64(s.To)})
+	if err != nil {
+		fail(err)
+		return
+	}
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			fail(err)
+			return
+		}
+		r, err := fromProto(m)
+		if err == nil && (r.N < s.From || r.N > s.To) {
+			err = fmt.Errorf("sent n=%d, outside its shard", r.N)
+		}
+		if err != nil {
+			fail(err)
+			return
+		}
+		results[r.N] = r
+		s.Results++
+		if cache != nil && r.Value != nil {
+			cache.Store(r.N, new(big.Int).Set(r.Value))
+		}
+	}
+}
+
+// fromProto converts a result received from a worker.
+func fromProto(m *fibpb.Result) (fib.Result, error) {
+	r := fib.Result{
+		N:        int(m.N),
+		Duration: time.Duration(m.DurationNs),
+		Cached:   m.Cached,
+		Worker:   int(m.Worker),
+		Attempts: int(m.Attempts),
+	}
+	if m.Error != "" {
+		r.Err = errors.New(m.Error)
+		return r, nil
+	}
+	v, ok := new(big.Int).SetString(m.Value, 10)
+	if !ok {
+		return r, fmt.Errorf("sent F(%d) = %q, not a decimal integer", m.N, m.Value)
+	}
+	r.Value = v
+	return r, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// baseline is a saved set of bench results to compare later runs against.
+type baseline struct {
+	GoVersion  string        `json:"go_version"`
+	GOOS       string        `json:"goos"`
+	GOARCH     string        `json:"goarch"`
+	GOMAXPROCS int           `json:"gomaxprocs"`
+	Rows       []baselineRow `json:"rows"`
+}
+
+// baselineRow holds the metrics of one combination of the bench matrix.
+type baselineRow struct {
+	Name        string `json:"name"`
+	MeanNS      int64  `json:"mean_ns"`
+	BytesPerOp  uint64 `json:"bytes_per_op"`
+	AllocsPerOp uint64 `json:"allocs_per_op"`
+}
+
+// name identifies r's combination across bench invocations.
+func (r benchRow) name() string {
+	if r.isMatMul() {
+		return fmt.Sprintf("matmul/size=%d/workers=%d", r.maxN, r.workers)
+	}
+	return fmt.Sprintf("algorithm=%s/cache=%s%s/n=%d/workers=%d", r.algorithm, r.cache, r.schedulerKey(), r.maxN, r.workers)
+}
+
+// schedulerKey is the part of the names of r naming its scheduler: empty
+// for the shared queue, so that names from before there was a choice of
+// scheduler still match.
+func (r benchRow) schedulerKey() string {
+	if r.scheduler == fib.SharedQueue.Name() {
+		return ""
+	}
+	return "/scheduler=" + r.scheduler
+}
+
+func newBaseline(rows []benchRow) baseline {
+	b := baseline{
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+	}
+	for _, r := range rows {
+		if r.times == nil {
+			continue
+		}
+		bytes, allocs := r.meanMem()
+		b.Rows = append(b.Rows, baselineRow{
+			Name:        r.name(),
+			MeanNS:      summarize(r.times).mean.Nanoseconds(),
+			BytesPerOp:  bytes,
+			AllocsPerOp: allocs,
+		})
+	}
+	return b
+}
+
+func saveBaseline(path string, b baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func loadBaseline(path string) (baseline, error) {
+	var b baseline
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	if err := json.Unmarshal(data, &b); err != nil {
+		return b, fmt.Errorf("reading baseline %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// compareBaseline prints how each metric of cur changed since base and
+// returns the number that grew by more than threshold, a fraction.
+func compareBaseline(w io.Writer, base, cur baseline, threshold float64) (int, error) {
+	if base.GOOS != cur.GOOS || base.GOARCH != cur.GOARCH || base.GOMAXPROCS != cur.GOMAXPROCS {
+		fmt.Fprintf(w, "Go: Warning: the baseline was recorded on %s/%s with GOMAXPROCS %d, this run on %s/%s with GOMAXPROCS %d\n",
+			base.GOOS, base.GOARCH, base.GOMAXPROCS, cur.GOOS, cur.GOARCH, cur.GOMAXPROCS)
+	}
+	old := make(map[string]baselineRow, len(base.Rows))
+	for _, r := range base.Rows {
+		old[r.Name] = r
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "combination\tmetric\tbaseline\tcurrent\tchange\t")
+	regressions := 0
+	for _, r := range cur.Rows {
+		b, ok := old[r.Name]
+		if !ok {
+			fmt.Fprintf(tw, "%s\t\t\t\tnot in baseline\t\n", r.Name)
+			continue
+		}
+		for _, m := range []struct {
+			name      string
+			was, now  float64
+			formatted func(float64) string
+		}{
+			{"time/op", float64(b.MeanNS), float64(r.MeanNS), func(v float64) string { return fmt.Sprintf("%.0fns", v) }},
+			{"B/op", float64(b.BytesPerOp), float64(r.BytesPerOp), func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+			{"allocs/op", float64(b.AllocsPerOp), float64(r.AllocsPerOp), func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+		} {
+			verdict := ""
+			if regressed(m.was, m.now, threshold) {
+				verdict = "REGRESSED"
+				regressions++
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, m.name, m.formatted(m.was), m.formatted(m.now), change(m.was, m.now), verdict)
+		}
+	}
+	return regressions, tw.Flush()
+}
+
+// regressed reports whether now exceeds was by more than threshold, a
+// fraction. A metric that was zero regresses when it becomes nonzero.
+func regressed(was, now, threshold float64) bool {
+	if was == 0 {
+		return now > 0
+	}
+	return (now-was)/was > threshold
+}
+
+// percent is a flag holding a fraction, written as a percentage with or
+// without the % sign.
+type percent float64
+
+func (p *percent) String() string {
+	return strconv.FormatFloat(float64(*p)*100, 'f', -1, 64) + "%"
+}
+
+func (p *percent) Set(s string) error {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a percentage", s)
+	}
+	if v < 0 {
+		return fmt.Errorf("percentage must not be negative, got %s", s)
+	}
+	*p = percent(v / 100)
+	return nil
+}
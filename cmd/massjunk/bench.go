@@ -0,0 +1,531 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/matmul"
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/metrics"
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+// naiveBenchLimit is the largest maxN bench runs the naive algorithm for;
+// beyond it a single run takes minutes.
+const naiveBenchLimit = 25
+
+// matmulWorkload is the algorithm column of the rows benchmarking matrix
+// multiplication, for comparison with the latency-bound Fibonacci rows.
+const matmulWorkload = "matmul"
+
+// benchCaches are the cache kinds bench can compare.
+var benchCaches = map[string]func(lruSize int) fib.Cache{
+	"map":     func(int) fib.Cache { return fib.NewMapCache() },
+	"sharded": func(int) fib.Cache { return fib.NewShardedCache(fib.DefaultShards) },
+	"lru":     func(size int) fib.Cache { return fib.NewLRUCache(size) },
+}
+
+// benchOptions holds the flags of the bench command.
+type benchOptions struct {
+	calcOptions
+	profileOptions
+	traceOptions
+	maxNs       intList
+	threads     intList
+	algorithms  stringList
+	caches      stringList
+	schedulers  stringList
+	lruSize     int
+	repeat      int
+	sweep       bool
+	format      string
+	csvPath     string
+	metricsAddr string
+	matmulSize  int
+	matmulBlock int
+
+	baseline     string
+	saveBaseline string
+	threshold    percent
+}
+
+func benchCmd(ctx context.Context, args []string) error {
+	o := benchOptions{
+		maxNs:     intList{30},
+		caches:    stringList{"map"},
+		threshold: 0.10,
+	}
+	for _, alg := range fib.Algorithms() {
+		o.algorithms = append(o.algorithms, alg.Name())
+	}
+	fs := newFlagSet("bench")
+	o.calcOptions.register(fs)
+	o.profileOptions.register(fs)
+	o.traceOptions.register(fs)
+	fs.Var(&o.maxNs, "n", "comma-separated `list` of maxN values; each run calculates F(0) through F(maxN)")
+	fs.Var(&o.threads, "threads", "comma-separated `list` of worker counts to compare (default: -workers)")
+	fs.Var(&o.algorithms, "algorithms", "comma-separated `list` of algorithms to compare")
+	fs.Var(&o.caches, "caches", "comma-separated `list` of caches to compare: map, sharded or lru")
+	fs.Var(&o.schedulers, "schedulers", "comma-separated `list` of schedulers to compare: shared or stealing (default: -scheduler)")
+	fs.IntVar(&o.lruSize, "lru-size", 64, "capacity of the lru cache")
+	fs.IntVar(&o.repeat, "repeat", 3, "number of cold-cache runs per combination")
+	fs.BoolVar(&o.sweep, "sweep", false, "measure how throughput scales with 1, 2, 4, ... up to 4*GOMAXPROCS workers, instead of -threads")
+	fs.StringVar(&o.format, "format", "table", "output format: table, or benchstat for the go test -bench format")
+	fs.StringVar(&o.csvPath, "csv", "", "also write per-n timings of every run to this CSV `file`")
+	fs.IntVar(&o.matmulSize, "matmul", 0, "also multiply two `size` x size matrices at each worker count, a compute-bound workload to compare scheduling with (0 disables)")
+	fs.IntVar(&o.matmulBlock, "matmul-block", matmul.DefaultBlock, "side of the blocks of the -matmul product computed as separate tasks")
+	fs.StringVar(&o.metricsAddr, "metrics-addr", "", "serve Prometheus metrics at http://`address`/metrics while benchmarking")
+	fs.StringVar(&o.baseline, "baseline", "", "compare the results with this JSON `file` saved by -save-baseline, failing if any metric regressed beyond -threshold")
+	fs.StringVar(&o.saveBaseline, "save-baseline", "", "save the results as a baseline to this JSON `file`")
+	fs.Var(&o.threshold, "threshold", "with -baseline, how much worse time/op, B/op or allocs/op may get, as a `percentage` such as 10%")
+	if err := parseFlags(fs, "bench", args); err != nil {
+		return err
+	}
+	if err := o.setupLogging(); err != nil {
+		return err
+	}
+	if o.repeat < 1 {
+		return fmt.Errorf("-repeat must be at least 1, got %d", o.repeat)
+	}
+	if o.format != "table" && o.format != "benchstat" {
+		return fmt.Errorf("unknown -format %q (want table or benchstat)", o.format)
+	}
+	switch {
+	case o.sweep:
+		o.threads = sweepLevels(4 * runtime.GOMAXPROCS(0))
+	case len(o.threads) == 0:
+		o.threads = intList{o.workers}
+	}
+	if len(o.schedulers) == 0 {
+		o.schedulers = stringList{o.scheduler}
+	}
+	if o.metricsAddr != "" {
+		o.metrics = metrics.New()
+		stop, err := serveMetrics(o.metricsAddr, o.metrics)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+	stopTracing, err := o.traceOptions.start(ctx)
+	if err != nil {
+		return err
+	}
+	stopProfiling, err := o.profileOptions.start()
+	if err != nil {
+		return errors.Join(err, stopTracing())
+	}
+	return errors.Join(bench(ctx, o), stopProfiling(), stopTracing())
+}
+
+// benchCase is one combination of the bench matrix. For -matmul rows,
+// algorithm is matmulWorkload and maxN the size of the matrices.
+type benchCase struct {
+	algorithm string
+	cache     string
+	scheduler string
+	maxN      int
+	workers   int
+	results   int // tasks completed by each run, for throughput
+}
+
+// isMatMul reports whether c benchmarks matrix multiplication.
+func (c benchCase) isMatMul() bool { return c.algorithm == matmulWorkload }
+
+// stealing reports whether c runs on the work-stealing scheduler.
+func (c benchCase) stealing() bool { return c.scheduler == fib.WorkStealing.Name() }
+
+// cases expands the matrix of o into the combinations to run, grouped by
+// maxN so that each group can be compared against its first case.
+func (o benchOptions) cases() ([]benchCase, error) {
+	for _, name := range o.algorithms {
+		if _, err := fib.ParseAlgorithm(name); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range o.caches {
+		if benchCaches[name] == nil {
+			return nil, fmt.Errorf("unknown cache %q (want map, sharded or lru)", name)
+		}
+	}
+	for _, name := range o.schedulers {
+		if _, err := fib.ParseScheduler(name); err != nil {
+			return nil, err
+		}
+	}
+	var cases []benchCase
+	for _, maxN := range o.maxNs {
+		for _, alg := range o.algorithms {
+			for _, cache := range o.caches {
+				for _, sched := range o.schedulers {
+					for _, workers := range o.threads {
+						cases = append(cases, benchCase{alg, cache, sched, maxN, workers, maxN + 1})
+					}
+				}
+			}
+		}
+	}
+	if o.matmulSize > 0 {
+		for _, workers := range o.threads {
+			tasks := matmul.Tasks(o.matmulSize, o.matmulBlock)
+			cases = append(cases, benchCase{matmulWorkload, "-", "-", o.matmulSize, workers, tasks})
+		}
+	}
+	return cases, nil
+}
+
+// benchRow is the outcome of one combination of the bench matrix.
+type benchRow struct {
+	benchCase
+	times   []time.Duration // one per run; nil if skipped
+	mem     []fib.MemStats  // one per run
+	steals  []uint64        // one per run
+	skipped string          // why the combination was not run
+}
+
+// meanSteals returns the mean number of tasks stolen per run of r.
+func (r benchRow) meanSteals() uint64 {
+	var sum uint64
+	for _, s := range r.steals {
+		sum += s
+	}
+	return sum / uint64(max(len(r.steals), 1))
+}
+
+// meanMem returns the mean bytes and allocations per run of r.
+func (r benchRow) meanMem() (bytes, allocs uint64) {
+	for _, m := range r.mem {
+		bytes += m.Bytes
+		allocs += m.Allocs
+	}
+	n := uint64(max(len(r.mem), 1))
+	return bytes / n, allocs / n
+}
+
+// bench runs every combination of the matrix described by o and prints
+// the outcome in the -format chosen.
+func bench(ctx context.Context, o benchOptions) error {
+	cases, err := o.cases()
+	if err != nil {
+		return err
+	}
+	var base baseline
+	if o.baseline != "" {
+		// Read it first, so that a bad path doesn't waste a whole run.
+		if base, err = loadBaseline(o.baseline); err != nil {
+			return err
+		}
+	}
+	var csvOut *csvFile
+	if o.csvPath != "" {
+		if csvOut, err = createCSV(o.csvPath, "algorithm", "cache", "scheduler", "max_n", "workers", "run"); err != nil {
+			return err
+		}
+		defer csvOut.Close()
+	}
+
+	slog.Info("benchmarking Fibonacci", "combinations", len(cases), "runs", o.repeat)
+	rows := make([]benchRow, len(cases))
+	for i, bc := range cases {
+		rows[i].benchCase = bc
+		if bc.isMatMul() {
+			if err := benchMatMul(ctx, o, &rows[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if bc.algorithm == fib.Naive.Name() && bc.maxN > naiveBenchLimit {
+			rows[i].skipped = fmt.Sprintf("skipped (n > %d)", naiveBenchLimit)
+			continue
+		}
+		co := o.calcOptions
+		co.algorithm = bc.algorithm
+		co.scheduler = bc.scheduler
+		co.workers = bc.workers
+		newCache := benchCaches[bc.cache]
+		co.newCache = func() fib.Cache { return newCache(o.lruSize) }
+		rows[i].times = make([]time.Duration, o.repeat)
+		rows[i].mem = make([]fib.MemStats, o.repeat)
+		rows[i].steals = make([]uint64, o.repeat)
+		for run := range rows[i].times {
+			stopMem := fib.MeasureMemory()
+			results, d, ps, err := benchOnce(ctx, co, bc.maxN)
+			rows[i].mem[run] = stopMem()
+			if err != nil {
+				return fmt.Errorf("%s/%s/%s/n=%d/workers=%d: %w", bc.algorithm, bc.cache, bc.scheduler, bc.maxN, bc.workers, err)
+			}
+			if csvOut != nil {
+				csvOut.write(results, bc.algorithm, bc.cache, bc.scheduler, strconv.Itoa(bc.maxN), strconv.Itoa(bc.workers), strconv.Itoa(run+1))
+			}
+			rows[i].times[run] = d
+			rows[i].steals[run] = ps.Steals
+		}
+	}
+
+	switch {
+	case o.format == "benchstat":
+		err = printBenchstat(os.Stdout, rows)
+	case o.sweep:
+		err = printSweep(os.Stdout, rows)
+	default:
+		err = printBenchTable(os.Stdout, rows)
+	}
+	if err != nil {
+		return err
+	}
+	if csvOut != nil {
+		if err := csvOut.Close(); err != nil {
+			return err
+		}
+	}
+	cur := newBaseline(rows)
+	if o.saveBaseline != "" {
+		if err := saveBaseline(o.saveBaseline, cur); err != nil {
+			return err
+		}
+		slog.Info("saved the baseline", "file", o.saveBaseline)
+	}
+	if o.baseline == "" {
+		return nil
+	}
+	fmt.Println()
+	regressions, err := compareBaseline(os.Stdout, base, cur, float64(o.threshold))
+	if err != nil {
+		return err
+	}
+	if regressions > 0 {
+		return fmt.Errorf("%d metrics regressed by more than %v against %s", regressions, &o.threshold, o.baseline)
+	}
+	fmt.Printf("Go: No metric regressed by more than %v against %s\n", &o.threshold, o.baseline)
+	return nil
+}
+
+// printBenchTable prints rows as a comparison table with the mean, spread
+// and speedup of each combination. Speedups are relative to the first
+// combination for the same maxN.
+func printBenchTable(w io.Writer, rows []benchRow) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "algorithm\tcache\tscheduler\tn\tworkers\tmean\tstddev\tmin\tmax\tspeedup\tB/op\tallocs/op\tsteals/op\t")
+	var baseline time.Duration
+	for i, r := range rows {
+		if i == 0 || r.maxN != rows[i-1].maxN || r.isMatMul() != rows[i-1].isMatMul() {
+			baseline = 0
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t", r.algorithm, r.cache, r.scheduler, r.maxN, r.workers)
+		if r.times == nil {
+			fmt.Fprintf(tw, "%s\t\t\t\t\t\t\t\t\n", r.skipped)
+			continue
+		}
+		s := summarize(r.times)
+		if baseline == 0 {
+			baseline = s.mean
+		}
+		bytes, allocs := r.meanMem()
+		steals := "-"
+		if r.stealing() {
+			steals = strconv.FormatUint(r.meanSteals(), 10)
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%.2fx\t%d\t%d\t%s\t\n", s.mean, s.stddev, s.min, s.max, float64(baseline)/float64(s.mean), bytes, allocs, steals)
+	}
+	return tw.Flush()
+}
+
+// printBenchstat prints rows in the text format of go test -bench, one line
+// per run, so that the output of two invocations can be compared with
+// benchstat. Each run counts as a single operation computing F(0) through
+// F(maxN), or one matrix product, and results/s counts its tasks.
+func printBenchstat(w io.Writer, rows []benchRow) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "goos: %s\ngoarch: %s\npkg: github.com/ZapGaming/Mass-Junk-Code/fib\n", runtime.GOOS, runtime.GOARCH)
+	procs := runtime.GOMAXPROCS(0)
+	for _, r := range rows {
+		name := fmt.Sprintf("BenchmarkCalculate/algorithm=%s/cache=%s%s/n=%d/workers=%d", r.algorithm, r.cache, r.schedulerKey(), r.maxN, r.workers)
+		if r.isMatMul() {
+			name = fmt.Sprintf("BenchmarkMatMul/size=%d/tasks=%d/workers=%d", r.maxN, r.results, r.workers)
+		}
+		if procs > 1 {
+			name += "-" + strconv.Itoa(procs)
+		}
+		for run, d := range r.times {
+			fmt.Fprintf(bw, "%s\t%8d\t%12d ns/op\t%12.2f results/s\t%10d B/op\t%8d allocs/op",
+				name, 1, d.Nanoseconds(), float64(r.results)/d.Seconds(), r.mem[run].Bytes, r.mem[run].Allocs)
+			if r.stealing() {
+				fmt.Fprintf(bw, "\t%8d steals/op", r.steals[run])
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+	return bw.Flush()
+}
+
+// diminishingReturns is the throughput gain below which doubling the
+// workers is reported as no longer paying off.
+const diminishingReturns = 1.2
+
+// sweepLevels returns the worker counts 1, 2, 4, ... up to and including
+// limit.
+func sweepLevels(limit int) intList {
+	var levels intList
+	for w := 1; w < limit; w *= 2 {
+		levels = append(levels, w)
+	}
+	return append(levels, limit)
+}
+
+// printSweep prints the rows of a -sweep as scaling curves: the throughput
+// at each worker count, the speedup and parallel efficiency over a single
+// worker, and the first level at which adding workers gained less than
+// diminishingReturns.
+func printSweep(w io.Writer, rows []benchRow) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "algorithm\tcache\tscheduler\tn\tworkers\tmean\tresults/s\tspeedup\tefficiency\t\t")
+	var base, prev float64
+	flagged := false
+	for i, r := range rows {
+		if i == 0 || r.algorithm != rows[i-1].algorithm || r.cache != rows[i-1].cache || r.scheduler != rows[i-1].scheduler || r.maxN != rows[i-1].maxN {
+			base, prev, flagged = 0, 0, false
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t", r.algorithm, r.cache, r.scheduler, r.maxN, r.workers)
+		if r.times == nil {
+			fmt.Fprintf(tw, "%s\t\t\t\t\t\n", r.skipped)
+			continue
+		}
+		mean := summarize(r.times).mean
+		throughput := float64(r.results) / mean.Seconds()
+		if base == 0 {
+			base = throughput
+		}
+		note := ""
+		if prev > 0 && !flagged && throughput < prev*diminishingReturns {
+			note = "<- diminishing returns"
+			flagged = true
+		}
+		speedup := throughput / base
+		fmt.Fprintf(tw, "%v\t%.1f\t%.2fx\t%.0f%%\t%s\t\n", mean, throughput, speedup, 100*speedup/float64(r.workers), note)
+		prev = throughput
+	}
+	return tw.Flush()
+}
+
+// durationStats summarizes repeated timings of the same thing.
+type durationStats struct {
+	mean, stddev, min, max time.Duration
+}
+
+// summarize computes the mean, sample standard deviation, minimum and
+// maximum of ds, which must not be empty.
+func summarize(ds []time.Duration) durationStats {
+	s := durationStats{min: ds[0], max: ds[0]}
+	var sum float64
+	for _, d := range ds {
+		sum += float64(d)
+		s.min = min(s.min, d)
+		s.max = max(s.max, d)
+	}
+	mean := sum / float64(len(ds))
+	s.mean = time.Duration(mean)
+	if len(ds) > 1 {
+		var sq float64
+		for _, d := range ds {
+			sq += (float64(d) - mean) * (float64(d) - mean)
+		}
+		s.stddev = time.Duration(math.Sqrt(sq / float64(len(ds)-1)))
+	}
+	return s
+}
+
+// intList is a flag holding a comma-separated list of integers.
+type intList []int
+
+func (l *intList) String() string {
+	var parts []string
+	for _, v := range *l {
+		parts = append(parts, strconv.Itoa(v))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *intList) Set(s string) error {
+	var vals intList
+	for _, part := range strings.Split(s, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		vals = append(vals, v)
+	}
+	*l = vals
+	return nil
+}
+
+// stringList is a flag holding a comma-separated list of names.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(s string) error {
+	*l = nil
+	for _, part := range strings.Split(s, ",") {
+		*l = append(*l, strings.TrimSpace(part))
+	}
+	return nil
+}
+
+// serveMetrics starts serving m on addr in the background. The returned
+// function shuts the server down.
+func serveMetrics(addr string, m *metrics.Metrics) (stop func(), err error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m.Handler())
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	slog.Info("serving metrics", "url", fmt.Sprintf("http://%s/metrics", ln.Addr()))
+	return func() { srv.Close() }, nil
+}
+
+// benchMatMul times -repeat multiplications of two random matrices as
+// described by o, filling in r.
+func benchMatMul(ctx context.Context, o benchOptions, r *benchRow) error {
+	a, b := matmul.Random(r.maxN, 1), matmul.Random(r.maxN, 2)
+	r.times = make([]time.Duration, o.repeat)
+	r.mem = make([]fib.MemStats, o.repeat)
+	for run := range r.times {
+		stopMem := fib.MeasureMemory()
+		_, stats, err := matmul.Multiply(ctx, a, b, o.matmulBlock, r.workers)
+		r.mem[run] = stopMem()
+		if err != nil {
+			return fmt.Errorf("%s/size=%d/workers=%d: %w", matmulWorkload, r.maxN, r.workers, err)
+		}
+		r.times[run] = stats.Elapsed
+	}
+	return nil
+}
+
+// benchOnce times one run of o's algorithm on a fresh calculator, also
+// returning the statistics of its worker pool. The -cache-file, if any, is
+// loaded but not saved, so every run starts from the same state.
+func benchOnce(ctx context.Context, o calcOptions, maxN int) ([]fib.Result, time.Duration, pool.Stats, error) {
+	calc, closer, err := o.calculator()
+	if err != nil {
+		return nil, 0, pool.Stats{}, err
+	}
+	defer closer.Close()
+	results, d, err := calc.Calculate(ctx, maxN)
+	return results, d, calc.PoolStats(), err
+}
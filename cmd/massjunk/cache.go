@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// cacheActions are the actions of the cache command.
+var cacheActions = []struct{ name, summary string }{
+	{"info", "count the entries and the range of n they cover"},
+	{"warm", "compute F(0) through F(-n) into the cache and save it"},
+	{"dump", "print every entry"},
+	{"stats", "describe the entries and the file, or a served cache's hit rate with -server"},
+	{"clear", "empty the cache and save it"},
+}
+
+func cacheUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: massjunk cache <action> -cache-file path [flags]\n\nActions:")
+	for _, a := range cacheActions {
+		fmt.Fprintf(os.Stderr, "  %-6s %s\n", a.name, a.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun massjunk cache <action> -h for the flags of each action.")
+}
+
+func cacheCmd(ctx context.Context, args []string) error {
+	if len(args) > 0 && slices.Contains([]string{"help", "-h", "-help", "--help"}, args[0]) {
+		cacheUsage()
+		return flag.ErrHelp
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		cacheUsage()
+		return withExitCode(exitUsage, errors.New("no cache action given"))
+	}
+	action, args := args[0], args[1:]
+
+	var (
+		o      calcOptions
+		upTo   int
+		full   bool
+		server string
+	)
+	fs := newFlagSet("cache " + action)
+	o.register(fs)
+	switch action {
+	case "warm":
+		fs.IntVar(&upTo, "n", 100, "warm the cache with F(0) through F(`n`)")
+	case "dump":
+		fs.BoolVar(&full, "full", false, "print every digit of long values instead of abbreviating them")
+	case "stats", "clear":
+		fs.StringVar(&server, "server", "", "act on the cache of the massjunk serve at this `URL`, such as http://localhost:8080, instead")
+	case "info":
+	default:
+		// Reject it before building the cache, which may mean loading a
+		// large file or connecting to Redis.
+		cacheUsage()
+		return withExitCode(exitUsage, fmt.Errorf("unknown cache action %q", action))
+	}
+	if err := parseFlags(fs, "cache", args); err != nil {
+		return err
+	}
+	if err := o.setupLogging(); err != nil {
+		return err
+	}
+	if server != "" {
+		return remoteCache(ctx, action, strings.TrimSuffix(server, "/"))
+	}
+	if o.cacheFile == "" && o.redisAddr == "" {
+		return errors.New("-cache-file or -redis-addr is required")
+	}
+	calc, closer, err := o.calculator()
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	switch action {
+	case "info":
+		printCacheInfo(calc.Cache())
+	case "warm":
+		if err := calc.WarmCache(ctx, upTo); err != nil {
+			return err
+		}
+		if err := o.saveCache(calc); err != nil {
+			return err
+		}
+		printCacheInfo(calc.Cache())
+	case "dump":
+		return dumpCache(calc.Cache(), full)
+	case "stats":
+		printCacheStats(calc.Cache(), o.cacheFile)
+	case "clear":
+		n := calc.Cache().Len()
+		calc.Cache().Reset()
+		if err := o.saveCache(calc); err != nil {
+			return err
+		}
+		fmt.Printf("Go: Cleared %d entries\n", n)
+	}
+	return nil
+}
+
+// printCacheInfo summarizes the contents of c.
+func printCacheInfo(c fib.Cache) {
+	fmt.Printf("Go: Cache holds %d entries", c.Len())
+	r, ok := c.(fib.Ranger)
+	if !ok || c.Len() == 0 {
+		fmt.Println()
+		return
+	}
+	lo, hi := -1, -1
+	r.Range(func(n int, _ *big.Int) bool {
+		if lo < 0 || n < lo {
+			lo = n
+		}
+		hi = max(hi, n)
+		return true
+	})
+	fmt.Printf(" for n in [%d, %d]\n", lo, hi)
+}
+
+// cacheEntry is one value held by a cache.
+type cacheEntry struct {
+	n int
+	v *big.Int
+}
+
+// cacheEntries returns the entries of c in order of n.
+func cacheEntries(c fib.Cache) ([]cacheEntry, error) {
+	r, ok := c.(fib.Ranger)
+	if !ok {
+		return nil, fmt.Errorf("cache %T cannot list its entries", c)
+	}
+	var entries []cacheEntry
+	r.Range(func(n int, v *big.Int) bool {
+		entries = append(entries, cacheEntry{n, v})
+		return true
+	})
+	sort.Slice(entries, func(i, j int) bool { return entries[i].n < entries[j].n })
+	return entries, nil
+}
+
+// dumpCache prints every entry of c.
+func dumpCache(c fib.Cache, full bool) error {
+	entries, err := cacheEntries(c)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "n\tdigits\tvalue\t")
+	for _, e := range entries {
+		s := e.v.String()
+		value := s
+		if !full {
+			value = abbreviate(s)
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t\n", e.n, len(s), value)
+	}
+	return tw.Flush()
+}
+
+// printCacheStats describes the size of c, and of the file it was loaded
+// from, if any. A cache that was only loaded has served no lookups, so
+// there is no hit rate to report; ask a running server for that.
+func printCacheStats(c fib.Cache, file string) {
+	entries, err := cacheEntries(c)
+	if err != nil {
+		fmt.Printf("Go: Entries: %d\n", c.Len())
+		return
+	}
+	fmt.Printf("Go: Entries: %d\n", len(entries))
+	if len(entries) == 0 {
+		return
+	}
+	digits, gaps := 0, 0
+	for i, e := range entries {
+		digits += len(e.v.String())
+		if i > 0 {
+			gaps += e.n - entries[i-1].n - 1
+		}
+	}
+	last := entries[len(entries)-1]
+	fmt.Printf("Go: Range: n in [%d, %d], %d missing in between\n", entries[0].n, last.n, gaps)
+	fmt.Printf("Go: Digits: %d in total, %d in the largest value\n", digits, len(last.v.String()))
+	if file != "" {
+		if fi, err := os.Stat(file); err == nil {
+			fmt.Printf("Go: File: %s, %d bytes\n", file, fi.Size())
+		}
+	}
+}
+
+// remoteCache runs the stats or clear action against a running server.
+func remoteCache(ctx context.Context, action, server string) error {
+	method, path := http.MethodGet, "/cache/stats"
+	if action == "clear" {
+		method, path = http.MethodDelete, "/cache"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, server+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s", method, server+path, resp.Status)
+	}
+	if action == "clear" {
+		var body struct {
+			Cleared int `json:"cleared"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return err
+		}
+		fmt.Printf("Go: Cleared %d entries on %s\n", body.Cleared, server)
+		return nil
+	}
+	var stats fib.CacheStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return err
+	}
+	fmt.Printf("Go: Cache: %v\n", stats)
+	return nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/fib/rediscache"
+	"github.com/ZapGaming/Mass-Junk-Code/metrics"
+)
+
+// calcOptions are the flags shared by every command that builds a
+// fib.Calculator.
+type calcOptions struct {
+	logOptions
+	cpuOptions
+
+	workers       int
+	adaptive      bool
+	maxWorkers    int
+	work          time.Duration
+	workload      string
+	workDist      string
+	workSpread    time.Duration
+	cpuFraction   float64
+	seed          uint64
+	deterministic bool
+	sequence      string
+	algorithm     string
+	machineInts   bool
+	autoBig       bool
+	order         string
+	scheduler     string
+	timeout       time.Duration
+	runTimeout    time.Duration
+	maxHeap       byteSize
+	maxGoroutines int
+	stallTimeout  time.Duration
+	rate          float64
+	burst         int
+	retries       int
+	backoff       time.Duration
+	jitter        float64
+	chaos         fib.Chaos
+
+	cacheFile   string
+	redisAddr   string
+	cacheShards int
+
+	// metrics, if set, receives every result and watches the calculator.
+	metrics *metrics.Metrics
+	// onProgress, if set, becomes the calculator's OnProgress hook.
+	onProgress func(done, total int)
+	// onResult, if set, is called with every result, after metrics.
+	onResult func(fib.Result)
+	// onConcurrency, if set, receives the samples of an -adaptive run.
+	onConcurrency func(fib.ConcurrencySample)
+	// newCache, if set, makes the calculator's cache in place of the one
+	// chosen by the flags.
+	newCache func() fib.Cache
+}
+
+func (o *calcOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.workers, "workers", 4, "number of worker goroutines")
+	fs.BoolVar(&o.adaptive, "adaptive", false, "tune the number of concurrent computations to throughput and latency, starting from -workers")
+	fs.IntVar(&o.maxWorkers, "max-workers", 0, "with -adaptive, the most computations to run at once (0 means 4 x -workers)")
+	fs.DurationVar(&o.work, "work", fib.DefaultWork, "simulated work per computation step (0 disables it)")
+	fs.StringVar(&o.workload, "workload", "sleep", "kind of simulated work: sleep (IO-bound), spin (CPU-bound) or mixed")
+	fs.StringVar(&o.workDist, "work-dist", "constant", "distribution of simulated work durations around -work: constant, uniform, normal or exponential")
+	fs.DurationVar(&o.workSpread, "work-spread", 0, "half-width of the uniform distribution, or standard deviation of the normal one")
+	fs.Float64Var(&o.cpuFraction, "cpu-fraction", 0.5, "share of each unit of -workload mixed spent on the CPU")
+	fs.Uint64Var(&o.seed, "seed", 0, "seed for the random workload, chaos and retry jitter (0 picks one at random)")
+	fs.BoolVar(&o.deterministic, "deterministic", false, "run computations one at a time in a fixed order on simulated time, with -seed defaulting to 1, so runs are instant and identical every time")
+	fs.StringVar(&o.sequence, "sequence", fib.FibonacciSequence.Name(), "sequence to compute: "+sequenceNames())
+	fs.StringVar(&o.algorithm, "algorithm", fib.Memoized.Name(), "algorithm: naive, memoized, iterative or doubling")
+	fs.BoolVar(&o.machineInts, "machine-ints", false, fmt.Sprintf("use int64 arithmetic instead of math/big (n <= %d)", fib.MaxMachineN))
+	fs.StringVar(&o.order, "order", "asc", "order in which waiting computations start: asc (smallest n first) or desc")
+	fs.StringVar(&o.scheduler, "scheduler", fib.SharedQueue.Name(), "how runs hand computations to the workers: shared, one queue they all take from, or stealing, a queue per worker with idle workers stealing from busy ones")
+	fs.BoolVar(&o.autoBig, "auto-big", false, "with -machine-ints, redo computations that overflow with math/big instead of failing")
+	fs.DurationVar(&o.timeout, "timeout", 0, "fail any single computation that takes longer than this (0 means no limit)")
+	fs.DurationVar(&o.runTimeout, "deadline", 0, "cancel a whole run that takes longer than this, keeping the results so far (0 means no limit)")
+	fs.Var(&o.maxHeap, "max-heap", "stop a run, keeping the results so far, once the heap grows past this `size`, such as 512MiB or 2GB (0 means no limit)")
+	fs.IntVar(&o.maxGoroutines, "max-goroutines", 0, "fail at once any run or request that would need more worker goroutines than this at a time (0 means no limit)")
+	fs.DurationVar(&o.stallTimeout, "stall-timeout", 0, "abort a run, printing every goroutine's stack, if no computation finishes for this long (0 means never)")
+	fs.Float64Var(&o.rate, "rate", 0, "start at most this many computations per second (0 means no limit)")
+	fs.IntVar(&o.burst, "burst", 1, "with -rate, how many computations may start back to back")
+	fs.IntVar(&o.retries, "retries", 0, "retry a failed computation up to this many times")
+	fs.DurationVar(&o.backoff, "retry-backoff", fib.DefaultBackoff, "delay before the first retry, doubling with each one after")
+	fs.Float64Var(&o.jitter, "retry-jitter", 0.2, "randomize retry delays by up to this fraction")
+	fs.Float64Var(&o.chaos.FailRate, "chaos-fail", 0, "probability that a unit of simulated work fails")
+	fs.Float64Var(&o.chaos.PanicRate, "chaos-panic", 0, "probability that a unit of simulated work panics")
+	fs.Float64Var(&o.chaos.SpikeRate, "chaos-spike", 0, "probability that a unit of simulated work is delayed by -chaos-spike-delay")
+	fs.DurationVar(&o.chaos.Spike, "chaos-spike-delay", 0, "latency added by a spike (0 means 100ms)")
+	fs.StringVar(&o.cacheFile, "cache-file", "", "load the cache from this file before the run and save it afterwards")
+	fs.StringVar(&o.redisAddr, "redis-addr", "", "share the cache through the Redis server at this address")
+	fs.IntVar(&o.cacheShards, "cache-shards", 0, "use a sharded in-memory cache with this many shards")
+	o.logOptions.register(fs)
+	o.cpuOptions.register(fs)
+}
+
+// config translates the flags into a fib.Config. Closing the returned closer
+// releases any connection the cache holds.
+func (o *calcOptions) config() (fib.Config, io.Closer, error) {
+	alg, err := fib.ParseAlgorithm(o.algorithm)
+	if err != nil {
+		return fib.Config{}, nil, err
+	}
+	seq, err := fib.ParseSequence(o.sequence)
+	if err != nil {
+		return fib.Config{}, nil, err
+	}
+	if seq != fib.FibonacciSequence && (o.cacheFile != "" || o.redisAddr != "") {
+		// Those caches are shared with runs that expect Fibonacci numbers.
+		return fib.Config{}, nil, fmt.Errorf("-sequence %s cannot be used with -cache-file or -redis-addr", seq.Name())
+	}
+	work := o.work
+	if work == 0 {
+		work = -1 // the flag's 0 means "no work", fib.Config's means "default"
+	}
+	workload, err := o.workloadConfig()
+	if err != nil {
+		return fib.Config{}, nil, err
+	}
+	var priority func(int) int
+	switch o.order {
+	case "asc":
+		priority = fib.SmallestFirst
+	case "desc":
+		priority = fib.LargestFirst
+	default:
+		return fib.Config{}, nil, fmt.Errorf("unknown -order %q (want asc or desc)", o.order)
+	}
+	sched, err := fib.ParseScheduler(o.scheduler)
+	if err != nil {
+		return fib.Config{}, nil, err
+	}
+	cfg := fib.Config{
+		Workers:       o.workers,
+		Work:          work,
+		Workload:      workload,
+		Seed:          o.seed,
+		Sequence:      seq,
+		Algorithm:     alg,
+		MachineInts:   o.machineInts,
+		AutoBig:       o.autoBig,
+		Priority:      priority,
+		Scheduler:     sched,
+		Timeout:       o.timeout,
+		RunTimeout:    o.runTimeout,
+		MaxHeap:       uint64(o.maxHeap),
+		MaxGoroutines: o.maxGoroutines,
+		StallTimeout:  o.stallTimeout,
+		RateLimit:     o.rate,
+		RateBurst:     o.burst,
+		Chaos:         o.chaos,
+		Retry:         fib.RetryPolicy{MaxAttempts: o.retries + 1, Backoff: o.backoff, Jitter: o.jitter},
+		Logger:        slog.Default(),
+		Deterministic: o.deterministic,
+	}
+
+	if o.adaptive {
+		cfg.Adaptive = &fib.Adaptive{Max: o.maxWorkers}
+	}
+
+	var closer io.Closer = nopCloser{}
+	switch {
+	case o.cacheShards > 0:
+		cfg.Cache = fib.NewShardedCache(o.cacheShards)
+	case o.redisAddr != "":
+		rc, err := rediscache.New(rediscache.Options{Addr: o.redisAddr})
+		if err != nil {
+			return fib.Config{}, nil, fmt.Errorf("connecting to Redis: %w", err)
+		}
+		cfg.Cache, closer = rc, rc
+	}
+	return cfg, closer, nil
+}
+
+// sequenceNames lists the registered sequences for the -sequence usage.
+func sequenceNames() string {
+	var names []string
+	for _, s := range fib.Sequences() {
+		names = append(names, s.Name())
+	}
+	return strings.Join(names, ", ")
+}
+
+// workloadConfig translates the workload flags into a fib.Workload. It
+// returns nil, leaving the choice to fib.Config.Work, when there is no work
+// to simulate.
+func (o *calcOptions) workloadConfig() (fib.Workload, error) {
+	if o.work <= 0 {
+		return nil, nil
+	}
+	var dist fib.Distribution
+	switch o.workDist {
+	case "constant":
+		dist = fib.Constant(o.work)
+	case "uniform":
+		dist = fib.Uniform{Min: max(o.work-o.workSpread, 0), Max: o.work + o.workSpread}
+	case "normal":
+		dist = fib.Normal{Mean: o.work, StdDev: o.workSpread}
+	case "exponential":
+		dist = fib.Exponential{Mean: o.work}
+	default:
+		return nil, fmt.Errorf("unknown -work-dist %q (want constant, uniform, normal or exponential)", o.workDist)
+	}
+	switch o.workload {
+	case "sleep":
+		return fib.Sleep{Dist: dist}, nil
+	case "spin":
+		return fib.Spin{Dist: dist}, nil
+	case "mixed":
+		return fib.Mixed{Dist: dist, CPUFraction: o.cpuFraction}, nil
+	}
+	return nil, fmt.Errorf("unknown -workload %q (want sleep, spin or mixed)", o.workload)
+}
+
+// calculator builds a calculator from the flags, loading the -cache-file if
+// one was given.
+func (o *calcOptions) calculator() (*fib.Calculator, io.Closer, error) {
+	if err := o.cpuOptions.apply(); err != nil {
+		return nil, nil, err
+	}
+	cfg, closer, err := o.config()
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.metrics != nil {
+		cfg.OnResult = o.metrics.Observer(cfg.Algorithm.Name())
+	}
+	if o.onResult != nil {
+		if observe := cfg.OnResult; observe != nil {
+			cfg.OnResult = func(r fib.Result) { observe(r); o.onResult(r) }
+		} else {
+			cfg.OnResult = o.onResult
+		}
+	}
+	cfg.OnProgress = o.onProgress
+	if cfg.Adaptive != nil {
+		cfg.Adaptive.OnSample = o.onConcurrency
+	}
+	if o.newCache != nil {
+		closer.Close()
+		cfg.Cache, closer = o.newCache(), nopCloser{}
+	}
+	calc := fib.New(cfg)
+	if o.metrics != nil {
+		o.metrics.Watch(calc)
+	}
+	if o.cacheFile != "" {
+		if err := fib.LoadCacheIfExists(calc.Cache(), o.cacheFile); err != nil {
+			closer.Close()
+			return nil, nil, err
+		}
+	}
+	return calc, closer, nil
+}
+
+// saveCache writes calc's cache back to the -cache-file, if one was given.
+func (o *calcOptions) saveCache(calc *fib.Calculator) error {
+	if o.cacheFile == "" {
+		return nil
+	}
+	return fib.SaveCache(calc.Cache(), o.cacheFile)
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// checkpointOptions are the flags that save a run's progress as it goes,
+// and pick an interrupted run back up.
+type checkpointOptions struct {
+	checkpoint string
+	every      time.Duration
+	resume     string
+}
+
+func (o *checkpointOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.checkpoint, "checkpoint", "", "save the results so far and the cache to this `file` periodically and when the run ends")
+	fs.DurationVar(&o.every, "checkpoint-every", 30*time.Second, "how often to save the -checkpoint")
+	fs.StringVar(&o.resume, "resume", "", "continue the run saved in this checkpoint `file`, computing only what it lacks (implies -checkpoint to the same file)")
+}
+
+// load reads the -resume checkpoint, if one was given, and makes it the
+// -checkpoint unless another file was named.
+func (o *checkpointOptions) load() (*fib.Checkpoint, error) {
+	if o.resume == "" {
+		return nil, nil
+	}
+	cp, err := fib.LoadCheckpoint(o.resume)
+	if err != nil {
+		return nil, err
+	}
+	if o.checkpoint == "" {
+		o.checkpoint = o.resume
+	}
+	return &cp, nil
+}
+
+// checkpointer saves the progress of a run to a file every so often, and
+// once more when the run ends.
+type checkpointer struct {
+	path  string
+	maxN  int
+	every time.Duration
+
+	mu      sync.Mutex
+	results []fib.Result
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newCheckpointer returns a checkpointer for a run up to maxN that starts
+// with the results of prior, if it is resuming one.
+func newCheckpointer(path string, every time.Duration, maxN int, prior *fib.Checkpoint) *checkpointer {
+	c := &checkpointer{path: path, maxN: maxN, every: every}
+	if prior != nil {
+		c.results = append(c.results, prior.Results...)
+	}
+	return c
+}
+
+// observe records a result; it is the run's onResult hook.
+func (c *checkpointer) observe(r fib.Result) {
+	c.mu.Lock()
+	c.results = append(c.results, r)
+	c.mu.Unlock()
+}
+
+// start begins saving calc's progress in the background. The returned
+// function stops that and saves a final checkpoint.
+func (c *checkpointer) start(calc *fib.Calculator) (stop func() error) {
+	c.stop, c.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(c.done)
+		tick := time.NewTicker(c.every)
+		defer tick.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-tick.C:
+				if err := c.save(calc); err != nil {
+					slog.Warn("saving checkpoint failed", "file", c.path, "error", err)
+				}
+			}
+		}
+	}()
+	return func() error {
+		close(c.stop)
+		<-c.done
+		return c.save(calc)
+	}
+}
+
+func (c *checkpointer) save(calc *fib.Calculator) error {
+	c.mu.Lock()
+	results := slices.Clone(c.results)
+	c.mu.Unlock()
+	cp := calc.Checkpoint(c.maxN, results)
+	if err := fib.SaveCheckpoint(c.path, cp); err != nil {
+		return fmt.Errorf("saving checkpoint: %w", err)
+	}
+	slog.Debug("saved checkpoint", "file", c.path, "done", cp.Done(), "total", c.maxN+1)
+	return nil
+}
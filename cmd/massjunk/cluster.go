@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ZapGaming/Mass-Junk-Code/cluster"
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+func clusterCmd(ctx context.Context, args []string) error {
+	var (
+		lo        logOptions
+		peers     string
+		maxN      int
+		cacheFile string
+		asJSON    bool
+	)
+	fs := newFlagSet("cluster")
+	lo.register(fs)
+	fs.StringVar(&peers, "peers", "", "comma-separated `addresses` of workers started with massjunk serve -grpc-addr")
+	fs.IntVar(&maxN, "n", 1000, "calculate Fibonacci numbers 0 through `maxN` across the workers")
+	fs.StringVar(&cacheFile, "cache-file", "", "merge every value the workers return into this cache file")
+	fs.BoolVar(&asJSON, "json", false, "print the results and shards as JSON")
+	if err := parseFlags(fs, "cluster", args); err != nil {
+		return err
+	}
+	if err := lo.setupLogging(); err != nil {
+		return err
+	}
+	if peers == "" {
+		return errors.New("-peers is required")
+	}
+	coord, err := cluster.Dial(strings.Split(peers, ","))
+	if err != nil {
+		return err
+	}
+	defer coord.Close()
+
+	var cache fib.Cache
+	if cacheFile != "" {
+		cache = fib.NewMapCache()
+		if err := fib.LoadCacheIfExists(cache, cacheFile); err != nil {
+			return err
+		}
+	}
+	rep, runErr := coord.Run(ctx, maxN, cache)
+	if err := printCluster(rep, maxN, asJSON); err != nil {
+		return err
+	}
+	if cache != nil {
+		if err := fib.SaveCache(cache, cacheFile); err != nil {
+			return errors.Join(runErr, err)
+		}
+		slog.Info("merged results into the cache", "file", cacheFile, "entries", cache.Len())
+	}
+	return runErr
+}
+
+// printCluster reports a distributed run: the shards, then the summary.
+func printCluster(rep cluster.Report, maxN int, asJSON bool) error {
+	type shard struct {
+		cluster.Shard
+		Error string `json:"error,omitempty"`
+	}
+	shards := make([]shard, len(rep.Shards))
+	for i, s := range rep.Shards {
+		shards[i] = shard{Shard: s}
+		if s.Err != nil {
+			shards[i].Error = s.Err.Error()
+		}
+	}
+	latency := latencies(rep.Results)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			ElapsedNS int64        `json:"elapsed_ns"`
+			Shards    []shard      `json:"shards"`
+			Results   []fib.Result `json:"results"`
+			Latency   any          `json:"latency"`
+		}{rep.Elapsed.Nanoseconds(), shards, rep.Results, latency})
+	}
+
+	fmt.Printf("Go: Calculated Fibonacci numbers up to %d on %d workers in %v\n", maxN, len(rep.Shards), rep.Elapsed)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "worker\trange\tresults\telapsed\terror")
+	for _, s := range shards {
+		fmt.Fprintf(tw, "%s\t%d-%d\t%d\t%v\t%s\n", s.Worker, s.From, s.To, s.Results, s.Elapsed, s.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if latency.Count > 0 {
+		fmt.Printf("Go: Latency: p50 %v, p90 %v, p99 %v, max %v\n", latency.P50, latency.P90, latency.P99, latency.Max)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// ANSI escape sequences for coloring table rows.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// useColor reports whether what is written to f should be colored: only if
+// f is a terminal and none of -no-color, the NO_COLOR environment variable
+// (see no-color.org) or TERM=dumb says otherwise.
+func (o *logOptions) useColor(f *os.File) bool {
+	if o.noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// resultColor is the color of r's row in a table of results: red if it
+// failed, yellow if it took longer than slow, green if it came from the
+// cache, and none otherwise.
+func resultColor(r fib.Result, slow time.Duration) string {
+	switch {
+	case r.Err != nil:
+		return ansiRed
+	case r.Duration > slow:
+		return ansiYellow
+	case r.Cached:
+		return ansiGreen
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix starts the name of every environment variable massjunk reads:
+// flag -cache-file, for instance, can be set with MASSJUNK_CACHE_FILE.
+const envPrefix = "MASSJUNK_"
+
+// parseFlags parses args into fs and then fills in every flag that was not
+// given on the command line, first from the environment and then from the
+// -config file. A config file is YAML (or JSON) whose keys are flag names;
+// top-level keys apply to every command and a section named after the
+// command overrides them for that command alone:
+//
+//	workers: 8
+//	work: 2ms
+//	run:
+//	  n: 40
+//	  output: table
+//	bench:
+//	  repeat: 5
+//
+// Top-level keys that the command has no flag for are ignored, so one file
+// can serve several commands; unknown keys in the command's own section are
+// reported as errors.
+func parseFlags(fs *flag.FlagSet, command string, args []string) error {
+	configPath := fs.String("config", os.Getenv(envPrefix+"CONFIG"), "read flag defaults from this YAML `file`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	fileValues, err := readConfig(*configPath, command, fs)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || f.Name == "config" {
+			return
+		}
+		v, ok := os.LookupEnv(envName(f.Name))
+		source := "$" + envName(f.Name)
+		if !ok {
+			v, ok = fileValues[f.Name]
+			source = *configPath
+		}
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, v); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: invalid value %q for %s: %v", source, v, f.Name, err))
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// envName returns the environment variable that overrides the named flag.
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// readConfig loads the config file at path, if any, and returns the flag
+// values it sets for command, as strings ready for flag.Set.
+func readConfig(path, command string, fs *flag.FlagSet) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	var section map[string]any
+	for k, v := range doc {
+		if m, ok := v.(map[string]any); ok {
+			if k == command {
+				section = m
+			}
+			continue // another command's section
+		}
+		if fs.Lookup(k) != nil {
+			values[k] = fmt.Sprint(v)
+		}
+	}
+	for k, v := range section {
+		if fs.Lookup(k) == nil {
+			return nil, fmt.Errorf("%s: %s: unknown setting %q", path, command, k)
+		}
+		values[k] = fmt.Sprint(v)
+	}
+	return values, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"math"
+	"runtime"
+)
+
+// cpuOptions are the flags that limit how many CPUs a run may use, so that
+// results from machines of different sizes can be compared.
+type cpuOptions struct {
+	gomaxprocs int
+	cores      float64
+}
+
+func (o *cpuOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.gomaxprocs, "gomaxprocs", 0, "set GOMAXPROCS, the number of CPUs running Go code at once (0 leaves it alone)")
+	fs.Float64Var(&o.cores, "cores", 0, "set GOMAXPROCS to this `fraction` of the CPUs, such as 0.5 for half, rounded up (0 leaves it alone)")
+}
+
+// set reports whether either flag was given.
+func (o *cpuOptions) set() bool { return o.gomaxprocs != 0 || o.cores != 0 }
+
+// apply sets GOMAXPROCS as the flags ask.
+func (o *cpuOptions) apply() error {
+	procs := o.gomaxprocs
+	switch {
+	case o.gomaxprocs != 0 && o.cores != 0:
+		return errors.New("-gomaxprocs and -cores cannot be used together")
+	case o.gomaxprocs < 0:
+		return errors.New("-gomaxprocs must be positive")
+	case o.cores < 0 || o.cores > 1:
+		return errors.New("-cores must be a fraction between 0 and 1")
+	case o.cores > 0:
+		procs = max(int(math.Ceil(o.cores*float64(runtime.NumCPU()))), 1)
+	}
+	if procs == 0 {
+		return nil
+	}
+	prev := runtime.GOMAXPROCS(procs)
+	if prev != procs {
+		slog.Debug("set GOMAXPROCS", "gomaxprocs", procs, "was", prev, "cpus", runtime.NumCPU())
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/sink"
+)
+
+// csvFile writes per-n timings to a CSV file for charting in a spreadsheet,
+// with the columns of a CSV sink. Callers may put extra columns, such as the
+// algorithm in bench mode, in front of the standard ones.
+type csvFile struct {
+	f *os.File
+	w *csv.Writer
+}
+
+// createCSV creates the file at path and writes the header row, prefixed by
+// the names of any extra columns.
+func createCSV(path string, extra ...string) (*csvFile, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &csvFile{f: f, w: csv.NewWriter(f)}
+	c.w.Write(append(extra, sink.CSVColumns...))
+	return c, nil
+}
+
+// write adds one row per result, each prefixed by the extra column values.
+func (c *csvFile) write(results []fib.Result, extra ...string) {
+	for _, r := range results {
+		c.w.Write(append(extra, sink.CSVRecord(r)...))
+	}
+}
+
+// Close flushes the rows and closes the file, reporting any write error.
+func (c *csvFile) Close() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		c.f.Close()
+		return err
+	}
+	return c.f.Close()
+}
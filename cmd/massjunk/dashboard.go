@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// dashboardInterval is how often the dashboard is redrawn and throughput
+// sampled.
+const dashboardInterval = 250 * time.Millisecond
+
+// dashboardHistory is how many throughput samples the sparkline shows.
+const dashboardHistory = 48
+
+// sparks are the sparkline's bars, from lowest to highest.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// dashboard redraws a block of live statistics about a run in place on a
+// terminal: busy workers, queue depth, progress, cache hit rate and a
+// sparkline of recent throughput. With controls, typing p and Enter pauses
+// or resumes the run.
+type dashboard struct {
+	w       io.Writer
+	maxN    int
+	started time.Time
+
+	done  atomic.Int64 // results so far, from the OnProgress hook
+	total atomic.Int64
+
+	interactive bool         // whether controls is reading commands
+	echoed      atomic.Int64 // command lines echoed below the frame
+
+	// Owned by the drawing goroutine.
+	calc  *fib.Calculator
+	last  int64     // done at the previous sample
+	rates []float64 // results per second, oldest first
+	lines int       // lines drawn by the previous frame
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newDashboard(w io.Writer, maxN int) *dashboard {
+	return &dashboard{w: w, maxN: maxN, started: time.Now(), quit: make(chan struct{})}
+}
+
+// update is the fib.Config.OnProgress hook; it only records the counts,
+// leaving the drawing to the dashboard's own goroutine.
+func (d *dashboard) update(done, total int) {
+	d.done.Store(int64(done))
+	d.total.Store(int64(total))
+}
+
+// start begins redrawing the dashboard for calc every dashboardInterval.
+// The returned function draws the final frame and stops.
+func (d *dashboard) start(calc *fib.Calculator) (stop func()) {
+	d.calc = calc
+	d.total.Store(int64(d.maxN + 1))
+	d.wg.Go(func() {
+		t := time.NewTicker(dashboardInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-d.quit:
+				d.sample(time.Since(d.started) - time.Duration(len(d.rates))*dashboardInterval)
+				d.draw()
+				return
+			case <-t.C:
+				d.sample(dashboardInterval)
+				d.draw()
+			}
+		}
+	})
+	return func() {
+		close(d.quit)
+		d.wg.Wait()
+	}
+}
+
+// controls carries out the commands in lines, such as those from
+// terminalLines, until the dashboard stops: p pauses the run of calc, or
+// resumes it if it is paused. Call it before start. The terminal echoes the
+// commands below the frame; the next frame clears them.
+func (d *dashboard) controls(lines <-chan string, calc *fib.Calculator) {
+	d.interactive = true
+	d.wg.Go(func() {
+		for {
+			select {
+			case <-d.quit:
+				return
+			case line := <-lines:
+				d.echoed.Add(1)
+				if strings.TrimSpace(line) != "p" {
+					continue
+				}
+				if calc.RunsPaused() {
+					calc.ResumeRuns()
+				} else {
+					calc.PauseRuns()
+				}
+			}
+		}
+	})
+}
+
+// terminalLines returns the lines typed on standard input, or nil if it
+// isn't a terminal. A read can't be interrupted, so the goroutine reading
+// them lives as long as the process; call this before taking a goroutine
+// baseline for leak checks.
+var terminalLines = sync.OnceValue(func() <-chan string {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(os.Stdin)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+	return lines
+})
+
+// sample records the throughput over the last interval.
+func (d *dashboard) sample(interval time.Duration) {
+	done := d.done.Load()
+	rate := 0.0
+	if interval > 0 {
+		rate = float64(done-d.last) / interval.Seconds()
+	}
+	d.last = done
+	d.rates = append(d.rates, rate)
+}
+
+// draw replaces the previous frame with the current one.
+func (d *dashboard) draw() {
+	var b strings.Builder
+	if up := d.lines + int(d.echoed.Swap(0)); up > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", up) // back to the top of the frame
+	}
+	done, total := d.done.Load(), max(d.total.Load(), 1)
+	workers, active := d.calc.Workers(), d.calc.Active()
+	stats := d.calc.CacheStats()
+	lines := []string{
+		fmt.Sprintf("massjunk  n 0..%d  %v", d.maxN, time.Since(d.started).Round(100*time.Millisecond)),
+		fmt.Sprintf("Workers  %s %d/%d busy", meter(active, workers), active, workers),
+		fmt.Sprintf("Queue    %d waiting", d.calc.QueueDepth()),
+		fmt.Sprintf("Done     %s %d/%d (%d%%)", meter(int(done), int(total)), done, total, 100*done/total),
+		fmt.Sprintf("Cache    %.1f%% hit rate, %d hits, %d misses, %d shared", 100*stats.HitRate(), stats.Hits, stats.Misses, stats.Shared),
+		fmt.Sprintf("Rate     %8.1f/s %s", d.rates[len(d.rates)-1], sparkline(d.rates, dashboardHistory)),
+		"State    " + d.state(),
+	}
+	for _, l := range lines {
+		b.WriteString("\x1b[2K") // clear what the previous frame left
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	b.WriteString("\x1b[J") // and any echoed commands below it
+	d.lines = len(lines)
+	io.WriteString(d.w, b.String())
+}
+
+// state describes whether the run is paused, and how to change that.
+func (d *dashboard) state() string {
+	paused := d.calc.RunsPaused()
+	switch {
+	case !d.interactive && paused:
+		return "paused"
+	case !d.interactive:
+		return "running"
+	case paused:
+		return "paused, enter p to resume"
+	}
+	return "running, enter p to pause"
+}
+
+// meter draws a 20-cell bar filled in proportion to n of total.
+func meter(n, total int) string {
+	const width = 20
+	filled := min(width*n/max(total, 1), width)
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("·", width-filled) + "]"
+}
+
+// sparkline draws the last width of values as bars scaled to their maximum.
+func sparkline(values []float64, width int) string {
+	values = values[max(len(values)-width, 0):]
+	top := 0.0
+	for _, v := range values {
+		top = max(top, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if top > 0 {
+			i = int(v / top * float64(len(sparks)-1))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+)
+
+// demoWorkload is one of the workloads run by the demo command.
+type demoWorkload struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, o *demoOptions, args []string) error
+}
+
+var demoWorkloads = []demoWorkload{
+	{"sieve", "count primes with a parallel segmented sieve", sieveDemo},
+	{"pi", "estimate π by Monte Carlo sampling", monteCarloDemo},
+	{"mergesort", "sort random integers with a parallel merge sort", mergeSortDemo},
+	{"mandelbrot", "render the Mandelbrot set to a PNG, tile by tile", mandelbrotDemo},
+	{"collatz", "find Collatz stopping times with a shared memo cache", collatzDemo},
+	{"matmul", "multiply dense matrices block by block", matMulDemo},
+	{"sha256", "measure SHA-256 throughput across workers", hashingDemo},
+	{"wordcount", "count word frequencies across files, map-reduce style", wordCountDemo},
+	{"life", "step Conway's Game of Life in bands that swap halo rows", lifeDemo},
+	{"queens", "count N-queens solutions, one task per first-row column", queensDemo},
+}
+
+// demoOptions holds the flags shared by every demo workload.
+type demoOptions struct {
+	logOptions
+	workers int
+	json    bool
+}
+
+func (o *demoOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.workers, "workers", 4, "number of worker goroutines")
+	fs.BoolVar(&o.json, "json", false, "print the result and task statistics as JSON")
+	o.logOptions.register(fs)
+}
+
+// parse parses a workload's flags, including the shared ones.
+func (o *demoOptions) parse(fs *flag.FlagSet, args []string) error {
+	o.register(fs)
+	if err := parseFlags(fs, "demo", args); err != nil {
+		return err
+	}
+	if o.workers < 1 {
+		return fmt.Errorf("-workers must be at least 1, got %d", o.workers)
+	}
+	return o.setupLogging()
+}
+
+// report prints a workload's result, either as the given summary lines
+// followed by the task statistics or, with -json, as a JSON document.
+func (o *demoOptions) report(result any, stats demo.Stats, lines ...string) error {
+	if o.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Result any        `json:"result"`
+			Stats  demo.Stats `json:"stats"`
+		}{result, stats})
+	}
+	for _, l := range lines {
+		fmt.Println("Go:", l)
+	}
+	l := stats.Latency
+	fmt.Printf("Go: %d tasks on %d workers in %v\n", stats.Tasks, stats.Workers, stats.Elapsed)
+	fmt.Printf("Go: Task latency: p50 %v, p90 %v, p99 %v, max %v\n", l.P50, l.P90, l.P99, l.Max)
+	return nil
+}
+
+func demoUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: massjunk demo <workload> [flags]\n\nWorkloads:")
+	for _, d := range demoWorkloads {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", d.name, d.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun massjunk demo <workload> -h for the flags of each workload.")
+}
+
+func demoCmd(ctx context.Context, args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") || args[0] == "help" {
+		demoUsage()
+		return flag.ErrHelp
+	}
+	name, args := args[0], args[1:]
+	for _, d := range demoWorkloads {
+		if d.name == name {
+			return d.run(ctx, new(demoOptions), args)
+		}
+	}
+	demoUsage()
+	return fmt.Errorf("unknown demo workload %q", name)
+}
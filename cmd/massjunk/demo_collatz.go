@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/collatz"
+)
+
+func collatzDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo collatz")
+	from := fs.Int("from", 1, "first starting value")
+	to := fs.Int("to", 1_000_000, "last starting value")
+	chunk := fs.Int("chunk", collatz.DefaultChunk, "starting values covered by each task")
+	cacheName := fs.String("cache", "sharded", "cache for chain lengths: map, sharded or lru")
+	lruSize := fs.Int("lru-size", 100_000, "capacity of the lru cache")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	newCache, ok := benchCaches[*cacheName]
+	if !ok {
+		return fmt.Errorf("unknown -cache %q (want map, sharded or lru)", *cacheName)
+	}
+	r, stats, err := collatz.Range(ctx, *from, *to, newCache(*lruSize), *chunk, o.workers)
+	if err != nil {
+		return err
+	}
+	return o.report(r, stats,
+		fmt.Sprintf("Longest chain from %d to %d starts at %d: %d steps", r.From, r.To, r.Longest, r.Steps),
+		fmt.Sprintf("Cache: %v", r.Cache))
+}
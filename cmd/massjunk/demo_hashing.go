@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/hashing"
+)
+
+func hashingDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo sha256")
+	size := fs.Int("size", 1<<20, "size in bytes of each buffer hashed")
+	count := fs.Int("count", 1024, "number of buffers to hash")
+	sweep := fs.Bool("sweep", false, "repeat with 1, 2, 4, ... up to 4*GOMAXPROCS workers, instead of -workers")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	if *size < 1 {
+		return fmt.Errorf("-size must be at least 1, got %d", *size)
+	}
+	bufs := hashing.Buffers(*size, 1)
+	if !*sweep {
+		r, stats, err := hashing.Hash(ctx, bufs, *count, o.workers)
+		if err != nil {
+			return err
+		}
+		return o.report(r, stats,
+			fmt.Sprintf("Hashed %s at %.1f MB/s, digest %s", formatBytes(uint64(r.Bytes)), r.MBps, r.Digest[:16]))
+	}
+
+	// Scaling table: ideal scaling doubles the throughput with the
+	// workers until they outnumber the CPUs.
+	fmt.Printf("%8s  %12s  %8s  %10s\n", "workers", "MB/s", "speedup", "efficiency")
+	var base float64
+	for _, workers := range sweepLevels(4 * runtime.GOMAXPROCS(0)) {
+		r, _, err := hashing.Hash(ctx, bufs, *count, workers)
+		if err != nil {
+			return err
+		}
+		if base == 0 {
+			base = r.MBps
+		}
+		speedup := r.MBps / base
+		fmt.Printf("%8d  %12.1f  %7.2fx  %9.0f%%\n", workers, r.MBps, speedup, 100*speedup/float64(workers))
+	}
+	return nil
+}
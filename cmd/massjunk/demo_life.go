@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/life"
+)
+
+func lifeDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo life")
+	width := fs.Int("width", 512, "grid width in cells")
+	height := fs.Int("height", 512, "grid height in cells")
+	density := fs.Float64("density", 0.3, "fraction of cells alive at the start")
+	generations := fs.Int("generations", 200, "generations to simulate")
+	seed := fs.Uint64("seed", 1, "seed for the starting grid")
+	render := fs.Bool("render", false, "draw the grid on the terminal after every generation")
+	delay := fs.Duration("delay", 100*time.Millisecond, "pause after drawing each generation with -render")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	if *width < 1 || *height < 1 {
+		return fmt.Errorf("grid must be at least 1x1, got %dx%d", *width, *height)
+	}
+	var draw func(int, *life.Grid)
+	if *render {
+		draw = func(gen int, g *life.Grid) {
+			// Home the cursor and clear the screen before each frame.
+			fmt.Fprintf(os.Stdout, "\x1b[H\x1b[2J%sGeneration %d, %d alive\n", g, gen, g.Alive())
+			select {
+			case <-ctx.Done():
+			case <-time.After(*delay):
+			}
+		}
+	}
+	g := life.Random(*width, *height, *density, *seed)
+	res, stats, err := life.Run(ctx, g, *generations, o.workers, draw)
+	if err != nil {
+		return err
+	}
+	return o.report(res, stats,
+		fmt.Sprintf("Simulated %d generations of a %dx%d grid in %d bands; %d cells alive",
+			res.Generations, *width, *height, res.Bands, res.Alive))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image/png"
+	"os"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/mandelbrot"
+)
+
+func mandelbrotDemo(ctx context.Context, o *demoOptions, args []string) error {
+	var m mandelbrot.Options
+	fs := newFlagSet("demo mandelbrot")
+	fs.IntVar(&m.Width, "width", 1600, "image width in pixels")
+	fs.IntVar(&m.Height, "height", 1200, "image height in pixels")
+	fs.Float64Var(&m.CenterX, "x", -0.75, "real part of the centre of the view")
+	fs.Float64Var(&m.CenterY, "y", 0, "imaginary part of the centre of the view")
+	fs.Float64Var(&m.Scale, "scale", 3.5, "width of the view in the complex plane")
+	fs.IntVar(&m.MaxIter, "max-iter", 512, "iterations before a point counts as inside the set")
+	fs.IntVar(&m.Tile, "tile", 64, "side of the square tiles rendered as separate tasks")
+	out := fs.String("out", "mandelbrot.png", "write the image to this PNG `file`")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	img, stats, err := mandelbrot.Render(ctx, m, o.workers)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return o.report(struct {
+		File   string `json:"file"`
+		Width  int    `json:"width"`
+		Height int    `json:"height"`
+	}{*out, m.Width, m.Height}, stats,
+		fmt.Sprintf("Rendered %dx%d to %s", m.Width, m.Height, *out))
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/matmul"
+)
+
+func matMulDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo matmul")
+	size := fs.Int("size", 1024, "multiply two `n` x n matrices")
+	block := fs.Int("block", matmul.DefaultBlock, "side of the blocks of the product computed as separate tasks")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	if *size < 1 {
+		return fmt.Errorf("-size must be at least 1, got %d", *size)
+	}
+	a, b := matmul.Random(*size, 1), matmul.Random(*size, 2)
+	_, stats, err := matmul.Multiply(ctx, a, b, *block, o.workers)
+	if err != nil {
+		return err
+	}
+	gflops := matmul.Flops(*size) / stats.Elapsed.Seconds() / 1e9
+	return o.report(struct {
+		Size   int     `json:"size"`
+		GFLOPS float64 `json:"gflops"`
+	}{*size, gflops}, stats,
+		fmt.Sprintf("Multiplied two %dx%d matrices at %.2f GFLOP/s", *size, *size, gflops))
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/mergesort"
+)
+
+func mergeSortDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo mergesort")
+	size := fs.Int("size", 10_000_000, "number of random integers to sort")
+	cutoff := fs.Int("cutoff", mergesort.DefaultCutoff, "length below which a piece is sorted sequentially")
+	seed := fs.Uint64("seed", 1, "seed for the generated data")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	if *size < 0 {
+		return fmt.Errorf("-size must be non-negative, got %d", *size)
+	}
+	data := mergesort.Generate(*size, *seed)
+	s := slices.Clone(data)
+	stats, err := mergesort.Sort(ctx, s, *cutoff, o.workers)
+	if err != nil {
+		return err
+	}
+	if !slices.IsSorted(s) {
+		return errors.New("mergesort: result is not sorted")
+	}
+
+	// Sort the same data sequentially for comparison.
+	start := time.Now()
+	slices.Sort(data)
+	sequential := time.Since(start)
+
+	return o.report(struct {
+		Size         int   `json:"size"`
+		SequentialNS int64 `json:"sequential_ns"`
+	}{*size, sequential.Nanoseconds()}, stats,
+		fmt.Sprintf("Sorted %d integers in %v; slices.Sort took %v (%.2fx)",
+			*size, stats.Elapsed, sequential, float64(sequential)/float64(stats.Elapsed)))
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/montecarlo"
+)
+
+func monteCarloDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo pi")
+	samples := fs.Int64("samples", 100_000_000, "number of random points to draw")
+	batch := fs.Int("batch", montecarlo.DefaultBatch, "points drawn by each task")
+	seed := fs.Uint64("seed", 0, "seed for the workers' generators (0 picks one at random)")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	r, stats, err := montecarlo.Estimate(ctx, *samples, *batch, o.workers, *seed)
+	if err != nil {
+		return err
+	}
+	lines := []string{"Convergence:"}
+	for _, p := range r.Convergence {
+		lines = append(lines, fmt.Sprintf("  %12d samples  π ≈ %.8f  error %.2e", p.Samples, p.Pi, p.Error))
+	}
+	lines = append(lines, fmt.Sprintf("π ≈ %.8f from %d samples, error %.2e (standard error %.2e)", r.Pi, r.Samples, r.Error, r.StdErr))
+	return o.report(r, stats, lines...)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/queens"
+)
+
+func queensDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo queens")
+	n := fs.Int("n", 12, "place `n` queens on an n×n board")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	r, stats, err := queens.Count(ctx, *n, o.workers)
+	if err != nil {
+		return err
+	}
+	return o.report(r, stats,
+		fmt.Sprintf("%d solutions to %d-queens", r.Solutions, r.N),
+		fmt.Sprintf("Rate: %.0f solutions/s", r.Rate))
+}
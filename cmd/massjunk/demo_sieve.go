@@ -0,0 +1,23 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/sieve"
+)
+
+func sieveDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo sieve")
+	limit := fs.Int("limit", 10_000_000, "count the primes up to `n`")
+	segment := fs.Int("segment", sieve.DefaultSegment, "integers sieved by each task")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	r, stats, err := sieve.Count(ctx, *limit, *segment, o.workers)
+	if err != nil {
+		return err
+	}
+	return o.report(r, stats,
+		fmt.Sprintf("%d primes up to %d, the largest %d", r.Count, r.Limit, r.Largest))
+}
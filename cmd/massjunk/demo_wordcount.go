@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo/wordcount"
+)
+
+func wordCountDemo(ctx context.Context, o *demoOptions, args []string) error {
+	fs := newFlagSet("demo wordcount")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: massjunk demo wordcount [flags] file-or-glob...")
+		fs.PrintDefaults()
+	}
+	top := fs.Int("top", 20, "print the `n` most frequent words (0 prints them all)")
+	if err := o.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no files given; usage: massjunk demo wordcount [flags] file-or-glob...")
+	}
+	files, err := wordcount.Expand(fs.Args())
+	if err != nil {
+		return err
+	}
+	r, stats, err := wordcount.Count(ctx, files, o.workers)
+	if err != nil {
+		return err
+	}
+	if *top > 0 && len(r.Table) > *top {
+		r.Table = r.Table[:*top]
+	}
+	lines := []string{fmt.Sprintf("%d words, %d distinct, in %d files", r.Words, r.Distinct, r.Files)}
+	for _, e := range r.Table {
+		lines = append(lines, fmt.Sprintf("%8d  %s", e.Count, e.Word))
+	}
+	return o.report(r, stats, lines...)
+}
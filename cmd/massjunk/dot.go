@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// dotRecorder is a span processor that keeps the spans of a run in memory,
+// to draw its call graph for Graphviz: one node per fib.Compute or fib.sub
+// span, under the run or value that needed it, coloured by whether the
+// value was computed, found in the cache or waited for while another
+// worker computed it.
+type dotRecorder struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (r *dotRecorder) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (r *dotRecorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	switch s.Name() {
+	case "fib.Calculate", "fib.Compute", "fib.sub":
+		r.mu.Lock()
+		r.spans = append(r.spans, s)
+		r.mu.Unlock()
+	}
+}
+
+func (r *dotRecorder) Shutdown(context.Context) error   { return nil }
+func (r *dotRecorder) ForceFlush(context.Context) error { return nil }
+
+// Node colours of the call graph.
+const (
+	dotComputed = "#cfe2f3"
+	dotCached   = "#d9ead3"
+	dotShared   = "#fff2cc"
+	dotFailed   = "#f4cccc"
+)
+
+// save writes the call graph to path in the DOT language.
+func (r *dotRecorder) save(path string) error {
+	r.mu.Lock()
+	spans := slices.Clone(r.spans)
+	r.mu.Unlock()
+	// Spans end children first; draw them in the order they started.
+	slices.SortStableFunc(spans, func(a, b sdktrace.ReadOnlySpan) int {
+		return a.StartTime().Compare(b.StartTime())
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "digraph fib {")
+	fmt.Fprintln(w, "\trankdir=TB;")
+	fmt.Fprintln(w, `	node [shape=box, style="rounded,filled", fontname="Helvetica", fontsize=10];`)
+	fmt.Fprintln(w, `	subgraph cluster_legend {`)
+	fmt.Fprintln(w, `		label="legend"; fontname="Helvetica"; fontsize=10;`)
+	fmt.Fprintf(w, "\t\tlegend_computed [label=\"computed\", fillcolor=%q];\n", dotComputed)
+	fmt.Fprintf(w, "\t\tlegend_cached [label=\"cache hit\", fillcolor=%q];\n", dotCached)
+	fmt.Fprintf(w, "\t\tlegend_shared [label=\"shared with another worker\", fillcolor=%q];\n", dotShared)
+	fmt.Fprintf(w, "\t\tlegend_failed [label=\"failed\", fillcolor=%q];\n", dotFailed)
+	fmt.Fprintln(w, "\t}")
+
+	ids := make(map[trace.SpanID]bool, len(spans))
+	for _, s := range spans {
+		ids[s.SpanContext().SpanID()] = true
+	}
+	for _, s := range spans {
+		id := s.SpanContext().SpanID()
+		label, color := dotNode(s)
+		fmt.Fprintf(w, "\tn%s [label=%q, fillcolor=%q];\n", id, label, color)
+		if parent := s.Parent().SpanID(); ids[parent] {
+			fmt.Fprintf(w, "\tn%s -> n%s;\n", parent, id)
+		}
+	}
+	fmt.Fprintln(w, "}")
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// dotNode returns the label and colour of the node for s.
+func dotNode(s sdktrace.ReadOnlySpan) (label, color string) {
+	var (
+		n              int64
+		maxN           int64
+		cached, shared bool
+	)
+	for _, kv := range s.Attributes() {
+		switch kv.Key {
+		case "fib.n":
+			n = kv.Value.AsInt64()
+		case "fib.max_n":
+			maxN = kv.Value.AsInt64()
+		case "fib.cached":
+			cached = kv.Value.AsBool()
+		case "fib.shared":
+			shared = kv.Value.AsBool()
+		}
+	}
+	d := s.EndTime().Sub(s.StartTime()).Round(time.Microsecond)
+	if s.Name() == "fib.Calculate" {
+		return fmt.Sprintf("Calculate 0..%d\n%v", maxN, d), "white"
+	}
+	color = dotComputed
+	switch {
+	case s.Status().Code == codes.Error:
+		color = dotFailed
+	case cached:
+		color = dotCached
+	case shared:
+		color = dotShared
+	}
+	return fmt.Sprintf("F(%d)\n%v", n, d), color
+}
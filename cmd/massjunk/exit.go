@@ -0,0 +1,61 @@
+package main
+
+import "errors"
+
+// The exit statuses of massjunk, listed by massjunk help. Scripts and CI
+// jobs can tell the outcomes of a run apart by them, even with -quiet.
+const (
+	exitFailed      = 1   // the command failed, or its flags were wrong
+	exitUsage       = 2   // there is no such command, or no such action of one
+	exitPartial     = 3   // run finished, but some computations failed
+	exitTimeout     = 4   // run ran out of time: -deadline, -timeout or -stall-timeout
+	exitVerify      = 5   // run's -verify or -self-check found wrong results
+	exitInterrupted = 130 // the command was interrupted by a signal
+)
+
+// exitStatuses describes the exit statuses for massjunk help.
+const exitStatuses = `Exit status:
+  0    success
+  1    failure, such as bad flags
+  2    unknown command, or a missing or unknown action
+  3    run finished, but some computations failed
+  4    run timed out (-deadline, -timeout or -stall-timeout)
+  5    run's -verify or -self-check found wrong results
+  130  interrupted`
+
+// exitError is an error that ends massjunk with a particular status.
+type exitError struct {
+	code int
+	err  error
+	// silent keeps main from printing it, for -quiet.
+	silent bool
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode makes err, if there is one, end massjunk with status code.
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// silence keeps main from printing err, keeping its exit status.
+func silence(err error) error {
+	if err == nil {
+		return nil
+	}
+	ee := &exitError{code: exitFailed, err: err}
+	errors.As(err, &ee)
+	return &exitError{code: ee.code, err: err, silent: true}
+}
+
+// exitCode returns the exit status for err and whether to print it.
+func exitCode(err error) (code int, print bool) {
+	if ee := (*exitError)(nil); errors.As(err, &ee) {
+		return ee.code, !ee.silent
+	}
+	return exitFailed, true
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// gcOptions are the flags of run that tune the garbage collector, and
+// measure what the tuning saves.
+type gcOptions struct {
+	gogc    int
+	ballast byteSize
+	tune    bool
+	compare bool
+}
+
+// The settings of -gc-tune. The memoized algorithm allocates a new big.Int
+// at every step while keeping little of it live, so the collector runs far
+// more often than the heap's size calls for.
+const (
+	tunedGOGC    = 400
+	tunedBallast = 64 << 20
+)
+
+func (o *gcOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.gogc, "gogc", 0, "set GOGC, the heap growth in `percent` that triggers a collection; negative turns the collector off (0 leaves it alone)")
+	fs.Var(&o.ballast, "ballast", "hold an untouched heap ballast of this `size`, such as 64MiB, so that the collector runs less often")
+	fs.BoolVar(&o.tune, "gc-tune", false, fmt.Sprintf("tune the collector for the allocation-heavy recursive algorithms: -gogc %d and a %s -ballast, unless those are given", tunedGOGC, formatBytes(tunedBallast)))
+	fs.BoolVar(&o.compare, "gc-compare", false, "first repeat the calculation with the collector untuned and a cold cache, then report the GC cycles of both runs")
+}
+
+// set reports whether any tuning was asked for.
+func (o *gcOptions) set() bool { return o.tune || o.gogc != 0 || o.ballast != 0 }
+
+// ballast keeps the -ballast allocation reachable for the rest of the
+// process. Its pages are never written, so it costs address space rather
+// than memory, but the collector counts it as live heap and so waits for
+// the heap to grow by a share of it too before collecting.
+var ballast []byte
+
+// check rejects a -gc-compare with nothing to compare.
+func (o *gcOptions) check() error {
+	if o.compare && !o.set() {
+		return errors.New("-gc-compare needs -gc-tune, -gogc or -ballast to compare against")
+	}
+	return nil
+}
+
+// apply tunes the collector as the flags ask.
+func (o *gcOptions) apply() {
+	if o.tune {
+		if o.gogc == 0 {
+			o.gogc = tunedGOGC
+		}
+		if o.ballast == 0 {
+			o.ballast = tunedBallast
+		}
+	}
+	if o.gogc != 0 {
+		prev := debug.SetGCPercent(o.gogc)
+		slog.Debug("set GOGC", "gogc", o.gogc, "was", prev)
+	}
+	if o.ballast > 0 {
+		ballast = make([]byte, o.ballast)
+		slog.Debug("allocated a heap ballast", "size", formatBytes(uint64(o.ballast)))
+	}
+}
+
+// String describes the tuning, such as "GOGC 400 and a 64.0 MiB ballast".
+func (o *gcOptions) String() string {
+	var parts []string
+	switch {
+	case o.gogc < 0:
+		parts = append(parts, "GOGC off")
+	case o.gogc > 0:
+		parts = append(parts, fmt.Sprintf("GOGC %d", o.gogc))
+	}
+	if o.ballast > 0 {
+		parts = append(parts, fmt.Sprintf("a %s ballast", formatBytes(uint64(o.ballast))))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// untunedMemStats runs the calculation of o once before the collector is
+// tuned, with a cold cache of its own and nothing observing the results,
+// and reports its memory use: the baseline -gc-compare measures the tuned
+// run against.
+func untunedMemStats(ctx context.Context, o calcOptions, maxN int) (fib.MemStats, error) {
+	o.cacheFile, o.redisAddr = "", ""
+	o.metrics, o.onResult, o.onProgress, o.onConcurrency = nil, nil, nil, nil
+	runtime.GC()
+	stop := fib.MeasureMemory()
+	_, _, _, err := benchOnce(ctx, o, maxN)
+	mem := stop()
+	if err != nil {
+		return fib.MemStats{}, fmt.Errorf("untuned run for -gc-compare: %w", err)
+	}
+	return mem, nil
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/history"
+)
+
+const defaultHistoryDB = "massjunk-history.db"
+
+// historyOptions is the flag that records runs in a history database.
+type historyOptions struct {
+	historyDB string
+}
+
+func (o *historyOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.historyDB, "history-db", "", "record the run and its results in this SQLite `file`, for massjunk history")
+}
+
+// record saves r to the -history-db, if one was given. It records
+// interrupted runs too, so it doesn't use ctx.
+func (o *historyOptions) record(ctx context.Context, r *report) error {
+	if o.historyDB == "" {
+		return nil
+	}
+	store, err := history.Open(o.historyDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	id, err := store.Record(context.WithoutCancel(ctx), historyRun(r), r.results)
+	if err != nil {
+		return fmt.Errorf("recording the run in %s: %w", o.historyDB, err)
+	}
+	slog.Info("recorded the run", "history_db", o.historyDB, "id", id)
+	return nil
+}
+
+// historyRun summarizes r for the history database.
+func historyRun(r *report) history.Run {
+	m := r.metadata()
+	run := history.Run{
+		StartedAt:    m.StartedAt,
+		MaxN:         m.MaxN,
+		Workers:      m.Workers,
+		Algorithm:    m.Algorithm,
+		MachineInts:  m.MachineInts,
+		Work:         m.Work,
+		Workload:     m.Workload,
+		WorkDist:     m.WorkDist,
+		Seed:         m.Seed,
+		GoVersion:    m.GoVersion,
+		GOOS:         m.GOOS,
+		GOARCH:       m.GOARCH,
+		NumCPU:       m.NumCPU,
+		GOMAXPROCS:   m.GOMAXPROCS,
+		Elapsed:      r.elapsed,
+		Completed:    r.completed(),
+		Mean:         r.latency.Mean,
+		P50:          r.latency.P50,
+		P90:          r.latency.P90,
+		P99:          r.latency.P99,
+		Max:          r.latency.Max,
+		CacheHitRate: r.cacheStats.HitRate(),
+	}
+	run.Failed = len(r.results) - run.Completed
+	if r.err != nil {
+		run.Error = r.err.Error()
+	}
+	return run
+}
+
+func historyCmd(ctx context.Context, args []string) error {
+	const usage = "usage: massjunk history list|show|compare [flags] [ID...]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	action, args := args[0], args[1:]
+
+	var (
+		lo    logOptions
+		db    string
+		limit int
+	)
+	fs := newFlagSet("history " + action)
+	lo.register(fs)
+	fs.StringVar(&db, "history-db", defaultHistoryDB, "SQLite `file` the runs were recorded in with run -history-db")
+	if action == "list" {
+		fs.IntVar(&limit, "limit", 20, "list at most this many of the latest runs (0 means all)")
+	}
+	if err := parseFlags(fs, "history", args); err != nil {
+		return err
+	}
+	if err := lo.setupLogging(); err != nil {
+		return err
+	}
+	ids, err := parseRunIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(db); err != nil {
+		return fmt.Errorf("no history at %s: record runs with massjunk run -history-db", db)
+	}
+	store, err := history.Open(db)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	switch {
+	case action == "list" && len(ids) == 0:
+		runs, err := store.Runs(ctx, limit)
+		if err != nil {
+			return err
+		}
+		return printHistory(runs)
+	case action == "show" && len(ids) == 1:
+		return showRun(ctx, store, ids[0], lo.useColor(os.Stdout))
+	case action == "compare" && len(ids) == 2:
+		return compareRuns(ctx, store, ids[0], ids[1])
+	}
+	return errors.New(usage)
+}
+
+func parseRunIDs(args []string) ([]int64, error) {
+	ids := make([]int64, len(args))
+	for i, a := range args {
+		id, err := strconv.ParseInt(a, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("run ID %q is not a number", a)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// printHistory lists runs one per line.
+func printHistory(runs []history.Run) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "id\tstarted\tn\tworkers\talgorithm\twork\telapsed\tp50\tp99\tcache hits\terror")
+	for _, r := range runs {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\t%s\t%v\t%v\t%v\t%.1f%%\t%s\n",
+			r.ID, r.StartedAt.Format("2006-01-02 15:04:05"), r.MaxN, r.Workers, r.Algorithm, r.Work,
+			r.Elapsed.Round(time.Microsecond), r.P50, r.P99, 100*r.CacheHitRate, r.Error)
+	}
+	return tw.Flush()
+}
+
+// showRun prints everything recorded about one run, its results in color if
+// color is set.
+func showRun(ctx context.Context, store *history.Store, id int64, color bool) error {
+	run, err := store.Run(ctx, id)
+	if err != nil {
+		return err
+	}
+	results, err := store.Results(ctx, id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Go: Run %d started %s: Fibonacci up to %d on %d workers with %s, work %s (%s, %s)\n",
+		run.ID, run.StartedAt.Format("2006-01-02 15:04:05"), run.MaxN, run.Workers, run.Algorithm, run.Work, run.Workload, run.WorkDist)
+	fmt.Printf("Go: Environment: %s on %s/%s, %d CPUs, GOMAXPROCS %d\n", run.GoVersion, run.GOOS, run.GOARCH, run.NumCPU, run.GOMAXPROCS)
+	fmt.Printf("Go: Took %v; %d computed, %d failed; cache hit rate %.1f%%\n", run.Elapsed, run.Completed, run.Failed, 100*run.CacheHitRate)
+	if run.Error != "" {
+		fmt.Printf("Go: Error: %s\n", run.Error)
+	}
+	return printResultTable(os.Stdout, results, color)
+}
+
+// compareRuns prints the summaries of two runs side by side, then how the
+// durations of the n they have in common changed.
+func compareRuns(ctx context.Context, store *history.Store, idA, idB int64) error {
+	a, err := store.Run(ctx, idA)
+	if err != nil {
+		return err
+	}
+	b, err := store.Run(ctx, idB)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "\trun %d\trun %d\tchange\t\n", a.ID, b.ID)
+	setting := func(name string, x, y any) {
+		mark := ""
+		if fmt.Sprint(x) != fmt.Sprint(y) {
+			mark = "differs"
+		}
+		fmt.Fprintf(tw, "%s\t%v\t%v\t%s\t\n", name, x, y, mark)
+	}
+	setting("n", a.MaxN, b.MaxN)
+	setting("workers", a.Workers, b.Workers)
+	setting("algorithm", a.Algorithm, b.Algorithm)
+	setting("work", a.Work, b.Work)
+	setting("workload", a.Workload, b.Workload)
+	setting("GOMAXPROCS", a.GOMAXPROCS, b.GOMAXPROCS)
+	setting("Go", a.GoVersion, b.GoVersion)
+	timing := func(name string, x, y time.Duration) {
+		fmt.Fprintf(tw, "%s\t%v\t%v\t%s\t\n", name, x, y, change(float64(x), float64(y)))
+	}
+	timing("elapsed", a.Elapsed, b.Elapsed)
+	timing("mean", a.Mean, b.Mean)
+	timing("p50", a.P50, b.P50)
+	timing("p90", a.P90, b.P90)
+	timing("p99", a.P99, b.P99)
+	timing("max", a.Max, b.Max)
+	fmt.Fprintf(tw, "cache hits\t%.1f%%\t%.1f%%\t%s\t\n", 100*a.CacheHitRate, 100*b.CacheHitRate, change(a.CacheHitRate, b.CacheHitRate))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	ra, err := store.Results(ctx, idA)
+	if err != nil {
+		return err
+	}
+	rb, err := store.Results(ctx, idB)
+	if err != nil {
+		return err
+	}
+	before := map[int]time.Duration{}
+	for _, r := range ra {
+		if r.Err == nil {
+			before[r.N] = r.Duration
+		}
+	}
+	var faster, slower, common int
+	for _, r := range rb {
+		d, ok := before[r.N]
+		if !ok || r.Err != nil {
+			continue
+		}
+		common++
+		switch {
+		case r.Duration < d:
+			faster++
+		case r.Duration > d:
+			slower++
+		}
+	}
+	fmt.Printf("Go: Of the %d n computed by both runs, %d were faster and %d slower in run %d\n", common, faster, slower, b.ID)
+	return nil
+}
+
+// change describes the relative change from x to y.
+func change(x, y float64) string {
+	if x == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%+.1f%%", 100*(y-x)/x)
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// language is one of the sibling implementations at the root of the
+// repository. None of them has an entry point of its own, so each is run
+// through a small generated driver that calls its calculation function.
+type language struct {
+	name   string
+	source string
+	// tool is the executable the language needs; the language is skipped
+	// if it isn't installed.
+	tool string
+	// command returns the command that runs src, the absolute path of the
+	// source, for maxN and workers, building it in the scratch directory
+	// tmp first if it needs compiling.
+	command func(ctx context.Context, src, tmp string, maxN, workers int) (*exec.Cmd, error)
+}
+
+var languages = []language{
+	{"Python", "Junk.py", "python3", func(ctx context.Context, src, _ string, maxN, workers int) (*exec.Cmd, error) {
+		driver := fmt.Sprintf("import runpy; runpy.run_path(%q)['main_fib_concurrent'](%d, %d)", src, maxN, workers)
+		return exec.CommandContext(ctx, "python3", "-c", driver), nil
+	}},
+	{"JavaScript", "Junk.js", "node", func(ctx context.Context, src, tmp string, maxN, workers int) (*exec.Cmd, error) {
+		driver, err := appendCall(src, filepath.Join(tmp, "junk.js"), fmt.Sprintf("calculateConcurrentFibonacci(%d, %d);", maxN, workers))
+		if err != nil {
+			return nil, err
+		}
+		return exec.CommandContext(ctx, "node", driver), nil
+	}},
+	{"Java", "Junk.java", "javac", func(ctx context.Context, src, tmp string, maxN, workers int) (*exec.Cmd, error) {
+		// javac wants a public class in a file of the same name.
+		if err := copyFile(src, filepath.Join(tmp, "ConcurrentFibonacci.java")); err != nil {
+			return nil, err
+		}
+		main := fmt.Sprintf("public class Main { public static void main(String[] a) { ConcurrentFibonacci.calculateConcurrentFibonacci(%d, %d); } }\n", maxN, workers)
+		if err := os.WriteFile(filepath.Join(tmp, "Main.java"), []byte(main), 0o644); err != nil {
+			return nil, err
+		}
+		if err := build(ctx, tmp, "javac", "ConcurrentFibonacci.java", "Main.java"); err != nil {
+			return nil, err
+		}
+		return exec.CommandContext(ctx, "java", "-cp", tmp, "Main"), nil
+	}},
+	{"Kotlin", "Kotlin junk.kt", "kotlinc", func(ctx context.Context, src, tmp string, maxN, workers int) (*exec.Cmd, error) {
+		main := fmt.Sprintf("import kotlinx.coroutines.runBlocking\nfun main() = runBlocking { calculateConcurrentFibonacciKotlin(%d, %d) }\n", maxN, workers)
+		if err := os.WriteFile(filepath.Join(tmp, "Main.kt"), []byte(main), 0o644); err != nil {
+			return nil, err
+		}
+		jar := filepath.Join(tmp, "junk.jar")
+		if err := build(ctx, tmp, "kotlinc", src, "Main.kt", "-include-runtime", "-d", jar); err != nil {
+			return nil, err
+		}
+		return exec.CommandContext(ctx, "java", "-jar", jar), nil
+	}},
+	{"C++", "C++junk.cpp", "g++", func(ctx context.Context, src, tmp string, maxN, workers int) (*exec.Cmd, error) {
+		main := fmt.Sprintf("#include %q\nint main() { calculateConcurrentFibonacci_cpp(%d, %d); }\n", src, maxN, workers)
+		if err := os.WriteFile(filepath.Join(tmp, "main.cpp"), []byte(main), 0o644); err != nil {
+			return nil, err
+		}
+		bin := filepath.Join(tmp, "junk")
+		if err := build(ctx, tmp, "g++", "-std=c++17", "-O2", "-pthread", "-o", bin, "main.cpp"); err != nil {
+			return nil, err
+		}
+		return exec.CommandContext(ctx, bin), nil
+	}},
+	{"Elixir", "Elixir junk.ex", "elixir", func(ctx context.Context, src, _ string, maxN, workers int) (*exec.Cmd, error) {
+		return exec.CommandContext(ctx, "elixir", "-r", src, "-e", fmt.Sprintf("Runner.run_fib_concurrent(%d, %d)", maxN, workers)), nil
+	}},
+	// Despite its name, "Rust junk.rs" holds the Ruby version.
+	{"Ruby", "Rust junk.rs", "ruby", func(ctx context.Context, src, _ string, maxN, workers int) (*exec.Cmd, error) {
+		driver := fmt.Sprintf("load ARGV[0]; calculate_concurrent_fibonacci_ruby(%d, %d)", maxN, workers)
+		return exec.CommandContext(ctx, "ruby", "-e", driver, src), nil
+	}},
+}
+
+// totalTimeLine matches the line every implementation prints at the end,
+// such as "Python: Total time taken for Fibonacci up to 15: 0.0123 seconds".
+var totalTimeLine = regexp.MustCompile(`Total time taken for Fibonacci up to \d+: ([0-9.]+) ?seconds`)
+
+// langResult is the outcome of running one implementation.
+type langResult struct {
+	name, source string
+	// reported is the time the implementation measured itself, and wall
+	// the time its process took, both zero if it didn't finish.
+	reported, wall time.Duration
+	status         string
+}
+
+func langsCmd(ctx context.Context, args []string) error {
+	var (
+		o       calcOptions
+		maxN    int
+		dir     string
+		only    stringList
+		timeout time.Duration
+	)
+	fs := newFlagSet("langs")
+	o.register(fs)
+	fs.IntVar(&maxN, "n", 15, "have every implementation calculate Fibonacci numbers 0 through `maxN`")
+	fs.StringVar(&dir, "dir", ".", "`directory` holding the sibling implementations, the root of the repository")
+	fs.Var(&only, "languages", "comma-separated `list` of languages to run, such as python,c++ (default all)")
+	fs.DurationVar(&timeout, "lang-timeout", 2*time.Minute, "give up on an implementation that takes longer than this, build included")
+	if err := parseFlags(fs, "langs", args); err != nil {
+		return err
+	}
+	if err := o.setupLogging(); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp("", "massjunk-langs")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	goResult, err := runGoForLangs(ctx, o, maxN)
+	if err != nil {
+		return err
+	}
+	results := []langResult{goResult}
+	for _, l := range languages {
+		if len(only) > 0 && !containsFold(only, l.name) {
+			continue
+		}
+		scratch := filepath.Join(tmp, strings.ToLower(strings.ReplaceAll(l.name, "+", "p")))
+		if err := os.Mkdir(scratch, 0o755); err != nil {
+			return err
+		}
+		results = append(results, runLanguage(ctx, l, dir, scratch, maxN, o.workers, timeout))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	printLangs(results, maxN, o.workers)
+	return ctx.Err()
+}
+
+// runGoForLangs times the Go calculator on the same problem, in process.
+func runGoForLangs(ctx context.Context, o calcOptions, maxN int) (langResult, error) {
+	calc, closer, err := o.calculator()
+	if err != nil {
+		return langResult{}, err
+	}
+	defer closer.Close()
+	start := time.Now()
+	_, elapsed, err := calc.Calculate(ctx, maxN)
+	r := langResult{name: "Go", source: "fib", reported: elapsed, wall: time.Since(start), status: "ok"}
+	if err != nil {
+		r.reported, r.wall, r.status = 0, 0, err.Error()
+	}
+	return r, nil
+}
+
+// runLanguage builds and runs l, reporting why if it can't.
+func runLanguage(ctx context.Context, l language, dir, tmp string, maxN, workers int, timeout time.Duration) langResult {
+	r := langResult{name: l.name, source: l.source}
+	src, err := filepath.Abs(filepath.Join(dir, l.source))
+	if err == nil {
+		_, err = os.Stat(src)
+	}
+	if err != nil {
+		r.status = "source not found"
+		return r
+	}
+	if _, err := exec.LookPath(l.tool); err != nil {
+		r.status = l.tool + " not installed"
+		return r
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd, err := l.command(ctx, src, tmp, maxN, workers)
+	if err != nil {
+		r.status = "build failed: " + diagnostic(err.Error(), filepath.Dir(src))
+		return r
+	}
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	start := time.Now()
+	err = cmd.Run()
+	wall := time.Since(start)
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		r.status = "timed out"
+	case err != nil:
+		r.status = "failed: " + diagnostic(out.String(), filepath.Dir(src))
+	default:
+		m := totalTimeLine.FindSubmatch(out.Bytes())
+		if m == nil {
+			r.status = "no timing in output"
+			break
+		}
+		secs, _ := strconv.ParseFloat(string(m[1]), 64)
+		r.reported, r.wall, r.status = time.Duration(secs*float64(time.Second)), wall, "ok"
+	}
+	return r
+}
+
+// build runs a compiler in dir, returning its output as the error if it
+// fails.
+func build(ctx context.Context, dir, tool string, args ...string) error {
+	cmd := exec.CommandContext(ctx, tool, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) == 0 {
+			return err
+		}
+		return errors.New(string(out))
+	}
+	return nil
+}
+
+// appendCall writes a copy of src to dst with call added at the end.
+func appendCall(src, dst, call string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	return dst, os.WriteFile(dst, append(data, "\n"+call+"\n"...), 0o644)
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
+
+// diagnostic picks the line of a compiler's or interpreter's output that
+// explains a failure: the first mentioning an error, or else the first that
+// isn't blank. Paths in dir are shortened to their file names.
+func diagnostic(out, dir string) string {
+	first := ""
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(strings.ReplaceAll(line, dir+string(filepath.Separator), ""))
+		if line == "" {
+			continue
+		}
+		if strings.Contains(strings.ToLower(line), "error") {
+			return line
+		}
+		if first == "" {
+			first = line
+		}
+	}
+	return first
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// printLangs prints the comparison table, with speeds relative to Go.
+func printLangs(results []langResult, maxN, workers int) {
+	fmt.Printf("Go: Fibonacci numbers 0 through %d with %d workers in every language\n", maxN, workers)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "language\tsource\treported\twall\tvs Go\tstatus")
+	base := results[0].reported
+	for _, r := range results {
+		reported, wall, rel := "-", "-", "-"
+		if r.status == "ok" {
+			reported, wall = r.reported.Round(time.Microsecond).String(), r.wall.Round(time.Microsecond).String()
+			if base > 0 && r.reported > 0 {
+				rel = fmt.Sprintf("%.2fx", float64(r.reported)/float64(base))
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.name, r.source, reported, wall, rel, r.status)
+	}
+	tw.Flush()
+}
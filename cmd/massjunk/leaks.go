@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+)
+
+// leakOptions are the goroutine leak check flags of the run command.
+type leakOptions struct {
+	strict bool
+	stacks bool
+}
+
+func (o *leakOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.strict, "strict", false, "fail if goroutines started by the run are still alive after it")
+	fs.BoolVar(&o.stacks, "leak-stacks", false, "print the stacks of goroutines left over after the run")
+}
+
+// leakGrace is how long goroutines get to exit after the run before they
+// are counted as leaked.
+const leakGrace = time.Second
+
+// goroutineSnapshot holds the stacks of the goroutines alive at one moment,
+// keyed by their "goroutine <id>" header.
+type goroutineSnapshot map[string][]byte
+
+// snapshotGoroutines returns the stacks of all goroutines.
+func snapshotGoroutines() goroutineSnapshot {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	snap := make(goroutineSnapshot)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		// Each stack starts "goroutine <id> [<state>]:".
+		if id, _, ok := bytes.Cut(stack, []byte(" [")); ok {
+			snap[string(id)] = stack
+		}
+	}
+	return snap
+}
+
+// newSince returns the stacks of the goroutines alive now that were not in
+// baseline.
+func newSince(baseline goroutineSnapshot) [][]byte {
+	var stacks [][]byte
+	for id, stack := range snapshotGoroutines() {
+		if _, ok := baseline[id]; !ok {
+			stacks = append(stacks, stack)
+		}
+	}
+	return stacks
+}
+
+// check waits up to leakGrace for the goroutines started since baseline to
+// exit, then reports any that are left: as a warning, or, with -strict, as
+// an error.
+func (o *leakOptions) check(baseline goroutineSnapshot) error {
+	leaked := newSince(baseline)
+	for deadline := time.Now().Add(leakGrace); len(leaked) > 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		leaked = newSince(baseline)
+	}
+	if len(leaked) == 0 {
+		return nil
+	}
+	if o.stacks {
+		for _, stack := range leaked {
+			fmt.Fprintf(os.Stderr, "%s\n\n", stack)
+		}
+	}
+	if o.strict {
+		return fmt.Errorf("%d goroutines leaked by the run", len(leaked))
+	}
+	slog.Warn("goroutines leaked by the run", "count", len(leaked), "hint", "use -leak-stacks to see them")
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// logOptions are the verbosity and color flags shared by every command.
+type logOptions struct {
+	quiet, verbose, debug bool
+	noColor               bool
+}
+
+func (o *logOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.quiet, "quiet", false, "log nothing; run prints nothing at all, its exit status telling how it went (see massjunk help)")
+	fs.BoolVar(&o.verbose, "verbose", false, "also log debug messages, such as the start and end of every run")
+	fs.BoolVar(&o.debug, "debug", false, "log every computation, with source locations")
+	fs.BoolVar(&o.noColor, "no-color", false, "don't color tables, even on a terminal")
+}
+
+// setupLogging installs a logger writing to stderr at the level chosen by the
+// flags as the default slog logger. Informational messages and up are
+// logged, or nothing with -quiet.
+func (o *logOptions) setupLogging() error {
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo, ReplaceAttr: levelNames}
+	switch {
+	case o.quiet && (o.verbose || o.debug):
+		return errors.New("-quiet cannot be combined with -verbose or -debug")
+	case o.quiet:
+		slog.SetDefault(slog.New(slog.DiscardHandler))
+		return nil
+	case o.debug:
+		opts.Level = fib.LevelTrace
+		opts.AddSource = true
+	case o.verbose:
+		opts.Level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, opts)))
+	return nil
+}
+
+// levelNames prints fib.LevelTrace as TRACE rather than DEBUG-4.
+func levelNames(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 && a.Value.Any() == fib.LevelTrace {
+		a.Value = slog.StringValue("TRACE")
+	}
+	return a
+}
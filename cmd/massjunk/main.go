@@ -0,0 +1,121 @@
+// Command massjunk is the demo driver for the fib package.
+//
+// Usage:
+//
+//	massjunk <command> [flags]
+//
+// The commands are:
+//
+//	run     calculate a range of Fibonacci numbers concurrently (the default)
+//	bench   compare algorithms, caches and worker counts side by side
+//	soak    compute random n for a while, reporting sustained throughput
+//	serve   answer Fibonacci queries over HTTP
+//	cache   inspect, warm, dump or clear a persisted or served cache
+//	demo    run one of the other workloads, such as a prime sieve
+//	cluster spread a range across workers running serve -grpc-addr
+//	queue   publish tasks to, or process them from, a Redis stream
+//	history list and compare runs recorded with run -history-db
+//	langs   time the sibling implementations in other languages against Go
+//	repl    type commands such as fib 40 or set workers 8 at a prompt
+//
+// Run massjunk <command> -h for the flags of each command. Any flag can also
+// be set with an environment variable named after it, such as
+// MASSJUNK_CACHE_FILE for -cache-file, or from a YAML file given with
+// -config; flags on the command line take precedence over the environment,
+// which takes precedence over the file.
+//
+// Interrupting massjunk with Ctrl-C or SIGTERM cancels the work in progress,
+// waits for the workers to wind down and reports the results so far before
+// exiting with status 130; a second interrupt exits immediately.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// command is one massjunk subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"run", "calculate a range of Fibonacci numbers concurrently (the default)", runCmd},
+	{"bench", "compare algorithms, caches and worker counts side by side", benchCmd},
+	{"soak", "compute random n for a while, reporting sustained throughput", soakCmd},
+	{"serve", "answer Fibonacci queries over HTTP", serveCmd},
+	{"cache", "inspect, warm, dump or clear a persisted or served cache", cacheCmd},
+	{"demo", "run one of the other workloads, such as a prime sieve", demoCmd},
+	{"cluster", "spread a range across workers running serve -grpc-addr", clusterCmd},
+	{"queue", "publish tasks to, or process them from, a Redis stream", queueCmd},
+	{"history", "list and compare runs recorded with run -history-db", historyCmd},
+	{"langs", "time the sibling implementations in other languages against Go", langsCmd},
+	{"repl", "type commands such as fib 40 or set workers 8 at a prompt, keeping the cache between them", replCmd},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: massjunk <command> [flags]\n\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-7s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun massjunk <command> -h for the flags of each command.")
+	fmt.Fprintln(os.Stderr, "\n"+exitStatuses)
+}
+
+func main() {
+	args := os.Args[1:]
+	// Without a command, or with flags straight away, behave like "run" so
+	// the old single-command invocations keep working.
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Restore the default behaviour once the first signal has arrived, so
+	// that a second one kills a command that is slow to wind down.
+	context.AfterFunc(ctx, stop)
+
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		err := c.run(ctx, args)
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		if err != nil {
+			code, print := exitCode(err)
+			if print {
+				fmt.Fprintln(os.Stderr, "Go:", err)
+			}
+			if ctx.Err() != nil {
+				code = exitInterrupted
+			}
+			os.Exit(code)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "massjunk: unknown command %q\n\n", name)
+	usage()
+	os.Exit(exitUsage)
+}
+
+// newFlagSet returns a flag set for the named command that reports parse
+// errors to its caller rather than exiting.
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("massjunk "+name, flag.ContinueOnError)
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/internal/histogram"
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+// report is everything known about a finished run.
+type report struct {
+	opts       runOptions
+	started    time.Time
+	results    []fib.Result
+	elapsed    time.Duration
+	err        error
+	cacheStats fib.CacheStats
+	mem        fib.MemStats
+	latency    histogram.Summary
+	// concurrency traces an -adaptive run's tuning.
+	concurrency []fib.ConcurrencySample
+	workers     []workerLoad
+	// peakGoroutines is the most goroutines the calculator ran at once.
+	peakGoroutines int
+	// untunedMem is the memory use of -gc-compare's untuned run.
+	untunedMem *fib.MemStats
+	// pool is the state of the run's worker pool once it finished.
+	pool pool.Stats
+}
+
+// latencies summarizes the durations of the successful computations in
+// results.
+func latencies(results []fib.Result) histogram.Summary {
+	h := histogram.New()
+	for _, res := range results {
+		if res.Err == nil {
+			h.Record(res.Duration)
+		}
+	}
+	return h.Summary()
+}
+
+// workerLoad is how one worker of a run spent its time.
+type workerLoad struct {
+	Worker int           `json:"worker"`
+	Tasks  int           `json:"tasks"`
+	Busy   time.Duration `json:"busy_ns"`
+	Idle   time.Duration `json:"idle_ns"`
+}
+
+// Utilization returns the fraction of the run the worker was busy.
+func (l workerLoad) Utilization() float64 {
+	if l.Busy+l.Idle == 0 {
+		return 0
+	}
+	return float64(l.Busy) / float64(l.Busy+l.Idle)
+}
+
+// workerLoads breaks results down by the worker that computed them, for
+// workers 1 through workers and any others found in results. A worker is
+// busy for the duration of its computations and idle for the rest of the
+// elapsed time, so an unbalanced run shows as some workers idling while
+// others work.
+func workerLoads(results []fib.Result, workers int, elapsed time.Duration) []workerLoad {
+	for _, r := range results {
+		workers = max(workers, r.Worker)
+	}
+	loads := make([]workerLoad, workers)
+	for i := range loads {
+		loads[i].Worker = i + 1
+	}
+	for _, r := range results {
+		if r.Worker < 1 {
+			continue // not computed on the pool
+		}
+		l := &loads[r.Worker-1]
+		l.Tasks++
+		l.Busy += r.Duration
+	}
+	for i := range loads {
+		loads[i].Idle = max(elapsed-loads[i].Busy, 0)
+	}
+	return loads
+}
+
+// completed counts the results that were computed successfully.
+func (r *report) completed() int {
+	n := 0
+	for _, res := range r.results {
+		if res.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// output renders a run in one of the -output formats.
+type output interface {
+	// start is called just before the calculation begins.
+	start(o runOptions)
+	// finish is called once it has ended, successfully or not.
+	finish(r *report) error
+}
+
+const outputFormats = "summary, table or json"
+
+// newOutput returns the output for format writing to w, in color if color
+// is set.
+func newOutput(format string, w io.Writer, color bool) (output, error) {
+	switch format {
+	case "summary":
+		return summaryOutput{w}, nil
+	case "table":
+		return tableOutput{summaryOutput{w}, color}, nil
+	case "json":
+		return jsonOutput{w}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (want %s)", format, outputFormats)
+}
+
+// summaryOutput prints the classic Go junk demo lines.
+type summaryOutput struct {
+	w io.Writer
+}
+
+func (s summaryOutput) start(o runOptions) {
+	fmt.Fprintln(s.w, "\n--- Go Example ---")
+	fmt.Fprintf(s.w, "Go: Calculating Fibonacci numbers up to %d concurrently using %d workers...\n", o.maxN, o.workers)
+	if o.cpuOptions.set() {
+		fmt.Fprintf(s.w, "Go: Using GOMAXPROCS %d of %d CPUs\n", runtime.GOMAXPROCS(0), runtime.NumCPU())
+	}
+}
+
+func (s summaryOutput) finish(r *report) error {
+	interrupted := errors.Is(r.err, fib.ErrCancelled)
+	if r.err == nil {
+		fmt.Fprintf(s.w, "Go: Total time taken for Fibonacci up to %d: %v\n", r.opts.maxN, r.elapsed)
+	}
+	if interrupted {
+		fmt.Fprintf(s.w, "Go: Interrupted after %v with %d of %d results computed\n", r.elapsed, r.completed(), len(r.results))
+	}
+	if errors.Is(r.err, fib.ErrMemoryBudget) {
+		fmt.Fprintf(s.w, "Go: Stopped after %v by -max-heap with %d of %d results computed\n", r.elapsed, r.completed(), len(r.results))
+	}
+	if r.latency.Count > 0 {
+		l := r.latency
+		fmt.Fprintf(s.w, "Go: Latency: p50 %v, p90 %v, p99 %v, max %v\n", l.P50, l.P90, l.P99, l.Max)
+	}
+	if len(r.concurrency) > 0 {
+		fmt.Fprintf(s.w, "Go: Concurrency: %s\n", concurrencyTrace(r.concurrency))
+	}
+	if r.opts.cacheStats || interrupted {
+		fmt.Fprintf(s.w, "Go: Cache: %v\n", r.cacheStats)
+	}
+	if r.opts.memStats {
+		fmt.Fprintf(s.w, "Go: Memory: %v\n", r.mem)
+	}
+	if r.opts.memStats || r.opts.maxGoroutines > 0 {
+		fmt.Fprintf(s.w, "Go: Goroutines: peak %d\n", r.peakGoroutines)
+	}
+	if r.opts.scheduler == fib.WorkStealing.Name() {
+		fmt.Fprintf(s.w, "Go: Scheduler: work stealing, with %d of %d tasks stolen\n", r.pool.Steals, r.pool.Started)
+	}
+	if r.opts.gcOptions.set() {
+		fmt.Fprintf(s.w, "Go: GC: %d cycles pausing %v with %v", r.mem.GCCycles, r.mem.GCPause, &r.opts.gcOptions)
+		if u := r.untunedMem; u != nil {
+			fmt.Fprintf(s.w, ", against %d cycles pausing %v untuned", u.GCCycles, u.GCPause)
+		}
+		fmt.Fprintln(s.w)
+	}
+	if r.err == nil {
+		fmt.Fprintln(s.w, "Go calculation complete.")
+	}
+	return nil
+}
+
+// concurrencyTrace describes how an adaptive run's concurrency changed, as
+// the successive worker counts and the time each change was made.
+func concurrencyTrace(samples []fib.ConcurrencySample) string {
+	var b strings.Builder
+	fmt.Fprint(&b, samples[0].Workers)
+	for _, s := range samples {
+		if s.Next != s.Workers {
+			fmt.Fprintf(&b, " → %d (%v)", s.Next, s.Elapsed.Round(time.Millisecond))
+		}
+	}
+	return b.String()
+}
+
+// tableOutput adds a per-n table to the summary.
+type tableOutput struct {
+	summaryOutput
+	color bool
+}
+
+func (t tableOutput) finish(r *report) error {
+	if err := printResultTable(t.w, r.results, t.color); err != nil {
+		return err
+	}
+	fmt.Fprintln(t.w)
+	if err := printWorkerTable(t.w, r.workers); err != nil {
+		return err
+	}
+	return t.summaryOutput.finish(r)
+}
+
+// printResultTable writes one line per result. In color, the lines of
+// failed results are red, those slower than the 90th percentile yellow and
+// cache hits green, with a key to the colors below the table.
+func printResultTable(w io.Writer, results []fib.Result, color bool) error {
+	// The escape sequences would throw off the tabwriter's widths, so the
+	// lines are colored once they are aligned.
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "n\tvalue\tduration\tcached\tworker\t")
+	for _, res := range results {
+		value := res.Value.String()
+		if res.Err != nil {
+			value = "error: " + res.Err.Error()
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%v\t%t\t%d\t\n", res.N, value, res.Duration, res.Cached, res.Worker)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if !color {
+		_, err := table.WriteTo(w)
+		return err
+	}
+	slow := latencies(results).P90
+	lines := strings.SplitAfter(table.String(), "\n")
+	bw := bufio.NewWriter(w)
+	bw.WriteString(lines[0]) // the header
+	for i, res := range results {
+		line := lines[i+1]
+		if c := resultColor(res, slow); c != "" {
+			line = c + strings.TrimSuffix(line, "\n") + ansiReset + "\n"
+		}
+		bw.WriteString(line)
+	}
+	fmt.Fprintf(bw, "%sKey:%s %scached%s, %sslower than p90 (%v)%s, %sfailed%s\n",
+		ansiDim, ansiReset, ansiGreen, ansiReset, ansiYellow, slow, ansiReset, ansiRed, ansiReset)
+	return bw.Flush()
+}
+
+// printWorkerTable writes one line per worker.
+func printWorkerTable(w io.Writer, loads []workerLoad) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "worker\ttasks\tbusy\tidle\tutilization\t")
+	for _, l := range loads {
+		fmt.Fprintf(tw, "%d\t%d\t%v\t%v\t%.1f%%\t\n", l.Worker, l.Tasks, l.Busy.Round(time.Microsecond), l.Idle.Round(time.Microsecond), 100*l.Utilization())
+	}
+	return tw.Flush()
+}
+
+// jsonOutput writes the whole run as a single JSON document for jq and
+// dashboards.
+type jsonOutput struct {
+	w io.Writer
+}
+
+// runMetadata describes the environment and settings of a run.
+type runMetadata struct {
+	StartedAt   time.Time `json:"started_at"`
+	MaxN        int       `json:"max_n"`
+	Workers     int       `json:"workers"`
+	Work        string    `json:"work"`
+	Workload    string    `json:"workload"`
+	WorkDist    string    `json:"work_dist"`
+	Seed        uint64    `json:"seed,omitempty"`
+	Algorithm   string    `json:"algorithm"`
+	MachineInts bool      `json:"machine_ints"`
+	Scheduler   string    `json:"scheduler"`
+	GoVersion   string    `json:"go_version"`
+	GOOS        string    `json:"goos"`
+	GOARCH      string    `json:"goarch"`
+	NumCPU      int       `json:"num_cpu"`
+	GOMAXPROCS  int       `json:"gomaxprocs"`
+}
+
+func (r *report) metadata() runMetadata {
+	return runMetadata{
+		StartedAt:   r.started,
+		MaxN:        r.opts.maxN,
+		Workers:     r.opts.workers,
+		Work:        r.opts.work.String(),
+		Workload:    r.opts.workload,
+		WorkDist:    r.opts.workDist,
+		Seed:        r.opts.seed,
+		Algorithm:   r.opts.algorithm,
+		MachineInts: r.opts.machineInts,
+		Scheduler:   r.opts.scheduler,
+		GoVersion:   runtime.Version(),
+		GOOS:        runtime.GOOS,
+		GOARCH:      runtime.GOARCH,
+		NumCPU:      runtime.NumCPU(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+	}
+}
+
+func (jsonOutput) start(runOptions) {}
+
+func (j jsonOutput) finish(r *report) error {
+	doc := struct {
+		Meta      runMetadata       `json:"meta"`
+		ElapsedNS int64             `json:"elapsed_ns"`
+		Error     string            `json:"error,omitempty"`
+		Results   []fib.Result      `json:"results"`
+		Latency   histogram.Summary `json:"latency"`
+		Cache     fib.CacheStats    `json:"cache"`
+		Memory    fib.MemStats      `json:"memory"`
+		Workers   []workerLoad      `json:"workers"`
+
+		PeakGoroutines int           `json:"peak_goroutines"`
+		Steals         *uint64       `json:"steals,omitempty"`
+		GCTuning       string        `json:"gc_tuning,omitempty"`
+		UntunedMemory  *fib.MemStats `json:"untuned_memory,omitempty"`
+
+		Concurrency []fib.ConcurrencySample `json:"concurrency,omitempty"`
+	}{
+		Meta:      r.metadata(),
+		ElapsedNS: r.elapsed.Nanoseconds(),
+		Results:   r.results,
+		Latency:   r.latency,
+		Cache:     r.cacheStats,
+		Memory:    r.mem,
+		Workers:   r.workers,
+
+		PeakGoroutines: r.peakGoroutines,
+		UntunedMemory:  r.untunedMem,
+
+		Concurrency: r.concurrency,
+	}
+	if r.opts.scheduler == fib.WorkStealing.Name() {
+		doc.Steals = &r.pool.Steals
+	}
+	if r.opts.gcOptions.set() {
+		doc.GCTuning = r.opts.gcOptions.String()
+	}
+	if r.err != nil {
+		doc.Error = r.err.Error()
+	}
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
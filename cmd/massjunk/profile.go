@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	rpprof "runtime/pprof"
+	"runtime/trace"
+
+	"github.com/ZapGaming/Mass-Junk-Code/server"
+)
+
+// profileOptions are the profiling flags of the run and bench commands.
+type profileOptions struct {
+	cpuProfile string
+	memProfile string
+	trace      string
+}
+
+func (o *profileOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.cpuProfile, "cpuprofile", "", "write a CPU profile to `file`")
+	fs.StringVar(&o.memProfile, "memprofile", "", "write a heap profile to `file` when the command finishes")
+	fs.StringVar(&o.trace, "trace", "", "write an execution trace to `file`, for go tool trace, with a task for the run and for each computation")
+}
+
+// start begins the requested CPU profile and execution trace. The returned
+// function stops them and writes the heap profile; it must be called once the
+// work being profiled is done.
+func (o *profileOptions) start() (stop func() error, err error) {
+	var stops []func() error
+	stopAll := func() error {
+		var errs []error
+		for i := len(stops) - 1; i >= 0; i-- {
+			errs = append(errs, stops[i]())
+		}
+		return errors.Join(errs...)
+	}
+
+	if o.cpuProfile != "" {
+		f, err := os.Create(o.cpuProfile)
+		if err != nil {
+			return nil, err
+		}
+		if err := rpprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		stops = append(stops, func() error {
+			rpprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+	if o.trace != "" {
+		f, err := os.Create(o.trace)
+		if err != nil {
+			stopAll()
+			return nil, err
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			stopAll()
+			return nil, err
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+	if o.memProfile != "" {
+		stops = append(stops, func() error {
+			f, err := os.Create(o.memProfile)
+			if err != nil {
+				return err
+			}
+			runtime.GC() // get up-to-date statistics
+			if err := rpprof.WriteHeapProfile(f); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		})
+	}
+	return stopAll, nil
+}
+
+// registerPprof mounts the net/http/pprof handlers under /debug/pprof/.
+func registerPprof(srv *server.Server) {
+	srv.Handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
+	srv.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	srv.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	srv.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	srv.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// progressBar draws a one-line progress bar, redrawn in place, for use as a
+// fib.Config.OnProgress hook.
+type progressBar struct {
+	w       io.Writer
+	width   int
+	started time.Time
+	drawn   time.Time
+}
+
+// progressInterval limits how often the bar is redrawn.
+const progressInterval = 100 * time.Millisecond
+
+func newProgressBar(w io.Writer) *progressBar {
+	return &progressBar{w: w, width: 40, started: time.Now()}
+}
+
+// update redraws the bar for done of total results, at most every
+// progressInterval except for the final update, which also ends the line.
+func (p *progressBar) update(done, total int) {
+	now := time.Now()
+	if done < total && now.Sub(p.drawn) < progressInterval {
+		return
+	}
+	p.drawn = now
+	filled := p.width * done / max(total, 1)
+	fmt.Fprintf(p.w, "\r[%s%s] %d/%d (%d%%) %v", strings.Repeat("=", filled), strings.Repeat(" ", p.width-filled),
+		done, total, 100*done/max(total, 1), now.Sub(p.started).Round(time.Millisecond))
+	if done == total {
+		fmt.Fprintln(p.w)
+	}
+}
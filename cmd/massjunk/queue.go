@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/fib/redisqueue"
+)
+
+func queueCmd(ctx context.Context, args []string) error {
+	const usage = "usage: massjunk queue publish|work -redis-addr host:port [flags]"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch action, args := args[0], args[1:]; action {
+	case "publish":
+		return queuePublish(ctx, args)
+	case "work":
+		return queueWork(ctx, args)
+	default:
+		return fmt.Errorf("unknown queue action %q; %s", action, usage)
+	}
+}
+
+// queueFlags registers the flags that pick the streams and cap the results,
+// shared by both actions.
+func queueFlags(fs *flag.FlagSet, opts *redisqueue.Options) {
+	fs.StringVar(&opts.Stream, "stream", redisqueue.DefaultStream, "Redis stream holding the tasks")
+	fs.StringVar(&opts.Results, "results", redisqueue.DefaultResults, "Redis stream holding the results")
+	fs.StringVar(&opts.Group, "group", redisqueue.DefaultGroup, "consumer group the workers belong to")
+	fs.Int64Var(&opts.MaxResults, "max-results", redisqueue.DefaultMaxResults, "trim the result stream to about this many entries")
+}
+
+func queuePublish(ctx context.Context, args []string) error {
+	var (
+		lo       logOptions
+		opts     redisqueue.Options
+		from, to int
+		noWait   bool
+	)
+	fs := newFlagSet("queue publish")
+	lo.register(fs)
+	queueFlags(fs, &opts)
+	fs.StringVar(&opts.Addr, "redis-addr", "", "address of the Redis server the workers read from")
+	fs.IntVar(&from, "from", 0, "publish tasks for n from `from`")
+	fs.IntVar(&to, "n", 1000, "publish tasks for n up to `maxN`")
+	fs.BoolVar(&noWait, "no-wait", false, "exit once the tasks are published instead of collecting the results")
+	if err := parseFlags(fs, "queue", args); err != nil {
+		return err
+	}
+	if err := lo.setupLogging(); err != nil {
+		return err
+	}
+	if opts.Addr == "" {
+		return errors.New("-redis-addr is required")
+	}
+	q, err := redisqueue.New(opts)
+	if err != nil {
+		return fmt.Errorf("connecting to Redis: %w", err)
+	}
+	defer q.Close()
+
+	start := time.Now()
+	run, err := q.Publish(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	slog.Info("published tasks", "run", run.ID, "tasks", run.Total(), "stream", opts.Stream)
+	if noWait {
+		return nil
+	}
+	var results []fib.Result
+	perConsumer := map[string]int{}
+	err = q.Collect(ctx, run, func(r redisqueue.Result) {
+		results = append(results, r.Result)
+		perConsumer[r.Consumer]++
+	})
+	printQueueRun(run, results, perConsumer, time.Since(start))
+	return err
+}
+
+// printQueueRun summarizes the results collected for run.
+func printQueueRun(run redisqueue.Run, results []fib.Result, perConsumer map[string]int, elapsed time.Duration) {
+	fmt.Printf("Go: Collected %d of %d results for run %s from %d workers in %v\n",
+		len(results), run.Total(), run.ID, len(perConsumer), elapsed)
+	consumers := make([]string, 0, len(perConsumer))
+	for c := range perConsumer {
+		consumers = append(consumers, c)
+	}
+	sort.Strings(consumers)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "worker\tresults")
+	for _, c := range consumers {
+		fmt.Fprintf(tw, "%s\t%d\n", c, perConsumer[c])
+	}
+	tw.Flush()
+	cached, failed := 0, 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed++
+		case r.Cached:
+			cached++
+		}
+	}
+	fmt.Printf("Go: %d answered from the shared cache, %d failed\n", cached, failed)
+	if latency := latencies(results); latency.Count > 0 {
+		fmt.Printf("Go: Latency: p50 %v, p90 %v, p99 %v, max %v\n", latency.P50, latency.P90, latency.P99, latency.Max)
+	}
+}
+
+func queueWork(ctx context.Context, args []string) error {
+	var (
+		o    calcOptions
+		opts redisqueue.Options
+	)
+	fs := newFlagSet("queue work")
+	o.register(fs)
+	queueFlags(fs, &opts)
+	fs.StringVar(&opts.Consumer, "consumer", "", "name of this worker in the group (default host name and process ID)")
+	fs.DurationVar(&opts.ClaimIdle, "claim-idle", redisqueue.DefaultClaimIdle, "take over tasks another worker has left unacknowledged for this long")
+	if err := parseFlags(fs, "queue", args); err != nil {
+		return err
+	}
+	if err := o.setupLogging(); err != nil {
+		return err
+	}
+	if o.redisAddr == "" {
+		return errors.New("-redis-addr is required")
+	}
+	// The same server holds the queue and the cache the workers share, which
+	// makes a redelivered task cheap to answer again.
+	opts.Addr = o.redisAddr
+	calc, closer, err := o.calculator()
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	q, err := redisqueue.New(opts)
+	if err != nil {
+		return fmt.Errorf("connecting to Redis: %w", err)
+	}
+	defer q.Close()
+
+	slog.Info("waiting for tasks", "consumer", q.Consumer(), "stream", opts.Stream, "workers", calc.Workers())
+	var done atomic.Int64
+	err = q.Work(ctx, calc, func(r redisqueue.Result) {
+		done.Add(1)
+		slog.Debug("task done", "run", r.Run, "n", r.N, "cached", r.Cached, "duration", r.Duration, "err", r.Err)
+	})
+	fmt.Printf("Go: Worker %s processed %d tasks\n", q.Consumer(), done.Load())
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
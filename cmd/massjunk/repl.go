@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+const replHelp = `Commands:
+  fib N                 compute F(N), from the cache if it is there
+  run N                 compute F(0) through F(N) on the workers
+  set workers N         change how many workers runs use
+  set algorithm NAME    switch to naive, memoized, iterative or doubling
+  set work DURATION     change the simulated work per step, such as 1ms or 0
+  stats                 show the settings, the cache and the work of the runs so far
+  clear cache           empty the cache
+  help                  show this list
+  quit                  leave, saving the -cache-file if one was given`
+
+// repl is an interactive session. Its calculator is rebuilt when a setting
+// it can't change in place is set, but the cache is carried over, so values
+// computed earlier in the session keep being served from it.
+type repl struct {
+	o     calcOptions
+	calc  *fib.Calculator
+	cache fib.Cache
+	// closers release the connections of every calculator built so far;
+	// a rebuilt one may still be using them through the shared cache.
+	closers []io.Closer
+	out     io.Writer
+}
+
+func replCmd(ctx context.Context, args []string) error {
+	var o calcOptions
+	fs := newFlagSet("repl")
+	o.register(fs)
+	if err := parseFlags(fs, "repl", args); err != nil {
+		return err
+	}
+	if err := o.setupLogging(); err != nil {
+		return err
+	}
+	r := &repl{o: o, out: os.Stdout}
+	if err := r.build(); err != nil {
+		return err
+	}
+	defer func() {
+		for _, c := range r.closers {
+			c.Close()
+		}
+	}()
+
+	prompt := ""
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		prompt = "massjunk> "
+		fmt.Fprintln(r.out, `Go: Type "help" for a list of commands.`)
+	}
+	lines := readLines(os.Stdin)
+	for quit := false; !quit; {
+		fmt.Fprint(r.out, prompt)
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(r.out)
+			quit = true
+		case line, ok := <-lines:
+			if !ok {
+				if prompt != "" {
+					fmt.Fprintln(r.out)
+				}
+				quit = true
+				break
+			}
+			var err error
+			quit, err = r.exec(ctx, line)
+			if err != nil {
+				fmt.Fprintf(r.out, "Go: Error: %v\n", err)
+			}
+		}
+	}
+	return r.o.saveCache(r.calc)
+}
+
+// readLines returns the lines read from rd, closing the channel at the
+// end of the input. A read can't be interrupted, so the goroutine reading
+// them outlives the session if it ends first.
+func readLines(rd io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		sc := bufio.NewScanner(rd)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+	return lines
+}
+
+// build makes the session's calculator from r.o, keeping the cache of the
+// previous one if there was one.
+func (r *repl) build() error {
+	o := r.o
+	if r.cache != nil {
+		// The -cache-file was loaded into the cache already; loading it
+		// again would bring back entries cleared since.
+		o.cacheFile = ""
+		o.newCache = func() fib.Cache { return r.cache }
+	}
+	calc, closer, err := o.calculator()
+	if err != nil {
+		return err
+	}
+	r.calc, r.cache = calc, calc.Cache()
+	r.closers = append(r.closers, closer)
+	return nil
+}
+
+// exec carries out one command line, reporting whether it asks to quit.
+func (r *repl) exec(ctx context.Context, line string) (quit bool, err error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	cmd, args := strings.ToLower(fields[0]), fields[1:]
+	switch {
+	case cmd == "quit" || cmd == "exit":
+		return true, nil
+	case cmd == "help" || cmd == "?":
+		fmt.Fprintln(r.out, replHelp)
+	case cmd == "fib" && len(args) == 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return false, fmt.Errorf("fib: %q is not a number", args[0])
+		}
+		return false, r.fib(ctx, n)
+	case cmd == "run" && len(args) == 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return false, fmt.Errorf("run: %q is not a number", args[0])
+		}
+		return false, r.run(ctx, n)
+	case cmd == "set" && len(args) == 2:
+		return false, r.set(strings.ToLower(args[0]), args[1])
+	case cmd == "stats" && len(args) == 0:
+		r.stats()
+	case cmd == "clear" && len(args) == 1 && strings.ToLower(args[0]) == "cache":
+		n := r.cache.Len()
+		r.cache.Reset()
+		fmt.Fprintf(r.out, "Go: Cleared %d entries\n", n)
+	default:
+		return false, fmt.Errorf("unknown command %q; type \"help\" for a list", strings.TrimSpace(line))
+	}
+	return false, nil
+}
+
+func (r *repl) fib(ctx context.Context, n int) error {
+	res := r.calc.Compute(ctx, n)
+	if res.Err != nil {
+		return res.Err
+	}
+	source := "computed"
+	if res.Cached {
+		source = "cached"
+	}
+	fmt.Fprintf(r.out, "Go: F(%d) = %s (%v, %s)\n", n, abbreviate(res.Value.String()), res.Duration.Round(time.Microsecond), source)
+	return nil
+}
+
+func (r *repl) run(ctx context.Context, maxN int) error {
+	results, elapsed, err := r.calc.Calculate(ctx, maxN)
+	failed, cached := 0, 0
+	for _, res := range results {
+		switch {
+		case res.Err != nil:
+			failed++
+		case res.Cached:
+			cached++
+		}
+	}
+	fmt.Fprintf(r.out, "Go: Computed %d of %d in %v with %d workers: %d from the cache, %d failed\n",
+		len(results), maxN+1, elapsed.Round(time.Microsecond), r.calc.Workers(), cached, failed)
+	if err == nil && len(results) > 0 {
+		last := results[len(results)-1]
+		if last.Err == nil {
+			fmt.Fprintf(r.out, "Go: F(%d) = %s\n", last.N, abbreviate(last.Value.String()))
+		}
+	}
+	return err
+}
+
+// set changes a setting. The worker count changes in place; the others
+// need a new calculator, which starts its cache statistics afresh.
+func (r *repl) set(name, value string) error {
+	switch name {
+	case "workers":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("set workers: %q is not a number", value)
+		}
+		if err := r.calc.SetWorkers(n); err != nil {
+			return err
+		}
+		r.o.workers = n
+		fmt.Fprintf(r.out, "Go: Workers: %d\n", n)
+		return nil
+	case "algorithm":
+		alg, err := fib.ParseAlgorithm(value)
+		if err != nil {
+			return err
+		}
+		prev := r.o.algorithm
+		r.o.algorithm = alg.Name()
+		if err := r.build(); err != nil {
+			r.o.algorithm = prev
+			return err
+		}
+		fmt.Fprintf(r.out, "Go: Algorithm: %s\n", alg.Name())
+		return nil
+	case "work":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("set work: %q is not a duration such as 1ms or 0", value)
+		}
+		prev := r.o.work
+		r.o.work = d
+		if err := r.build(); err != nil {
+			r.o.work = prev
+			return err
+		}
+		fmt.Fprintf(r.out, "Go: Work: %v per step\n", d)
+		return nil
+	}
+	return fmt.Errorf("set: unknown setting %q; want workers, algorithm or work", name)
+}
+
+func (r *repl) stats() {
+	fmt.Fprintf(r.out, "Go: Algorithm: %s, %d workers, %v of work per step\n", r.calc.Algorithm().Name(), r.calc.Workers(), r.o.work)
+	fmt.Fprintf(r.out, "Go: Cache: %v\n", r.calc.CacheStats())
+	if p := r.calc.PoolStats(); p.Started > 0 {
+		fmt.Fprintf(r.out, "Go: Pool: %d tasks completed of %d started, %d stolen\n", p.Completed, p.Started, p.Steals)
+	}
+}
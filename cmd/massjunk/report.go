@@ -0,0 +1,355 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	"os"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/internal/histogram"
+)
+
+const reportFormats = "md or html"
+
+// reportOptions are the flags that write a full run report to a file, for
+// pasting into an issue or opening in a browser.
+type reportOptions struct {
+	report     string
+	reportFile string
+}
+
+func (o *reportOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.report, "report", "", "also write a full report of the run in this format: "+reportFormats)
+	fs.StringVar(&o.reportFile, "report-file", "", "write the -report to this `file` (default massjunk-report.md or .html)")
+}
+
+// check rejects an unknown -report format before any work is done.
+func (o *reportOptions) check() error {
+	switch o.report {
+	case "", "md", "html":
+		return nil
+	}
+	return fmt.Errorf("unknown report format %q (want %s)", o.report, reportFormats)
+}
+
+// write renders r to the -report file, if one was asked for.
+func (o *reportOptions) write(r *report) error {
+	if o.report == "" {
+		return nil
+	}
+	path := o.reportFile
+	if path == "" {
+		path = "massjunk-report." + o.report
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	v := newReportView(r)
+	if o.report == "html" {
+		err = htmlReport.Execute(f, v)
+	} else {
+		err = markdownReport.Execute(f, v)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// reportView is what the report templates render.
+type reportView struct {
+	Meta        runMetadata
+	Elapsed     time.Duration
+	Error       string
+	Completed   int
+	Rows        []reportRow
+	Latency     histogram.Summary
+	Cache       fib.CacheStats
+	Memory      fib.MemStats
+	Concurrency string
+	Workers     []workerLoad
+	Goroutines  int
+
+	// DurationChart plots each n's computation time, and WorkerChart the
+	// number of computations each worker ran.
+	DurationChart, WorkerChart chart
+}
+
+// reportRow is one line of the per-n table.
+type reportRow struct {
+	N        int
+	Value    string
+	Duration time.Duration
+	Cached   bool
+	Worker   int
+	Error    string
+}
+
+// maxReportDigits is the longest value shown in full in a report; longer
+// ones are abbreviated to their first and last digits.
+const maxReportDigits = 40
+
+func newReportView(r *report) reportView {
+	v := reportView{
+		Meta:      r.metadata(),
+		Elapsed:   r.elapsed,
+		Completed: r.completed(),
+		Latency:   r.latency,
+		Cache:     r.cacheStats,
+		Memory:    r.mem,
+		Workers:   r.workers,
+
+		Goroutines: r.peakGoroutines,
+	}
+	if r.err != nil {
+		v.Error = r.err.Error()
+	}
+	if len(r.concurrency) > 0 {
+		v.Concurrency = concurrencyTrace(r.concurrency)
+	}
+	perWorker := map[int]float64{}
+	var durations []bar
+	for _, res := range r.results {
+		row := reportRow{N: res.N, Duration: res.Duration, Cached: res.Cached, Worker: res.Worker}
+		if res.Err != nil {
+			row.Error = res.Err.Error()
+		} else if res.Value != nil {
+			row.Value = abbreviate(res.Value.String())
+			perWorker[res.Worker]++
+		}
+		v.Rows = append(v.Rows, row)
+		durations = append(durations, bar{
+			Value: float64(res.Duration),
+			Title: fmt.Sprintf("n=%d: %v", res.N, res.Duration),
+		})
+	}
+	v.DurationChart = newChart(durations)
+	var workers []bar
+	for w := 1; w <= max(r.opts.workers, len(perWorker)); w++ {
+		workers = append(workers, bar{
+			Value: perWorker[w],
+			Title: fmt.Sprintf("worker %d: %.0f computations", w, perWorker[w]),
+		})
+	}
+	v.WorkerChart = newChart(workers)
+	return v
+}
+
+// abbreviate shortens a long decimal value to its first and last digits.
+func abbreviate(s string) string {
+	if len(s) <= maxReportDigits {
+		return s
+	}
+	half := maxReportDigits / 2
+	return fmt.Sprintf("%s…%s (%d digits)", s[:half], s[len(s)-half:], len(s))
+}
+
+// chart is a bar chart laid out for drawing as SVG.
+type chart struct {
+	Width, Height float64
+	Bars          []bar
+}
+
+// bar is one bar of a chart; X, Y, W and H are filled in by newChart.
+type bar struct {
+	Value      float64
+	Title      string
+	X, Y, W, H float64
+}
+
+const (
+	chartWidth  = 720
+	chartHeight = 200
+)
+
+// newChart scales bars to fill a chart side by side.
+func newChart(bars []bar) chart {
+	c := chart{Width: chartWidth, Height: chartHeight, Bars: bars}
+	if len(bars) == 0 {
+		return c
+	}
+	top := 0.0
+	for _, b := range bars {
+		top = max(top, b.Value)
+	}
+	w := chartWidth / float64(len(bars))
+	for i := range bars {
+		b := &bars[i]
+		b.X, b.W = float64(i)*w, max(w-1, 1)
+		if top > 0 {
+			b.H = b.Value / top * chartHeight
+		}
+		b.Y = chartHeight - b.H
+		// A tenth of a pixel is plenty and keeps the SVG readable.
+		for _, f := range []*float64{&b.X, &b.Y, &b.W, &b.H} {
+			*f = math.Round(*f*10) / 10
+		}
+	}
+	return c
+}
+
+var reportFuncs = map[string]any{
+	"pct": func(f float64) string { return fmt.Sprintf("%.1f%%", 100*f) },
+}
+
+var markdownReport = texttemplate.Must(texttemplate.New("md").Funcs(reportFuncs).Parse(`# massjunk run report
+
+Started {{.Meta.StartedAt.Format "2006-01-02 15:04:05 MST"}}, took {{.Elapsed}}.
+{{- if .Error}}
+
+**Error:** {{.Error}} ({{.Completed}} of {{len .Rows}} results computed)
+{{- end}}
+
+## Configuration
+
+| setting | value |
+| ------- | ----- |
+| n | 0 to {{.Meta.MaxN}} |
+| workers | {{.Meta.Workers}} |
+| algorithm | {{.Meta.Algorithm}} |
+| machine ints | {{.Meta.MachineInts}} |
+| work | {{.Meta.Work}} ({{.Meta.Workload}}, {{.Meta.WorkDist}}) |
+{{- if .Meta.Seed}}
+| seed | {{.Meta.Seed}} |
+{{- end}}
+
+## Environment
+
+| | |
+| --- | --- |
+| Go | {{.Meta.GoVersion}} |
+| OS/arch | {{.Meta.GOOS}}/{{.Meta.GOARCH}} |
+| CPUs | {{.Meta.NumCPU}} |
+| GOMAXPROCS | {{.Meta.GOMAXPROCS}} |
+
+## Summary
+
+| metric | value |
+| ------ | ----- |
+| computations | {{.Latency.Count}} |
+| mean | {{.Latency.Mean}} |
+| p50 | {{.Latency.P50}} |
+| p90 | {{.Latency.P90}} |
+| p99 | {{.Latency.P99}} |
+| max | {{.Latency.Max}} |
+| cache hit rate | {{pct .Cache.HitRate}} |
+| cache | {{.Cache}} |
+| memory | {{.Memory}} |
+| peak goroutines | {{.Goroutines}} |
+{{- if .Concurrency}}
+| concurrency | {{.Concurrency}} |
+{{- end}}
+
+## Workers
+
+| worker | tasks | busy | idle | utilization |
+| -: | -: | -: | -: | -: |
+{{- range .Workers}}
+| {{.Worker}} | {{.Tasks}} | {{.Busy}} | {{.Idle}} | {{pct .Utilization}} |
+{{- end}}
+
+## Results
+
+| n | value | duration | cached | worker |
+| -: | -: | -: | :-: | -: |
+{{- range .Rows}}
+| {{.N}} | {{if .Error}}error: {{.Error}}{{else}}{{.Value}}{{end}} | {{.Duration}} | {{.Cached}} | {{.Worker}} |
+{{- end}}
+`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("html").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>massjunk run report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em auto; max-width: 60em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.6em; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.error { color: #b00; }
+svg rect { fill: #4a7fb5; }
+svg rect:hover { fill: #e08a1e; }
+</style>
+</head>
+<body>
+<h1>massjunk run report</h1>
+<p>Started {{.Meta.StartedAt.Format "2006-01-02 15:04:05 MST"}}, took {{.Elapsed}}.</p>
+{{- if .Error}}
+<p class="error"><strong>Error:</strong> {{.Error}} ({{.Completed}} of {{len .Rows}} results computed)</p>
+{{- end}}
+
+<h2>Configuration</h2>
+<table>
+<tr><th>n</th><td>0 to {{.Meta.MaxN}}</td></tr>
+<tr><th>workers</th><td>{{.Meta.Workers}}</td></tr>
+<tr><th>algorithm</th><td>{{.Meta.Algorithm}}</td></tr>
+<tr><th>machine ints</th><td>{{.Meta.MachineInts}}</td></tr>
+<tr><th>work</th><td>{{.Meta.Work}} ({{.Meta.Workload}}, {{.Meta.WorkDist}})</td></tr>
+{{- if .Meta.Seed}}
+<tr><th>seed</th><td>{{.Meta.Seed}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Environment</h2>
+<table>
+<tr><th>Go</th><td>{{.Meta.GoVersion}}</td></tr>
+<tr><th>OS/arch</th><td>{{.Meta.GOOS}}/{{.Meta.GOARCH}}</td></tr>
+<tr><th>CPUs</th><td>{{.Meta.NumCPU}}</td></tr>
+<tr><th>GOMAXPROCS</th><td>{{.Meta.GOMAXPROCS}}</td></tr>
+</table>
+
+<h2>Summary</h2>
+<table>
+<tr><th>computations</th><td class="num">{{.Latency.Count}}</td></tr>
+<tr><th>mean</th><td class="num">{{.Latency.Mean}}</td></tr>
+<tr><th>p50</th><td class="num">{{.Latency.P50}}</td></tr>
+<tr><th>p90</th><td class="num">{{.Latency.P90}}</td></tr>
+<tr><th>p99</th><td class="num">{{.Latency.P99}}</td></tr>
+<tr><th>max</th><td class="num">{{.Latency.Max}}</td></tr>
+<tr><th>cache hit rate</th><td class="num">{{pct .Cache.HitRate}}</td></tr>
+<tr><th>cache</th><td>{{.Cache}}</td></tr>
+<tr><th>memory</th><td>{{.Memory}}</td></tr>
+<tr><th>peak goroutines</th><td class="num">{{.Goroutines}}</td></tr>
+{{- if .Concurrency}}
+<tr><th>concurrency</th><td>{{.Concurrency}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Duration by n</h2>
+{{template "chart" .DurationChart}}
+
+<h2>Computations by worker</h2>
+{{template "chart" .WorkerChart}}
+
+<table>
+<tr><th>worker</th><th>tasks</th><th>busy</th><th>idle</th><th>utilization</th></tr>
+{{- range .Workers}}
+<tr><td class="num">{{.Worker}}</td><td class="num">{{.Tasks}}</td><td class="num">{{.Busy}}</td><td class="num">{{.Idle}}</td><td class="num">{{pct .Utilization}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Results</h2>
+<table>
+<tr><th>n</th><th>value</th><th>duration</th><th>cached</th><th>worker</th></tr>
+{{- range .Rows}}
+<tr><td class="num">{{.N}}</td>
+{{- if .Error}}<td class="error">error: {{.Error}}</td>{{else}}<td class="num">{{.Value}}</td>{{end -}}
+<td class="num">{{.Duration}}</td><td>{{.Cached}}</td><td class="num">{{.Worker}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+{{define "chart" -}}
+<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}">
+{{- range .Bars}}
+<rect x="{{.X}}" y="{{.Y}}" width="{{.W}}" height="{{.H}}"><title>{{.Title}}</title></rect>
+{{- end}}
+</svg>
+{{- end}}`))
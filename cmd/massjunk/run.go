@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// runOptions holds the flags of the run command.
+type runOptions struct {
+	calcOptions
+	profileOptions
+	traceOptions
+	leakOptions
+	reportOptions
+	historyOptions
+	checkpointOptions
+	gcOptions
+	maxN       int
+	output     string
+	sinks      string
+	csvPath    string
+	cacheStats bool
+	prewarm    bool
+	progress   bool
+	tui        bool
+	memStats   bool
+	verify     bool
+	selfCheck  bool
+}
+
+func runCmd(ctx context.Context, args []string) error {
+	var o runOptions
+	fs := newFlagSet("run")
+	o.calcOptions.register(fs)
+	o.profileOptions.register(fs)
+	o.traceOptions.register(fs)
+	o.traceOptions.registerDOT(fs)
+	o.leakOptions.register(fs)
+	o.reportOptions.register(fs)
+	o.historyOptions.register(fs)
+	o.checkpointOptions.register(fs)
+	o.gcOptions.register(fs)
+	fs.IntVar(&o.maxN, "n", 15, "calculate Fibonacci numbers 0 through `maxN`")
+	fs.StringVar(&o.output, "output", "summary", "output format: "+outputFormats)
+	fs.StringVar(&o.sinks, "sink", "", "also send the results, as they are computed, to these comma-separated `sinks`: stdout, json:PATH, csv:PATH, an http(s) URL to POST to, or discard")
+	fs.StringVar(&o.csvPath, "csv", "", "also write per-n timings to this CSV `file` (short for -sink csv:file)")
+	fs.BoolVar(&o.cacheStats, "cache-stats", false, "print a cache-efficiency summary at the end of the run")
+	fs.BoolVar(&o.prewarm, "prewarm", false, "fill the cache before the run so only scheduling overhead is measured")
+	fs.BoolVar(&o.memStats, "mem-stats", false, "print allocation and garbage collection statistics at the end of the run")
+	fs.BoolVar(&o.verify, "verify", false, "check every result against an independently computed reference")
+	fs.BoolVar(&o.selfCheck, "self-check", false, "check that the results obey F(n) = F(n-1) + F(n-2) and agree with the cache and with an uncached recomputation")
+	fs.BoolVar(&o.progress, "progress", false, "show a progress bar on stderr while calculating")
+	fs.BoolVar(&o.tui, "tui", false, "show a live dashboard of workers, queue, cache and throughput on stderr while calculating; on a terminal, enter p to pause or resume the run")
+	if err := parseFlags(fs, "run", args); err != nil {
+		return err
+	}
+	if err := o.setupLogging(); err != nil {
+		return err
+	}
+	stopTracing, err := o.traceOptions.start(ctx)
+	if err != nil {
+		return err
+	}
+	stopProfiling, err := o.profileOptions.start()
+	if err != nil {
+		return errors.Join(err, stopTracing())
+	}
+	if o.tui {
+		terminalLines() // reads for the whole process, so start it first
+	}
+	baseline := snapshotGoroutines()
+	err = run(ctx, o)
+	if err == nil {
+		err = o.leakOptions.check(baseline)
+	}
+	err = errors.Join(err, stopProfiling(), stopTracing())
+	if o.quiet {
+		err = silence(err)
+	}
+	return err
+}
+
+// run performs one calculation as described by o and reports it on stdout,
+// or nowhere with -quiet. A run that completes with failures or finds wrong
+// results ends massjunk with the matching exit status.
+func run(ctx context.Context, o runOptions) error {
+	stdout, stderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	if o.quiet {
+		stdout, stderr = io.Discard, io.Discard
+	}
+	out, err := newOutput(o.output, stdout, o.useColor(os.Stdout))
+	if err != nil {
+		return err
+	}
+	if err := o.reportOptions.check(); err != nil {
+		return err
+	}
+	if o.progress && o.tui {
+		return errors.New("-progress and -tui cannot be used together")
+	}
+	if o.quiet && (o.progress || o.tui) {
+		return errors.New("-quiet cannot be combined with -progress or -tui")
+	}
+	if err := o.gcOptions.check(); err != nil {
+		return err
+	}
+	if o.progress {
+		o.onProgress = newProgressBar(os.Stderr).update
+	}
+	var dash *dashboard
+	if o.tui {
+		dash = newDashboard(os.Stderr, o.maxN)
+		o.onProgress = dash.update
+	}
+	resumed, err := o.checkpointOptions.load()
+	if err != nil {
+		return err
+	}
+	if resumed != nil {
+		if resumed.MaxN != o.maxN {
+			slog.Info("resuming the checkpoint's run", "n", resumed.MaxN)
+		}
+		o.maxN = resumed.MaxN
+	}
+	var ckpt *checkpointer
+	if o.checkpoint != "" {
+		ckpt = newCheckpointer(o.checkpoint, o.every, o.maxN, resumed)
+	}
+	sinks, err := newRunSink(o.sinks, o.csvPath)
+	if err != nil {
+		return err
+	}
+	switch {
+	case ckpt != nil && sinks != nil:
+		o.onResult = func(r fib.Result) { ckpt.observe(r); sinks.observe(r) }
+	case ckpt != nil:
+		o.onResult = ckpt.observe
+	case sinks != nil:
+		o.onResult = sinks.observe
+	}
+	if sinks != nil && resumed != nil {
+		// The resumed results won't be computed again, but belong to the run.
+		for _, r := range resumed.Results {
+			sinks.observe(r)
+		}
+	}
+	rep := &report{opts: o}
+	if o.gcOptions.compare {
+		mem, err := untunedMemStats(ctx, o.calcOptions, o.maxN)
+		if err != nil {
+			return err
+		}
+		rep.untunedMem = &mem
+	}
+	o.gcOptions.apply()
+	o.onConcurrency = func(s fib.ConcurrencySample) {
+		rep.concurrency = append(rep.concurrency, s)
+	}
+	calc, closer, err := o.calculator()
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	if o.verify && calc.Sequence() != fib.FibonacciSequence {
+		return fmt.Errorf("-verify only knows the %s sequence", fib.FibonacciSequence.Name())
+	}
+	if o.prewarm {
+		if err := calc.WarmCache(ctx, o.maxN); err != nil {
+			return err
+		}
+	}
+
+	out.start(o)
+	rep.opts, rep.started = o, time.Now()
+	if o.gcOptions.compare {
+		runtime.GC() // start level with the untuned run
+	}
+	stopMem := fib.MeasureMemory()
+	stopDashboard := func() {}
+	if dash != nil {
+		if lines := terminalLines(); lines != nil {
+			dash.controls(lines, calc)
+		}
+		stopDashboard = dash.start(calc)
+	}
+	stopCheckpoints := func() error { return nil }
+	if ckpt != nil {
+		stopCheckpoints = ckpt.start(calc)
+	}
+	if resumed != nil {
+		rep.results, rep.elapsed, rep.err = calc.Resume(ctx, *resumed)
+	} else {
+		rep.results, rep.elapsed, rep.err = calc.Calculate(ctx, o.maxN)
+	}
+	stopDashboard()
+	var stall *fib.StallError
+	if errors.As(rep.err, &stall) {
+		fmt.Fprintf(stderr, "Go: The run stalled; the goroutines at the time were:\n\n%s\n", stall.Stacks)
+	}
+	if err := stopCheckpoints(); err != nil {
+		return err
+	}
+	rep.mem = stopMem()
+	rep.latency = latencies(rep.results)
+	rep.workers = workerLoads(rep.results, o.workers, rep.elapsed)
+	rep.cacheStats = calc.CacheStats()
+	rep.peakGoroutines = calc.PeakGoroutines()
+	rep.pool = calc.PoolStats()
+	if err := out.finish(rep); err != nil {
+		return err
+	}
+	if err := o.reportOptions.write(rep); err != nil {
+		return err
+	}
+	if err := o.historyOptions.record(ctx, rep); err != nil {
+		return err
+	}
+	if sinks != nil {
+		if err := sinks.finish(rep); err != nil {
+			return err
+		}
+	}
+
+	if err := o.saveCache(calc); err != nil {
+		return err
+	}
+	if o.verify {
+		if err := verify(stderr, rep.results); err != nil {
+			return withExitCode(exitVerify, err)
+		}
+	}
+	if o.selfCheck {
+		if err := selfCheck(ctx, stderr, calc, rep.results); err != nil {
+			return withExitCode(exitVerify, err)
+		}
+	}
+	if runErr := rep.err; runErr != nil {
+		code := exitPartial
+		if errors.Is(runErr, fib.ErrTimeout) || errors.Is(runErr, fib.ErrStalled) {
+			code = exitTimeout
+		}
+		return withExitCode(code, fmt.Errorf("calculation completed with errors: %w", runErr))
+	}
+	return nil
+}
+
+// verify reports results that disagree with fib.Verify's reference on w,
+// returning an error if there are any.
+func verify(w io.Writer, results []fib.Result) error {
+	mismatches := fib.Verify(results)
+	for _, m := range mismatches {
+		fmt.Fprintln(w, "Go: verify:", m)
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("verification failed: %d of %d results are wrong", len(mismatches), len(results))
+	}
+	slog.Info("verified results against the reference", "count", len(results))
+	return nil
+}
+
+// selfCheck reports the violations fib.Calculator.SelfCheck finds in
+// results on w, returning an error if there are any.
+func selfCheck(ctx context.Context, w io.Writer, calc *fib.Calculator, results []fib.Result) error {
+	violations, err := calc.SelfCheck(ctx, results)
+	for _, v := range violations {
+		fmt.Fprintln(w, "Go: self-check:", v)
+	}
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("self-check failed: %d violations in %d results", len(violations), len(results))
+	}
+	slog.Info("results passed the self-check", "count", len(results))
+	return nil
+}
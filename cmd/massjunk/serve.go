@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/ZapGaming/Mass-Junk-Code/metrics"
+	"github.com/ZapGaming/Mass-Junk-Code/server"
+)
+
+func serveCmd(ctx context.Context, args []string) error {
+	var (
+		o        calcOptions
+		to       traceOptions
+		addr     string
+		grpcAddr string
+		maxN     int
+	)
+	fs := newFlagSet("serve")
+	o.register(fs)
+	to.register(fs)
+	fs.StringVar(&addr, "addr", "localhost:8080", "listen on this `address`")
+	fs.StringVar(&grpcAddr, "grpc-addr", "", "also serve the gRPC API on this `address`")
+	fs.IntVar(&maxN, "max-n", server.DefaultMaxN, "reject requests for n above this")
+	if err := parseFlags(fs, "serve", args); err != nil {
+		return err
+	}
+	if err := o.setupLogging(); err != nil {
+		return err
+	}
+
+	stopTracing, err := to.start(ctx)
+	if err != nil {
+		return err
+	}
+	defer stopTracing()
+
+	o.metrics = metrics.New()
+	calc, closer, err := o.calculator()
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	srv := server.New(calc)
+	srv.MaxN = maxN
+	srv.Handle("GET /metrics", o.metrics.Handler())
+	expvar.Publish("massjunk", o.metrics.Expvar())
+	srv.Handle("GET /debug/vars", expvar.Handler())
+	registerPprof(srv)
+	slog.Info("serving Fibonacci numbers", "url", "http://"+addr, "endpoints", "GET /fib/{n}, GET /fib/{n}/mod/{m}, POST /fib/range, GET /fib/range/stream, GET /ws, GET /cache/stats, DELETE /cache, GET/PUT /admin/workers, GET/PUT /admin/paused, GET /metrics, GET /debug/vars, /debug/pprof/")
+
+	waitGRPC := func() error { return nil }
+	if grpcAddr != "" {
+		g := server.NewGRPC(calc)
+		g.MaxN = maxN
+		gs := grpc.NewServer()
+		g.Register(gs)
+		if waitGRPC, err = serveGRPC(ctx, grpcAddr, gs); err != nil {
+			return err
+		}
+		slog.Info("serving gRPC", "addr", grpcAddr, "service", "massjunk.fib.v1.Fibonacci")
+	}
+	err = listenAndServe(ctx, &http.Server{Addr: addr, Handler: srv})
+	return errors.Join(err, waitGRPC())
+}
+
+// serveGRPC starts gs listening on addr until ctx is cancelled, then stops
+// it gracefully, or abruptly if streams are still open after
+// shutdownTimeout. The returned function waits for it to stop.
+func serveGRPC(ctx context.Context, addr string, gs *grpc.Server) (wait func() error, err error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- gs.Serve(lis) }()
+	stop := context.AfterFunc(ctx, func() {
+		t := time.AfterFunc(shutdownTimeout, gs.Stop)
+		defer t.Stop()
+		gs.GracefulStop()
+	})
+	return func() error {
+		err := <-done
+		stop()
+		return err
+	}, nil
+}
+
+// shutdownTimeout bounds how long a server waits for requests in flight once
+// it has been asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+// listenAndServe runs hs until ctx is cancelled, then shuts it down
+// gracefully, letting requests in flight finish.
+func listenAndServe(ctx context.Context, hs *http.Server) error {
+	done := make(chan error, 1)
+	stop := context.AfterFunc(ctx, func() {
+		slog.Info("shutting down")
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		done <- hs.Shutdown(sctx)
+	})
+	defer stop()
+
+	if err := hs.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
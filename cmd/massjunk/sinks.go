@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/sink"
+)
+
+// runSink feeds a run's results to the sinks chosen with -sink and -csv as
+// they are computed.
+type runSink struct {
+	s sink.Sink
+
+	mu  sync.Mutex
+	err error // the first failure, after which results are dropped
+}
+
+// newRunSink opens the sinks described by specs, a comma-separated list of
+// sink specs, and a CSV sink for csvPath; either may be empty. It returns
+// nil if there are none.
+func newRunSink(specs, csvPath string) (*runSink, error) {
+	var all []string
+	if specs != "" {
+		all = append(all, specs)
+	}
+	if csvPath != "" {
+		all = append(all, "csv:"+csvPath)
+	}
+	if len(all) == 0 {
+		return nil, nil
+	}
+	s, err := sink.Parse(strings.Join(all, ","))
+	if err != nil {
+		return nil, err
+	}
+	return &runSink{s: s}, nil
+}
+
+// observe passes r on; it is safe to call from the calculator's workers.
+func (rs *runSink) observe(r fib.Result) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.err == nil {
+		rs.err = rs.s.Result(r)
+	}
+}
+
+// finish sends the summary of rep and closes the sinks.
+func (rs *runSink) finish(rep *report) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	sum := sink.Summary{
+		MaxN:      rep.opts.maxN,
+		Elapsed:   rep.elapsed,
+		Completed: rep.completed(),
+		Errors:    len(rep.results) - rep.completed(),
+		Err:       rep.err,
+		Cache:     rep.cacheStats,
+	}
+	if rs.err == nil {
+		rs.err = rs.s.Summary(sum)
+	}
+	return errors.Join(rs.err, rs.s.Close())
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/internal/histogram"
+)
+
+// soakOptions holds the flags of the soak command.
+type soakOptions struct {
+	calcOptions
+	duration time.Duration
+	maxN     int
+	every    time.Duration
+}
+
+func soakCmd(ctx context.Context, args []string) error {
+	var o soakOptions
+	fs := newFlagSet("soak")
+	o.calcOptions.register(fs)
+	fs.DurationVar(&o.duration, "duration", time.Minute, "how long to keep computing")
+	fs.IntVar(&o.maxN, "n", 90, "compute random n between 0 and `maxN`")
+	fs.DurationVar(&o.every, "report-every", 5*time.Second, "print a line of statistics this often")
+	if err := parseFlags(fs, "soak", args); err != nil {
+		return err
+	}
+	if err := o.setupLogging(); err != nil {
+		return err
+	}
+	if o.maxN < 0 || o.every <= 0 || o.workers < 1 {
+		return errors.New("-n must be non-negative, and -report-every and -workers positive")
+	}
+	return soak(ctx, o)
+}
+
+// soakInterval accumulates the statistics of one reporting interval.
+type soakInterval struct {
+	latency *histogram.Histogram
+	errors  atomic.Uint64
+}
+
+func newSoakInterval() *soakInterval {
+	return &soakInterval{latency: histogram.New()}
+}
+
+// soak computes random n on every worker until the -duration is up or ctx
+// is cancelled, printing throughput, latency percentiles and the goroutine
+// count and heap size at every interval, and a summary at the end.
+func soak(ctx context.Context, o soakOptions) error {
+	calc, closer, err := o.calculator()
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, o.duration)
+	defer cancel()
+
+	total := newSoakInterval()
+	var current atomic.Pointer[soakInterval]
+	current.Store(newSoakInterval())
+	startGoroutines, startHeap := runtime.NumGoroutine(), heapBytes()
+
+	seed := o.seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < o.workers; w++ {
+		wg.Go(func() {
+			rng := rand.New(rand.NewPCG(seed, uint64(w)))
+			for ctx.Err() == nil {
+				r := calc.Compute(ctx, rng.IntN(o.maxN+1))
+				if ctx.Err() != nil {
+					return // a computation cut short by the end of the soak
+				}
+				iv := current.Load()
+				iv.latency.Record(r.Duration)
+				total.latency.Record(r.Duration)
+				if r.Err != nil {
+					iv.errors.Add(1)
+					total.errors.Add(1)
+				}
+			}
+		})
+	}
+
+	// The lines come out one at a time, so use fixed widths rather than a
+	// tabwriter.
+	const lineFormat = "%8v %12v %7v %10v %10v %10v %12v %10v %10v\n"
+	fmt.Printf(lineFormat, "elapsed", "ops/s", "errors", "p50", "p90", "p99", "max", "goroutines", "heap")
+	start := time.Now()
+	last := start
+	tick := time.NewTicker(o.every)
+	defer tick.Stop()
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-tick.C:
+		}
+		now := time.Now()
+		iv := current.Swap(newSoakInterval())
+		s := iv.latency.Summary()
+		if s.Count == 0 && done {
+			break
+		}
+		fmt.Printf(lineFormat, now.Sub(start).Round(time.Second),
+			fmt.Sprintf("%.1f", float64(s.Count)/now.Sub(last).Seconds()), iv.errors.Load(), s.P50, s.P90, s.P99, s.Max,
+			runtime.NumGoroutine(), formatBytes(heapBytes()))
+		last = now
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	s := total.latency.Summary()
+	fmt.Printf("Go: Soaked for %v: %d computations (%.1f/s sustained), %d errors\n",
+		elapsed.Round(time.Millisecond), s.Count, float64(s.Count)/elapsed.Seconds(), total.errors.Load())
+	fmt.Printf("Go: Latency: mean %v, p50 %v, p90 %v, p99 %v, max %v\n", s.Mean, s.P50, s.P90, s.P99, s.Max)
+	fmt.Printf("Go: Goroutines %d -> %d, heap %s -> %s\n", startGoroutines, runtime.NumGoroutine(),
+		formatBytes(startHeap), formatBytes(heapBytes()))
+	fmt.Printf("Go: Cache: %v\n", calc.CacheStats())
+	return o.saveCache(calc)
+}
+
+// heapBytes returns the current size of the heap objects. It reads
+// runtime/metrics, which unlike runtime.ReadMemStats doesn't stop the world.
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// traceOptions are the OpenTelemetry flags of the run, bench and serve
+// commands.
+type traceOptions struct {
+	otlpEndpoint string
+	// dotFile is only registered by run; see registerDOT.
+	dotFile string
+}
+
+func (o *traceOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.otlpEndpoint, "otlp-endpoint", "", "export trace spans over OTLP/HTTP to this `URL`, such as http://localhost:4318/v1/traces for a local Jaeger")
+}
+
+// registerDOT adds the -dot flag, for commands whose spans make a call
+// graph of a sensible size.
+func (o *traceOptions) registerDOT(fs *flag.FlagSet) {
+	fs.StringVar(&o.dotFile, "dot", "", "write the call graph of the run, with cache hits and durations, to this Graphviz `file`")
+}
+
+// start installs a global tracer provider exporting to the OTLP endpoint
+// and recording the -dot call graph, if either was asked for. The returned
+// function flushes any spans still buffered, shuts the provider down and
+// writes the call graph.
+func (o *traceOptions) start(ctx context.Context) (stop func() error, err error) {
+	if o.otlpEndpoint == "" && o.dotFile == "" {
+		return func() error { return nil }, nil
+	}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("massjunk"))),
+	}
+	if o.otlpEndpoint != "" {
+		exp, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(o.otlpEndpoint))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+	var dot *dotRecorder
+	if o.dotFile != "" {
+		dot = new(dotRecorder)
+		opts = append(opts, sdktrace.WithSpanProcessor(dot))
+	}
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := tp.Shutdown(ctx)
+		if dot != nil {
+			err = errors.Join(err, dot.save(o.dotFile))
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,217 @@
+// Package dag runs a graph of interdependent tasks on a worker pool. Each task
+// starts as soon as every task it depends on has finished, and is handed
+// their results.
+package dag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+var (
+	// ErrDuplicate is returned by Add for an ID that is already in the graph.
+	ErrDuplicate = errors.New("dag: duplicate task")
+
+	// ErrMissing is returned by Run when a task depends on an ID that was
+	// never added.
+	ErrMissing = errors.New("dag: missing dependency")
+
+	// ErrCycle is returned by Run when the dependencies form a cycle, so
+	// that some tasks could never start.
+	ErrCycle = errors.New("dag: dependency cycle")
+
+	// ErrPanic matches, with errors.Is, the *PanicError of a task that
+	// panicked.
+	ErrPanic = errors.New("dag: task panicked")
+)
+
+// PanicError reports a panic recovered from a task's Func.
+type PanicError struct {
+	Value any    // the value passed to panic
+	Stack []byte // the stack of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrPanic, e.Value)
+}
+
+func (e *PanicError) Is(target error) bool { return target == ErrPanic }
+
+// Func computes the value of one task from the values of its dependencies,
+// keyed by their IDs.
+type Func[K comparable, V any] func(ctx context.Context, deps map[K]V) (V, error)
+
+// Graph is a set of tasks identified by keys of type K and producing values
+// of type V. It is not safe for concurrent use, but a finished graph can be
+// run any number of times.
+type Graph[K comparable, V any] struct {
+	tasks map[K]*task[K, V]
+	order []K // IDs in the order they were added
+}
+
+type task[K comparable, V any] struct {
+	fn   Func[K, V]
+	deps []K
+}
+
+// New returns an empty graph.
+func New[K comparable, V any]() *Graph[K, V] {
+	return &Graph[K, V]{tasks: make(map[K]*task[K, V])}
+}
+
+// Add adds the task id, computed by fn once every task in deps has finished.
+// Dependencies may be added after the tasks that need them.
+func (g *Graph[K, V]) Add(id K, fn Func[K, V], deps ...K) error {
+	if _, ok := g.tasks[id]; ok {
+		return fmt.Errorf("%w %v", ErrDuplicate, id)
+	}
+	g.tasks[id] = &task[K, V]{fn: fn, deps: deps}
+	g.order = append(g.order, id)
+	return nil
+}
+
+// Len reports the number of tasks in the graph.
+func (g *Graph[K, V]) Len() int { return len(g.tasks) }
+
+// Run executes every task in the graph on a pool of the given number of
+// workers and returns their values by ID. Tasks that are ready at the same
+// time start in the order they were added.
+//
+// The first task to fail cancels the context passed to the others and stops
+// any more from starting; Run then waits for those still running and returns
+// the values computed so far along with that error, which names the task. A
+// task that panics fails with a *PanicError instead of crashing the program.
+func (g *Graph[K, V]) Run(ctx context.Context, workers int) (map[K]V, error) {
+	dependents, err := g.check()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	p := pool.New(workers)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		values  = make(map[K]V, len(g.tasks))
+		pending = make(map[K]int, len(g.tasks))
+		runErr  error
+	)
+	for id, t := range g.tasks {
+		pending[id] = len(t.deps)
+	}
+
+	var submit func(id K)
+	submit = func(id K) {
+		t := g.tasks[id]
+		wg.Add(1)
+		p.Submit(func(int) {
+			defer wg.Done()
+
+			mu.Lock()
+			deps := make(map[K]V, len(t.deps))
+			for _, d := range t.deps {
+				deps[d] = values[d]
+			}
+			mu.Unlock()
+
+			v, err := g.call(ctx, id, t, deps)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if runErr == nil {
+					runErr = err
+					cancel()
+				}
+				return
+			}
+			values[id] = v
+			if runErr != nil {
+				return
+			}
+			for _, d := range dependents[id] {
+				if pending[d]--; pending[d] == 0 {
+					submit(d)
+				}
+			}
+		})
+	}
+
+	mu.Lock()
+	for _, id := range g.order {
+		if pending[id] == 0 {
+			submit(id)
+		}
+	}
+	mu.Unlock()
+
+	wg.Wait()
+	p.Close()
+	p.Wait()
+	return values, runErr
+}
+
+// call runs one task, unless the run has already been cancelled.
+func (g *Graph[K, V]) call(ctx context.Context, id K, t *task[K, V], deps map[K]V) (v V, err error) {
+	if err := ctx.Err(); err != nil {
+		return v, fmt.Errorf("dag: task %v: %w", id, err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("dag: task %v: %w", id, &PanicError{Value: p, Stack: debug.Stack()})
+		}
+	}()
+	v, err = t.fn(ctx, deps)
+	if err != nil {
+		return v, fmt.Errorf("dag: task %v: %w", id, err)
+	}
+	return v, nil
+}
+
+// check verifies that every dependency exists and that there are no cycles,
+// returning for each task the tasks that depend on it.
+func (g *Graph[K, V]) check() (map[K][]K, error) {
+	dependents := make(map[K][]K, len(g.tasks))
+	pending := make(map[K]int, len(g.tasks))
+	for _, id := range g.order {
+		t := g.tasks[id]
+		for _, d := range t.deps {
+			if _, ok := g.tasks[d]; !ok {
+				return nil, fmt.Errorf("%w: %v needs %v", ErrMissing, id, d)
+			}
+			dependents[d] = append(dependents[d], id)
+		}
+		pending[id] = len(t.deps)
+	}
+
+	// Peel off tasks whose dependencies are all satisfied; whatever is left
+	// over waits on itself.
+	var ready []K
+	for _, id := range g.order {
+		if pending[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	for len(ready) > 0 {
+		id := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		for _, d := range dependents[id] {
+			if pending[d]--; pending[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	for _, id := range g.order {
+		if pending[id] > 0 {
+			return nil, fmt.Errorf("%w through %v", ErrCycle, id)
+		}
+	}
+	return dependents, nil
+}
@@ -0,0 +1,125 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestRunOrder builds a diamond, a -> b, c -> d, and checks that every task
+// runs after its dependencies and sees their values.
+func TestRunOrder(t *testing.T) {
+	g := New[string, int]()
+	var (
+		mu   sync.Mutex
+		done []string
+	)
+	add := func(id string, v int, deps ...string) {
+		t.Helper()
+		err := g.Add(id, func(_ context.Context, got map[string]int) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, d := range deps {
+				if !slices.Contains(done, d) {
+					t.Errorf("%s started before its dependency %s finished", id, d)
+				}
+			}
+			done = append(done, id)
+			sum := v
+			for _, dv := range got {
+				sum += dv
+			}
+			return sum, nil
+		}, deps...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Added out of order: dependencies may come after their dependents.
+	add("d", 1000, "b", "c")
+	add("b", 10, "a")
+	add("c", 100, "a")
+	add("a", 1)
+	if err := g.Add("a", nil); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("adding a twice: got error %v, want ErrDuplicate", err)
+	}
+
+	for _, workers := range []int{1, 4} {
+		done = nil
+		values, err := g.Run(t.Context(), workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]int{"a": 1, "b": 11, "c": 101, "d": 1112}
+		for id, v := range want {
+			if values[id] != v {
+				t.Errorf("with %d workers, values[%s] = %d, want %d", workers, id, values[id], v)
+			}
+		}
+	}
+}
+
+func TestRunCheck(t *testing.T) {
+	ok := func(context.Context, map[int]int) (int, error) { return 0, nil }
+
+	g := New[int, int]()
+	g.Add(1, ok)
+	g.Add(2, ok, 1, 4)
+	g.Add(3, ok, 2)
+	g.Add(4, ok, 3)
+	if _, err := g.Run(t.Context(), 2); !errors.Is(err, ErrCycle) {
+		t.Errorf("2 -> 3 -> 4 -> 2: got error %v, want ErrCycle", err)
+	}
+
+	g = New[int, int]()
+	g.Add(1, ok, 1)
+	if _, err := g.Run(t.Context(), 2); !errors.Is(err, ErrCycle) {
+		t.Errorf("1 -> 1: got error %v, want ErrCycle", err)
+	}
+
+	g = New[int, int]()
+	g.Add(1, ok, 2)
+	if _, err := g.Run(t.Context(), 2); !errors.Is(err, ErrMissing) {
+		t.Errorf("1 needing 2, never added: got error %v, want ErrMissing", err)
+	}
+}
+
+// TestRunFailure checks that a failing task stops its dependents from
+// running, and that Run reports its error.
+func TestRunFailure(t *testing.T) {
+	errBroken := errors.New("broken")
+	var ran atomic.Int32
+	g := New[string, int]()
+	g.Add("ok", func(context.Context, map[string]int) (int, error) { return 1, nil })
+	g.Add("bad", func(context.Context, map[string]int) (int, error) { return 0, errBroken }, "ok")
+	g.Add("after", func(context.Context, map[string]int) (int, error) {
+		ran.Add(1)
+		return 2, nil
+	}, "bad")
+
+	values, err := g.Run(t.Context(), 2)
+	if !errors.Is(err, errBroken) {
+		t.Fatalf("got error %v, want %v", err, errBroken)
+	}
+	if ran.Load() != 0 {
+		t.Error("a task ran although its dependency failed")
+	}
+	if values["ok"] != 1 {
+		t.Errorf("values = %v, want the value of the task that succeeded", values)
+	}
+}
+
+func TestRunPanic(t *testing.T) {
+	g := New[string, int]()
+	g.Add("boom", func(context.Context, map[string]int) (int, error) { panic("boom") })
+	g.Add("after", func(context.Context, map[string]int) (int, error) { return 1, nil }, "boom")
+
+	_, err := g.Run(t.Context(), 2)
+	var pe *PanicError
+	if !errors.Is(err, ErrPanic) || !errors.As(err, &pe) || pe.Value != "boom" || len(pe.Stack) == 0 {
+		t.Fatalf("got error %v, want a *PanicError with the value boom and a stack", err)
+	}
+}
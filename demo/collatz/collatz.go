@@ -0,0 +1,130 @@
+// Package collatz computes Collatz stopping times over a range of starting
+// values, memoizing chain lengths in a fib.Cache shared by all workers.
+// Unlike Fibonacci, whose lookups walk densely down from n, Collatz chains
+// leap around and revisit values far from where they started, so the cache
+// sees scattered keys and much of it is never read again.
+package collatz
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// DefaultChunk is how many starting values each task covers unless told
+// otherwise.
+const DefaultChunk = 10_000
+
+// Result is the outcome of a range of stopping times.
+type Result struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Longest is the starting value with the longest chain in the range,
+	// the smallest of them if several tie, and Steps its stopping time.
+	Longest int `json:"longest"`
+	Steps   int `json:"steps"`
+	// Cache counts the lookups made in the cache.
+	Cache fib.CacheStats `json:"cache"`
+}
+
+// StoppingTime returns the number of steps n takes to reach 1, halving it
+// when even and replacing it with 3n+1 when odd.
+func StoppingTime(n int) int {
+	steps := 0
+	for ; n != 1; steps++ {
+		n = next(n)
+	}
+	return steps
+}
+
+func next(n int) int {
+	if n%2 == 0 {
+		return n / 2
+	}
+	return 3*n + 1
+}
+
+// Range computes the stopping times of from through to, in chunks of the
+// given size, or DefaultChunk if it is not positive, each a task on a pool
+// of workers. Chain lengths are memoized in cache, for starting values up
+// to to; values beyond it are walked but not stored, which keeps an
+// unbounded cache from growing with the chains' peaks.
+func Range(ctx context.Context, from, to int, cache fib.Cache, chunk, workers int) (Result, demo.Stats, error) {
+	if from < 1 || to < from {
+		return Result{}, demo.Stats{}, fmt.Errorf("collatz: need 1 <= from <= to, got from=%d to=%d", from, to)
+	}
+	if chunk <= 0 {
+		chunk = DefaultChunk
+	}
+	var hits, misses, stores atomic.Uint64
+	m := memo{cache: cache, limit: to, hits: &hits, misses: &misses, stores: &stores}
+
+	tasks := (to-from)/chunk + 1
+	best := make([]Result, tasks)
+	stats, err := demo.Run(ctx, workers, tasks, func(ctx context.Context, i, _ int) error {
+		lo := from + i*chunk
+		hi := min(lo+chunk-1, to)
+		b := &best[i]
+		for n := lo; n <= hi; n++ {
+			if steps := m.stoppingTime(n); steps > b.Steps || b.Longest == 0 {
+				b.Longest, b.Steps = n, steps
+			}
+		}
+		return ctx.Err()
+	})
+
+	r := Result{From: from, To: to}
+	for _, b := range best {
+		if b.Longest != 0 && (b.Steps > r.Steps || r.Longest == 0) {
+			r.Longest, r.Steps = b.Longest, b.Steps
+		}
+	}
+	// Caches that count their evictions, like fib.LRUCache, say so.
+	if st, ok := cache.(interface{ Stats() fib.CacheStats }); ok {
+		r.Cache = st.Stats()
+	}
+	r.Cache.Hits, r.Cache.Misses, r.Cache.Stores = hits.Load(), misses.Load(), stores.Load()
+	r.Cache.Size = cache.Len()
+	return r, stats, err
+}
+
+// memo memoizes stopping times in a fib.Cache.
+type memo struct {
+	cache                fib.Cache
+	limit                int // largest value stored
+	hits, misses, stores *atomic.Uint64
+}
+
+// stoppingTime walks the chain from n until it reaches 1 or a value whose
+// stopping time is cached, then stores the stopping times of the values it
+// passed through.
+func (m memo) stoppingTime(n int) int {
+	var path []int
+	steps := 0
+	for v := n; ; v = next(v) {
+		if v == 1 {
+			break
+		}
+		if v <= m.limit {
+			if s, ok := m.cache.Load(v); ok {
+				m.hits.Add(1)
+				steps = int(s.Int64())
+				break
+			}
+			m.misses.Add(1)
+		}
+		path = append(path, v)
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		steps++
+		if v := path[i]; v <= m.limit {
+			m.cache.Store(v, big.NewInt(int64(steps)))
+			m.stores.Add(1)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,63 @@
+// Package demo runs the workloads that sit alongside the Fibonacci
+// calculator: data-parallel and divide-and-conquer problems that exercise
+// the same worker pool and are reported with the same statistics. Each
+// workload lives in a subpackage and splits its problem into independent
+// tasks for Run.
+package demo
+
+import (
+	"context"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/internal/histogram"
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+// Stats describes the tasks of a workload run.
+type Stats struct {
+	// Workers is the size of the pool the tasks ran on.
+	Workers int `json:"workers"`
+	// Tasks is the number of tasks that completed.
+	Tasks int `json:"tasks"`
+	// Elapsed is the wall time of the whole run.
+	Elapsed time.Duration `json:"elapsed_ns"`
+	// Latency summarizes the durations of the individual tasks.
+	Latency histogram.Summary `json:"latency"`
+}
+
+// Run calls task for each i from 0 to tasks-1 on a pool of the given number
+// of workers, passing the ID of the worker making the call, from 1 to
+// workers, and timing every call. The first task to fail cancels the
+// context passed to the rest, and those not yet started are skipped; Run
+// returns once every started task has finished, with that error.
+func Run(ctx context.Context, workers, tasks int, task func(ctx context.Context, i, worker int) error) (Stats, error) {
+	start := time.Now()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	h := histogram.New()
+	p := pool.New(workers)
+	for i := range tasks {
+		p.Submit(func(worker int) {
+			if ctx.Err() != nil {
+				return
+			}
+			t := time.Now()
+			if err := task(ctx, i, worker); err != nil {
+				cancel(err)
+				return
+			}
+			h.Record(time.Since(t))
+		})
+	}
+	p.Close()
+	p.Wait()
+
+	s := Stats{
+		Workers: p.Workers(),
+		Tasks:   int(h.Count()),
+		Elapsed: time.Since(start),
+		Latency: h.Summary(),
+	}
+	return s, context.Cause(ctx)
+}
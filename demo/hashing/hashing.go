@@ -0,0 +1,69 @@
+// Package hashing measures how SHA-256 throughput scales across workers. It
+// does nothing but compute, so it serves as a baseline for the raw CPU
+// capacity of the machine when reading Fibonacci timings dominated by
+// simulated sleeps.
+package hashing
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+)
+
+// distinct is how many different buffers are hashed; tasks cycle through
+// them, so memory use stays bounded however many there are.
+const distinct = 16
+
+// Result is the outcome of a hashing run.
+type Result struct {
+	// Bytes is the total amount of data hashed.
+	Bytes int64 `json:"bytes"`
+	// MBps is the throughput in megabytes (10⁶ bytes) per second.
+	MBps float64 `json:"mb_per_s"`
+	// Digest is the SHA-256 of every digest in turn, which depends only
+	// on what was hashed, to show that the work was really done.
+	Digest string `json:"digest"`
+}
+
+// Buffers returns the random buffers of the given size that Hash works
+// through, the same for the same seed.
+func Buffers(size int, seed uint64) [][]byte {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	bufs := make([][]byte, distinct)
+	for i := range bufs {
+		bufs[i] = make([]byte, size)
+		for j := range bufs[i] {
+			bufs[i][j] = byte(rng.Uint32())
+		}
+	}
+	return bufs
+}
+
+// Hash computes the SHA-256 digests of count buffers, cycling through
+// bufs, each digest a task on a pool of workers.
+func Hash(ctx context.Context, bufs [][]byte, count, workers int) (Result, demo.Stats, error) {
+	if len(bufs) == 0 || count < 1 {
+		return Result{}, demo.Stats{}, fmt.Errorf("hashing: need buffers and a positive count, got %d buffers and count %d", len(bufs), count)
+	}
+	digests := make([][sha256.Size]byte, count)
+	stats, err := demo.Run(ctx, workers, count, func(ctx context.Context, i, _ int) error {
+		digests[i] = sha256.Sum256(bufs[i%len(bufs)])
+		return ctx.Err()
+	})
+	if err != nil {
+		return Result{}, stats, err
+	}
+
+	var r Result
+	all := sha256.New()
+	for i, d := range digests {
+		r.Bytes += int64(len(bufs[i%len(bufs)]))
+		all.Write(d[:])
+	}
+	r.MBps = float64(r.Bytes) / stats.Elapsed.Seconds() / 1e6
+	r.Digest = fmt.Sprintf("%x", all.Sum(nil))
+	return r, stats, nil
+}
@@ -0,0 +1,242 @@
+// Package life runs Conway's Game of Life on a toroidal grid split into
+// horizontal bands, one per worker. Between generations neighbouring bands
+// exchange their edge rows, the halo each needs to step its own cells, and
+// all of them meet at a barrier, so that every generation is complete
+// before the next begins.
+package life
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+	"github.com/ZapGaming/Mass-Junk-Code/internal/histogram"
+)
+
+// Grid is a W×H grid of cells, stored row by row, whose edges wrap around.
+type Grid struct {
+	W, H  int
+	Cells []bool
+}
+
+// Random returns a grid on which each cell is alive with the given
+// probability, the same for the same seed.
+func Random(w, h int, density float64, seed uint64) *Grid {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	g := &Grid{W: w, H: h, Cells: make([]bool, w*h)}
+	for i := range g.Cells {
+		g.Cells[i] = rng.Float64() < density
+	}
+	return g
+}
+
+// Alive counts the live cells.
+func (g *Grid) Alive() int {
+	n := 0
+	for _, c := range g.Cells {
+		if c {
+			n++
+		}
+	}
+	return n
+}
+
+// String draws the grid with one character per cell.
+func (g *Grid) String() string {
+	var b strings.Builder
+	for y := range g.H {
+		for _, c := range g.Cells[y*g.W : (y+1)*g.W] {
+			if c {
+				b.WriteRune('█')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Result is the outcome of a simulation.
+type Result struct {
+	Generations int `json:"generations"`
+	Bands       int `json:"bands"`
+	// Alive is the population after the last generation.
+	Alive int `json:"alive"`
+}
+
+// Run advances g by the given number of generations in place, on one
+// goroutine per band of rows, as many bands as workers but no more than
+// rows. After each generation, once every band has written its rows back
+// to g, onGeneration, if set, is called with the generation number and g,
+// which it must not keep or modify. Each band's step of a generation
+// counts as a task in the returned Stats.
+//
+// If ctx is cancelled, Run stops after the generation in progress and
+// returns the context's error.
+func Run(ctx context.Context, g *Grid, generations, workers int, onGeneration func(gen int, g *Grid)) (Result, demo.Stats, error) {
+	if g.W < 1 || g.H < 1 || len(g.Cells) != g.W*g.H {
+		return Result{}, demo.Stats{}, fmt.Errorf("life: malformed %dx%d grid", g.W, g.H)
+	}
+	bands := min(max(workers, 1), g.H)
+	start := time.Now()
+	latency := histogram.New()
+
+	// above[i] carries the bottom row of the band above band i, and
+	// below[i] the top row of the band below it.
+	above := make([]chan []bool, bands)
+	below := make([]chan []bool, bands)
+	for i := range bands {
+		above[i] = make(chan []bool, 1)
+		below[i] = make(chan []bool, 1)
+	}
+
+	var (
+		b    = newBarrier(bands)
+		stop bool // set by band 0 between the two barriers of a generation
+		done int
+		err  error
+	)
+	var wg sync.WaitGroup
+	for i := range bands {
+		lo, hi := i*g.H/bands, (i+1)*g.H/bands
+		up, down := (i+bands-1)%bands, (i+1)%bands
+		wg.Go(func() {
+			band := newBand(g, lo, hi)
+			for gen := 1; gen <= generations; gen++ {
+				t := time.Now()
+				// Send the edges first; the channels hold one row each,
+				// so no band waits on another to receive.
+				below[up] <- band.row(0)
+				above[down] <- band.row(hi - lo - 1)
+				band.step(<-above[i], <-below[i])
+				band.store(g)
+				latency.Record(time.Since(t))
+
+				b.wait()
+				if i == 0 {
+					done = gen
+					if onGeneration != nil {
+						onGeneration(gen, g)
+					}
+					if err = ctx.Err(); err != nil {
+						stop = true
+					}
+				}
+				b.wait()
+				if stop {
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	stats := demo.Stats{
+		Workers: bands,
+		Tasks:   int(latency.Count()),
+		Elapsed: time.Since(start),
+		Latency: latency.Summary(),
+	}
+	return Result{Generations: done, Bands: bands, Alive: g.Alive()}, stats, err
+}
+
+// band is one worker's rows of the grid, with room for a halo row above and
+// below.
+type band struct {
+	w         int
+	lo        int      // first row of the grid held
+	cur, next [][]bool // rows 1 to len-2 are the band's own
+}
+
+func newBand(g *Grid, lo, hi int) *band {
+	b := &band{w: g.W, lo: lo}
+	b.cur = make([][]bool, hi-lo+2)
+	b.next = make([][]bool, hi-lo+2)
+	for r := range b.cur {
+		b.cur[r] = make([]bool, g.W)
+		b.next[r] = make([]bool, g.W)
+	}
+	for r := range hi - lo {
+		copy(b.cur[r+1], g.Cells[(lo+r)*g.W:(lo+r+1)*g.W])
+	}
+	return b
+}
+
+// row returns a copy of the band's own row r, counting from 0, for a
+// neighbour's halo.
+func (b *band) row(r int) []bool {
+	return append([]bool(nil), b.cur[r+1]...)
+}
+
+// step advances the band a generation, given the halo rows.
+func (b *band) step(top, bottom []bool) {
+	n := len(b.cur)
+	b.cur[0], b.cur[n-1] = top, bottom
+	for r := 1; r < n-1; r++ {
+		for x := range b.w {
+			left, right := (x+b.w-1)%b.w, (x+1)%b.w
+			alive := 0
+			for _, row := range b.cur[r-1 : r+2] {
+				for _, c := range [3]bool{row[left], row[x], row[right]} {
+					if c {
+						alive++
+					}
+				}
+			}
+			self := b.cur[r][x]
+			if self {
+				alive--
+			}
+			b.next[r][x] = alive == 3 || self && alive == 2
+		}
+	}
+	b.cur, b.next = b.next, b.cur
+	// The halos were neighbours' rows; give the slots fresh rows of their
+	// own for the next swap.
+	b.cur[0], b.cur[n-1] = make([]bool, b.w), make([]bool, b.w)
+}
+
+// store writes the band's rows back into g.
+func (b *band) store(g *Grid) {
+	for r := 1; r < len(b.cur)-1; r++ {
+		copy(g.Cells[(b.lo+r-1)*g.W:], b.cur[r])
+	}
+}
+
+// barrier makes a fixed number of goroutines wait for one another, any
+// number of times.
+type barrier struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	n       int
+	waiting int
+	round   int
+}
+
+func newBarrier(n int) *barrier {
+	b := &barrier{n: n}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// wait blocks until all n goroutines have called it in the current round.
+func (b *barrier) wait() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	round := b.round
+	b.waiting++
+	if b.waiting == b.n {
+		b.waiting = 0
+		b.round++
+		b.cond.Broadcast()
+		return
+	}
+	for round == b.round {
+		b.cond.Wait()
+	}
+}
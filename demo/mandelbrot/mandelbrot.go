@@ -0,0 +1,104 @@
+// Package mandelbrot renders the Mandelbrot set tile by tile in parallel, a
+// CPU-bound workload whose tasks vary widely in cost: tiles inside the set
+// run every iteration, while those far outside escape at once.
+package mandelbrot
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+)
+
+// Options describe the image to render. The zero value of each field but
+// Width and Height means its default.
+type Options struct {
+	// Width and Height are the size of the image in pixels.
+	Width, Height int
+	// CenterX, CenterY and Scale place the view: the image is centred on
+	// CenterX + CenterY·i, and Scale is the width of the complex plane
+	// it shows. Scale 0 means 3.5, wide enough for the whole set when
+	// centred on -0.75.
+	CenterX, CenterY, Scale float64
+	// MaxIter is how many iterations a point may take to escape before it
+	// counts as inside the set. Zero means 256.
+	MaxIter int
+	// Tile is the side of the square tiles rendered as separate tasks.
+	// Zero means 64.
+	Tile int
+}
+
+// Render draws the view described by o, each tile a task on a pool of
+// workers.
+func Render(ctx context.Context, o Options, workers int) (*image.RGBA, demo.Stats, error) {
+	if o.Width < 1 || o.Height < 1 {
+		return nil, demo.Stats{}, fmt.Errorf("mandelbrot: image must be at least 1x1, got %dx%d", o.Width, o.Height)
+	}
+	if o.Scale == 0 {
+		o.Scale = 3.5
+	}
+	if o.MaxIter <= 0 {
+		o.MaxIter = 256
+	}
+	if o.Tile <= 0 {
+		o.Tile = 64
+	}
+	img := image.NewRGBA(image.Rect(0, 0, o.Width, o.Height))
+	cols := (o.Width + o.Tile - 1) / o.Tile
+	rows := (o.Height + o.Tile - 1) / o.Tile
+	stats, err := demo.Run(ctx, workers, cols*rows, func(ctx context.Context, i, _ int) error {
+		x0, y0 := i%cols*o.Tile, i/cols*o.Tile
+		r := image.Rect(x0, y0, x0+o.Tile, y0+o.Tile).Intersect(img.Bounds())
+		o.renderTile(img, r)
+		return ctx.Err()
+	})
+	return img, stats, err
+}
+
+// renderTile draws the pixels of img within r. Tiles don't overlap, so
+// concurrent calls write disjoint parts of img.
+func (o Options) renderTile(img *image.RGBA, r image.Rectangle) {
+	step := o.Scale / float64(o.Width)
+	left := o.CenterX - step*float64(o.Width)/2
+	top := o.CenterY + step*float64(o.Height)/2
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		ci := top - step*float64(y)
+		for x := r.Min.X; x < r.Max.X; x++ {
+			cr := left + step*float64(x)
+			img.SetRGBA(x, y, o.shade(escape(cr, ci, o.MaxIter)))
+		}
+	}
+}
+
+// escape returns how many iterations of z ← z² + c it takes z to leave the
+// circle of radius 2, smoothed to a fraction, or -1 if it stays within
+// maxIter iterations.
+func escape(cr, ci float64, maxIter int) float64 {
+	var zr, zi float64
+	for n := range maxIter {
+		zr, zi = zr*zr-zi*zi+cr, 2*zr*zi+ci
+		if m := zr*zr + zi*zi; m > 4 {
+			// Smooth the bands between iteration counts.
+			return float64(n) + 1 - math.Log2(math.Log2(m)/2)
+		}
+	}
+	return -1
+}
+
+// shade colours a point by its escape time: black inside the set, and
+// blue through white to orange outside it.
+func (o Options) shade(n float64) color.RGBA {
+	if n < 0 {
+		return color.RGBA{A: 255}
+	}
+	t := math.Sqrt(n / float64(o.MaxIter))
+	return color.RGBA{
+		R: uint8(255 * min(1, 2*t)),
+		G: uint8(255 * min(1, 1.5*t)),
+		B: uint8(255 * min(1, 0.4+t)),
+		A: 255,
+	}
+}
@@ -0,0 +1,99 @@
+// Package matmul multiplies dense matrices with a blocked parallel
+// algorithm, a compute- and memory-bandwidth-bound workload to set against
+// the latency-bound simulated work of the Fibonacci calculator.
+package matmul
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+)
+
+// DefaultBlock is the side of the square blocks of the result computed as
+// separate tasks unless told otherwise. Three 64×64 blocks of float64 fit
+// comfortably in a typical L2 cache.
+const DefaultBlock = 64
+
+// Matrix is a square matrix of float64, stored row by row.
+type Matrix struct {
+	N    int
+	Data []float64
+}
+
+// New returns an n×n matrix of zeros.
+func New(n int) *Matrix {
+	return &Matrix{N: n, Data: make([]float64, n*n)}
+}
+
+// Random returns an n×n matrix of values in [0, 1), the same for the same
+// seed.
+func Random(n int, seed uint64) *Matrix {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	m := New(n)
+	for i := range m.Data {
+		m.Data[i] = rng.Float64()
+	}
+	return m
+}
+
+// At returns the element in row i and column j.
+func (m *Matrix) At(i, j int) float64 { return m.Data[i*m.N+j] }
+
+// Flops is the number of floating-point operations in multiplying two n×n
+// matrices, a multiplication and an addition for each of n³ terms.
+func Flops(n int) float64 {
+	return 2 * float64(n) * float64(n) * float64(n)
+}
+
+// Tasks is the number of tasks Multiply splits an n×n product into with the
+// given block size.
+func Tasks(n, block int) int {
+	if block <= 0 {
+		block = DefaultBlock
+	}
+	b := (n + block - 1) / block
+	return b * b
+}
+
+// Multiply returns a×b. The result is cut into square blocks of the given
+// side, or DefaultBlock if it is not positive, and each block is computed
+// as a task on a pool of workers, walking the matching rows of a and
+// columns of b block by block so that the data in use stays in cache.
+func Multiply(ctx context.Context, a, b *Matrix, block, workers int) (*Matrix, demo.Stats, error) {
+	if a.N != b.N {
+		return nil, demo.Stats{}, fmt.Errorf("matmul: cannot multiply %dx%d by %dx%d", a.N, a.N, b.N, b.N)
+	}
+	if block <= 0 {
+		block = DefaultBlock
+	}
+	n := a.N
+	c := New(n)
+	blocks := (n + block - 1) / block
+	stats, err := demo.Run(ctx, workers, blocks*blocks, func(ctx context.Context, t, _ int) error {
+		i0, j0 := t/blocks*block, t%blocks*block
+		i1, j1 := min(i0+block, n), min(j0+block, n)
+		for k0 := 0; k0 < n; k0 += block {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			k1 := min(k0+block, n)
+			for i := i0; i < i1; i++ {
+				row := c.Data[i*n : (i+1)*n]
+				for k := k0; k < k1; k++ {
+					aik := a.Data[i*n+k]
+					bk := b.Data[k*n : (k+1)*n]
+					for j := j0; j < j1; j++ {
+						row[j] += aik * bk[j]
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, stats, err
+	}
+	return c, stats, nil
+}
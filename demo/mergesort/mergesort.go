@@ -0,0 +1,123 @@
+// Package mergesort sorts slices with a parallel merge sort, demonstrating
+// divide-and-conquer parallelism on a bounded number of goroutines.
+package mergesort
+
+import (
+	"cmp"
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+	"github.com/ZapGaming/Mass-Junk-Code/internal/histogram"
+)
+
+// DefaultCutoff is the length below which a slice is sorted sequentially
+// unless told otherwise: short enough for the halves to be spread over the
+// workers, long enough that a goroutine's cost is lost in the sorting.
+const DefaultCutoff = 1 << 13
+
+// Generate returns n random integers, the same for the same seed.
+func Generate(n int, seed uint64) []int {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	s := make([]int, n)
+	for i := range s {
+		s[i] = rng.Int()
+	}
+	return s
+}
+
+// Sort sorts s in ascending order. It splits s in half recursively until
+// the pieces are no longer than cutoff, or DefaultCutoff if cutoff is not
+// positive, sorts those with slices.Sort and merges them back. The halves
+// of a split are sorted in parallel while fewer than workers goroutines
+// are busy, the calling one included, and one after the other otherwise.
+// Each sequential sort counts as a task in the returned Stats.
+//
+// If ctx is cancelled, Sort stops and returns its error, leaving s
+// partially sorted.
+func Sort[T cmp.Ordered](ctx context.Context, s []T, cutoff, workers int) (demo.Stats, error) {
+	if cutoff <= 0 {
+		cutoff = DefaultCutoff
+	}
+	workers = max(workers, 1)
+	m := merger[T]{
+		cutoff: cutoff,
+		// The calling goroutine needs no token.
+		tokens:  make(chan struct{}, workers-1),
+		buf:     make([]T, len(s)),
+		latency: histogram.New(),
+	}
+	start := time.Now()
+	err := m.sort(ctx, s, m.buf)
+	return demo.Stats{
+		Workers: workers,
+		Tasks:   int(m.latency.Count()),
+		Elapsed: time.Since(start),
+		Latency: m.latency.Summary(),
+	}, err
+}
+
+// merger holds the settings and scratch space of one Sort.
+type merger[T cmp.Ordered] struct {
+	cutoff  int
+	tokens  chan struct{} // one held by each extra goroutine
+	buf     []T           // scratch space as long as the slice being sorted
+	latency *histogram.Histogram
+}
+
+// sort sorts s using buf, a slice of the same length, as scratch space.
+func (m *merger[T]) sort(ctx context.Context, s, buf []T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(s) <= m.cutoff {
+		start := time.Now()
+		slices.Sort(s)
+		m.latency.Record(time.Since(start))
+		return nil
+	}
+
+	mid := len(s) / 2
+	var leftErr, rightErr error
+	select {
+	case m.tokens <- struct{}{}:
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			defer func() { <-m.tokens }()
+			leftErr = m.sort(ctx, s[:mid], buf[:mid])
+		})
+		rightErr = m.sort(ctx, s[mid:], buf[mid:])
+		wg.Wait()
+	default:
+		leftErr = m.sort(ctx, s[:mid], buf[:mid])
+		if leftErr == nil {
+			rightErr = m.sort(ctx, s[mid:], buf[mid:])
+		}
+	}
+	if err := cmp.Or(leftErr, rightErr); err != nil {
+		return err
+	}
+	merge(s, buf, mid)
+	return nil
+}
+
+// merge merges the sorted halves s[:mid] and s[mid:] in place, by way of
+// buf.
+func merge[T cmp.Ordered](s, buf []T, mid int) {
+	copy(buf, s)
+	left, right := buf[:mid], buf[mid:]
+	i := 0
+	for len(left) > 0 && len(right) > 0 {
+		if right[0] < left[0] {
+			s[i], right = right[0], right[1:]
+		} else {
+			s[i], left = left[0], left[1:]
+		}
+		i++
+	}
+	i += copy(s[i:], left)
+	copy(s[i:], right)
+}
@@ -0,0 +1,35 @@
+package mergesort
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// BenchmarkSort compares the parallel merge sort, across cutoffs and worker
+// counts, with a sequential slices.Sort of the same data.
+func BenchmarkSort(b *testing.B) {
+	const size = 1 << 20
+	data := Generate(size, 1)
+	s := make([]int, size)
+
+	b.Run("slices.Sort", func(b *testing.B) {
+		for b.Loop() {
+			copy(s, data)
+			slices.Sort(s)
+		}
+	})
+	for _, cutoff := range []int{1 << 10, DefaultCutoff, 1 << 16} {
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("cutoff=%d/workers=%d", cutoff, workers), func(b *testing.B) {
+				for b.Loop() {
+					copy(s, data)
+					if _, err := Sort(context.Background(), s, cutoff, workers); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package montecarlo estimates π by sampling random points in the unit
+// square and counting those inside the quarter circle, an embarrassingly
+// parallel counterpart to the dependency-heavy Fibonacci workload.
+package montecarlo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+)
+
+// DefaultBatch is the number of samples each task draws unless told
+// otherwise.
+const DefaultBatch = 1 << 20
+
+// Result is the outcome of an estimate.
+type Result struct {
+	Samples int64 `json:"samples"`
+	Inside  int64 `json:"inside"`
+	// Pi is the estimate, 4 × Inside / Samples.
+	Pi float64 `json:"pi"`
+	// Error is how far Pi is from math.Pi.
+	Error float64 `json:"error"`
+	// StdErr is the standard error expected of an estimate from this many
+	// samples; Error should be within a few of them.
+	StdErr float64 `json:"std_err"`
+	// Convergence traces the estimate as batches completed, at every
+	// power of two of them and at the end.
+	Convergence []Point `json:"convergence"`
+}
+
+// Point is the estimate after a number of samples.
+type Point struct {
+	Samples int64   `json:"samples"`
+	Pi      float64 `json:"pi"`
+	Error   float64 `json:"error"`
+}
+
+// Estimate draws samples points, in batches of the given size, or
+// DefaultBatch if it is not positive, with each batch a task on a pool of
+// workers. Every worker has its own generator, seeded from seed and its ID,
+// so workers never contend for one; which batches a worker draws depends
+// on scheduling, so only a single worker makes the estimate repeatable.
+func Estimate(ctx context.Context, samples int64, batch, workers int, seed uint64) (Result, demo.Stats, error) {
+	if samples < 1 {
+		return Result{}, demo.Stats{}, fmt.Errorf("montecarlo: need at least one sample, got %d", samples)
+	}
+	if batch <= 0 {
+		batch = DefaultBatch
+	}
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	rngs := make([]*rand.Rand, max(workers, 1))
+	for i := range rngs {
+		rngs[i] = rand.New(rand.NewPCG(seed, uint64(i+1)))
+	}
+
+	var (
+		mu      sync.Mutex
+		r       Result
+		batches int
+	)
+	tasks := int((samples + int64(batch) - 1) / int64(batch))
+	stats, err := demo.Run(ctx, workers, tasks, func(ctx context.Context, i, worker int) error {
+		n := min(int64(batch), samples-int64(i)*int64(batch))
+		inside := sample(rngs[worker-1], n)
+
+		mu.Lock()
+		defer mu.Unlock()
+		r.Samples += n
+		r.Inside += inside
+		batches++
+		if bits.OnesCount(uint(batches)) == 1 || batches == tasks {
+			r.Convergence = append(r.Convergence, r.point())
+		}
+		return ctx.Err()
+	})
+	if r.Samples > 0 {
+		p := r.point()
+		r.Pi, r.Error = p.Pi, p.Error
+		q := float64(r.Inside) / float64(r.Samples)
+		r.StdErr = 4 * math.Sqrt(q*(1-q)/float64(r.Samples))
+	}
+	return r, stats, err
+}
+
+// point is the estimate so far.
+func (r *Result) point() Point {
+	pi := 4 * float64(r.Inside) / float64(r.Samples)
+	return Point{Samples: r.Samples, Pi: pi, Error: math.Abs(pi - math.Pi)}
+}
+
+// sample draws n points and counts those inside the quarter circle.
+func sample(rng *rand.Rand, n int64) int64 {
+	var inside int64
+	for range n {
+		x, y := rng.Float64(), rng.Float64()
+		if x*x+y*y <= 1 {
+			inside++
+		}
+	}
+	return inside
+}
@@ -0,0 +1,82 @@
+// Package queens counts the solutions to the N-queens puzzle by
+// backtracking, one task for each column the first queen can take, so that
+// independent subtrees of the search are explored in parallel and their
+// counts summed at the end.
+package queens
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+)
+
+// MaxN is the largest board Count accepts; the search keeps the attacked
+// columns and diagonals of a row in the bits of a uint32.
+const MaxN = 32
+
+// Result is the outcome of a search.
+type Result struct {
+	N int `json:"n"`
+	// Solutions is the number of ways to place N non-attacking queens.
+	Solutions uint64 `json:"solutions"`
+	// PerColumn holds the solutions with the first-row queen in each column.
+	PerColumn []uint64 `json:"per_column"`
+	// Rate is Solutions per second of wall-clock time.
+	Rate float64 `json:"solutions_per_second"`
+}
+
+// Count finds every placement of n queens on an n×n board, placing the
+// queen of the first row in each column as a separate task on a pool of
+// workers.
+func Count(ctx context.Context, n, workers int) (Result, demo.Stats, error) {
+	if n < 1 || n > MaxN {
+		return Result{}, demo.Stats{}, fmt.Errorf("queens: n must be between 1 and %d, got %d", MaxN, n)
+	}
+	all := uint32(1<<n - 1)
+	perColumn := make([]uint64, n)
+	stats, err := demo.Run(ctx, workers, n, func(ctx context.Context, col, _ int) error {
+		bit := uint32(1) << col
+		var err error
+		perColumn[col], err = solve(ctx, all, bit, bit<<1, bit>>1, 1, n)
+		return err
+	})
+	if err != nil {
+		return Result{}, stats, err
+	}
+
+	r := Result{N: n, PerColumn: perColumn}
+	for _, s := range perColumn {
+		r.Solutions += s
+	}
+	if secs := stats.Elapsed.Seconds(); secs > 0 {
+		r.Rate = float64(r.Solutions) / secs
+	}
+	return r, stats, nil
+}
+
+// solve counts the completions of a board with queens in the first row
+// rows, given the columns they occupy and the squares of the next row they
+// attack along each diagonal.
+func solve(ctx context.Context, all, cols, left, right uint32, row, n int) (uint64, error) {
+	if row == n {
+		return 1, nil
+	}
+	// Checking at every node would dominate the search; the second row is
+	// often enough for a prompt cancellation without costing anything.
+	if row == 1 {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	var count uint64
+	for free := all &^ (cols | left | right); free != 0; free &= free - 1 {
+		bit := free & -free
+		c, err := solve(ctx, all, cols|bit, (left|bit)<<1&all, (right|bit)>>1, row+1, n)
+		if err != nil {
+			return 0, err
+		}
+		count += c
+	}
+	return count, nil
+}
@@ -0,0 +1,109 @@
+// Package sieve counts primes with a parallel segmented Sieve of
+// Eratosthenes, a data-parallel counterpart to the recursive Fibonacci
+// workload.
+package sieve
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+)
+
+// DefaultSegment is the number of integers each task sieves unless told
+// otherwise: small enough for its bitmap to stay in cache.
+const DefaultSegment = 1 << 18
+
+// Result is the outcome of a sieve.
+type Result struct {
+	// Limit is the bound the primes were sought below or at.
+	Limit int `json:"limit"`
+	// Count is the number of primes up to Limit.
+	Count int `json:"count"`
+	// Largest is the largest of them, or 0 if there are none.
+	Largest int `json:"largest"`
+}
+
+// Count finds the primes up to limit. The primes up to √limit are sieved
+// first, by a single goroutine; the rest of the range is then cut into
+// segments of the given size, or DefaultSegment if it is not positive, and
+// each segment is sieved with those primes as a separate task on a pool of
+// workers.
+func Count(ctx context.Context, limit, segment, workers int) (Result, demo.Stats, error) {
+	if limit < 0 {
+		return Result{}, demo.Stats{}, fmt.Errorf("sieve: limit must be non-negative, got %d", limit)
+	}
+	if segment <= 0 {
+		segment = DefaultSegment
+	}
+	root := int(math.Sqrt(float64(limit)))
+	for (root+1)*(root+1) <= limit {
+		root++ // float64 can round √limit down
+	}
+	base := simple(root)
+
+	// Segment i covers [i*segment, (i+1)*segment), clipped to limit.
+	segments := limit/segment + 1
+	counts := make([]int, segments)
+	largest := make([]int, segments)
+	stats, err := demo.Run(ctx, workers, segments, func(ctx context.Context, i, _ int) error {
+		lo := i * segment
+		hi := min(lo+segment, limit+1)
+		counts[i], largest[i] = sieveSegment(lo, hi, base)
+		return ctx.Err()
+	})
+	if err != nil {
+		return Result{}, stats, err
+	}
+
+	r := Result{Limit: limit}
+	for i := range segments {
+		r.Count += counts[i]
+		r.Largest = max(r.Largest, largest[i])
+	}
+	return r, stats, nil
+}
+
+// simple returns the primes up to n with an ordinary sieve.
+func simple(n int) []int {
+	if n < 2 {
+		return nil
+	}
+	composite := make([]bool, n+1)
+	var primes []int
+	for p := 2; p <= n; p++ {
+		if composite[p] {
+			continue
+		}
+		primes = append(primes, p)
+		for m := p * p; m <= n; m += p {
+			composite[m] = true
+		}
+	}
+	return primes
+}
+
+// sieveSegment counts the primes in [lo, hi), and finds the largest, given
+// every prime up to √hi.
+func sieveSegment(lo, hi int, base []int) (count, largest int) {
+	composite := make([]bool, hi-lo)
+	for _, p := range base {
+		if p*p >= hi {
+			break
+		}
+		// Start at the first multiple of p in range, but not below p²,
+		// whose smaller multiples have smaller factors too.
+		first := max(p*p, (lo+p-1)/p*p)
+		for m := first; m < hi; m += p {
+			composite[m-lo] = true
+		}
+	}
+	for n := max(lo, 2); n < hi; n++ {
+		if !composite[n-lo] {
+			count++
+			largest = n
+		}
+	}
+	return count, largest
+}
@@ -0,0 +1,114 @@
+// Package wordcount counts word frequencies across files in map-reduce
+// style: each file is read and counted as a task on the worker pool, mixing
+// IO with CPU work, and the per-file counts are then merged into one
+// frequency table.
+package wordcount
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ZapGaming/Mass-Junk-Code/demo"
+)
+
+// Entry is a word and the number of times it occurs.
+type Entry struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Result is the outcome of a word count.
+type Result struct {
+	Files int `json:"files"`
+	// Words is the number of words read, and Distinct the number of
+	// different ones.
+	Words    int `json:"words"`
+	Distinct int `json:"distinct"`
+	// Table lists the words, most frequent first and alphabetically among
+	// equals.
+	Table []Entry `json:"table"`
+}
+
+// Expand returns the files matched by the given glob patterns, in order and
+// without repeats. A pattern that matches nothing is an error, unless it has
+// no glob characters: then it names a file, and Count will report it if it
+// doesn't exist.
+func Expand(patterns []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, p := range patterns {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("wordcount: bad pattern %q: %w", p, err)
+		}
+		if matches == nil {
+			if strings.ContainsAny(p, `*?[\`) {
+				return nil, fmt.Errorf("wordcount: no files match %q", p)
+			}
+			matches = []string{p}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	return files, nil
+}
+
+// Count counts the words in files, each file a map task on a pool of
+// workers, and reduces the counts into a single table. Words are maximal
+// runs of letters, digits and apostrophes, folded to lower case.
+func Count(ctx context.Context, files []string, workers int) (Result, demo.Stats, error) {
+	counts := make([]map[string]int, len(files))
+	stats, err := demo.Run(ctx, workers, len(files), func(ctx context.Context, i, _ int) error {
+		data, err := os.ReadFile(files[i])
+		if err != nil {
+			return err
+		}
+		counts[i] = countWords(string(data))
+		return ctx.Err()
+	})
+	if err != nil {
+		return Result{}, stats, err
+	}
+
+	total := make(map[string]int)
+	r := Result{Files: len(files)}
+	for _, m := range counts {
+		for w, n := range m {
+			total[w] += n
+			r.Words += n
+		}
+	}
+	r.Distinct = len(total)
+	r.Table = make([]Entry, 0, len(total))
+	for w, n := range total {
+		r.Table = append(r.Table, Entry{w, n})
+	}
+	slices.SortFunc(r.Table, func(a, b Entry) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Word, b.Word))
+	})
+	return r, stats, nil
+}
+
+// countWords is the map step: the frequency of each word in text.
+func countWords(text string) map[string]int {
+	m := make(map[string]int)
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	for _, w := range words {
+		if w = strings.Trim(w, "'"); w != "" {
+			m[strings.ToLower(w)]++
+		}
+	}
+	return m
+}
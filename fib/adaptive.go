@@ -0,0 +1,180 @@
+package fib
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultTuneInterval is how often an adaptive run reconsiders its
+// concurrency unless Adaptive.Interval says otherwise.
+const DefaultTuneInterval = 100 * time.Millisecond
+
+// Adaptive makes batch runs tune their own concurrency instead of keeping
+// to Config.Workers, which becomes the starting point. After every interval
+// the run compares the mean latency of the computations that finished
+// against the best it has seen: while latency holds, it admits one more
+// concurrent computation if throughput held up too, and once latency
+// degrades it cuts concurrency by a quarter, in the additive-increase,
+// multiplicative-decrease manner of TCP congestion control.
+type Adaptive struct {
+	// Min and Max bound the concurrency, neither more than MaxWorkers.
+	// Zero means 1 and four times Workers respectively.
+	Min, Max int
+
+	// Interval is how often concurrency is adjusted. Zero means
+	// DefaultTuneInterval. It is measured in real time.
+	Interval time.Duration
+
+	// LatencyTolerance is how many times the best mean latency a run may
+	// see before it backs off. Zero means 2.
+	LatencyTolerance float64
+
+	// OnSample, if set, is called after every interval with what was
+	// observed and the concurrency chosen for the next one. Calls are made
+	// one at a time, and all of a run's calls are made before it returns.
+	OnSample func(ConcurrencySample)
+}
+
+// ConcurrencySample is what an adaptive run observed over one interval.
+type ConcurrencySample struct {
+	// Elapsed is the time since the run started.
+	Elapsed time.Duration `json:"elapsed_ns"`
+	// Workers is how many computations were allowed to run at once during
+	// the interval, and Next how many will be during the following one.
+	Workers int `json:"workers"`
+	Next    int `json:"next"`
+	// Throughput is the number of computations finished per second.
+	Throughput float64 `json:"throughput"`
+	// Latency is their mean duration.
+	Latency time.Duration `json:"latency_ns"`
+}
+
+// tuner limits how many computations of a run proceed at once, adjusting
+// the limit as described for Adaptive.
+type tuner struct {
+	min, max  int
+	tolerance float64
+	interval  time.Duration
+	onSample  func(ConcurrencySample)
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	limit  int
+	active int
+
+	// Observations over the current interval, and from earlier ones.
+	done     int
+	total    time.Duration
+	best     time.Duration
+	prevRate float64
+}
+
+func newTuner(a Adaptive, workers int) *tuner {
+	t := &tuner{
+		min:       max(a.Min, 1),
+		max:       a.Max,
+		tolerance: a.LatencyTolerance,
+		interval:  a.Interval,
+		onSample:  a.OnSample,
+	}
+	if t.max == 0 {
+		t.max = min(4*workers, MaxWorkers)
+	}
+	t.max = max(t.max, t.min)
+	if t.tolerance == 0 {
+		t.tolerance = 2
+	}
+	if t.interval <= 0 {
+		t.interval = DefaultTuneInterval
+	}
+	t.limit = min(max(workers, t.min), t.max)
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// acquire blocks until the computation may proceed. A nil tuner never
+// blocks.
+func (t *tuner) acquire() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	for t.active >= t.limit {
+		t.cond.Wait()
+	}
+	t.active++
+	t.mu.Unlock()
+}
+
+// release records that a computation which took d has finished.
+func (t *tuner) release(d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.active--
+	t.done++
+	t.total += d
+	t.mu.Unlock()
+	t.cond.Signal()
+}
+
+// start adjusts the limit every interval until the returned function is
+// called, which waits for the last adjustment to be reported.
+func (t *tuner) start(ctx context.Context) (stop func()) {
+	begin := time.Now()
+	quit := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		tick := time.NewTicker(t.interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+			case <-quit:
+				return
+			case <-ctx.Done():
+				return
+			}
+			s := t.adjust()
+			s.Elapsed = time.Since(begin)
+			if t.onSample != nil {
+				t.onSample(s)
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-exited
+	}
+}
+
+// adjust closes the current interval, setting the limit for the next.
+func (t *tuner) adjust() ConcurrencySample {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := ConcurrencySample{Workers: t.limit, Next: t.limit}
+	if t.done == 0 {
+		// Nothing finished; there is no evidence either way.
+		return s
+	}
+	s.Latency = t.total / time.Duration(t.done)
+	s.Throughput = float64(t.done) / t.interval.Seconds()
+	t.done, t.total = 0, 0
+
+	if t.best == 0 || s.Latency < t.best {
+		t.best = s.Latency
+	}
+	switch {
+	case float64(s.Latency) > t.tolerance*float64(t.best):
+		t.limit = max(min(t.limit*3/4, t.limit-1), t.min)
+	case s.Throughput >= 0.95*t.prevRate:
+		t.limit = min(t.limit+1, t.max)
+	}
+	t.prevRate = s.Throughput
+	s.Next = t.limit
+	t.cond.Broadcast()
+	return s
+}
@@ -0,0 +1,392 @@
+package fib
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ZapGaming/Mass-Junk-Code/internal/singleflight"
+)
+
+// Algorithm is a strategy for computing a single Fibonacci number.
+//
+// Implementations charge one unit of simulated work, via Env.Work, for every
+// step they take, so the cost of different strategies can be compared on the
+// same workload. Compute is only called with n >= 0.
+type Algorithm interface {
+	// Name is the short identifier used to select the algorithm, such as
+	// "iterative".
+	Name() string
+	// Compute returns F(n).
+	Compute(ctx context.Context, n int, env *Env) (*big.Int, error)
+}
+
+// Env is what a Calculator lends an Algorithm while it computes: the
+// memoization cache, the simulated workload and the arithmetic settings.
+type Env struct {
+	cache       Cache
+	machineInts bool
+	workload    Workload
+	rng         *rand.Rand
+	clock       Clock
+	chaos       Chaos
+
+	flight singleflight.Group[int, *big.Int]
+
+	counts *envCounts
+}
+
+// envCounts tallies cache use. Environments of the same Calculator share
+// one.
+type envCounts struct {
+	hits, misses, stores, shared atomic.Uint64
+}
+
+// MachineInts reports whether the algorithm should use int64 arithmetic
+// rather than math/big.
+func (e *Env) MachineInts() bool { return e.machineInts }
+
+// Work performs one unit of the configured workload. With Config.Chaos set,
+// the unit may also fail, panic or take far longer than usual.
+func (e *Env) Work(ctx context.Context) error {
+	if e.chaos.enabled() {
+		if err := e.chaos.inject(ctx, e); err != nil {
+			return err
+		}
+	}
+	return e.workload.Do(ctx, e)
+}
+
+// Rand returns the calculator's random number generator, seeded from
+// Config.Seed. It is safe for concurrent use.
+func (e *Env) Rand() *rand.Rand { return e.rng }
+
+// Clock returns the calculator's clock.
+func (e *Env) Clock() Clock { return e.clock }
+
+// Load returns the cached value of F(n), if there is one. The returned value
+// is shared and must not be modified.
+func (e *Env) Load(n int) (*big.Int, bool) {
+	v, ok := e.cache.Load(n)
+	if ok {
+		e.counts.hits.Add(1)
+	} else {
+		e.counts.misses.Add(1)
+	}
+	return v, ok
+}
+
+// Store caches v as the value of F(n). v must not be modified afterwards.
+func (e *Env) Store(n int, v *big.Int) {
+	e.counts.stores.Add(1)
+	e.cache.Store(n, v)
+}
+
+// Once computes F(n) by calling fn, unless another goroutine sharing this Env
+// is already computing F(n); then it waits for and returns that result
+// instead, so each uncached value is computed only once even when many
+// workers ask for it at the same time. A waiting caller returns early with
+// ErrCancelled or ErrTimeout if ctx is done first, and computes F(n) itself
+// if fn was cut short by the context of the caller that ran it. A panic in
+// fn is returned as a *PanicError to every caller.
+func (e *Env) Once(ctx context.Context, n int, fn func() (*big.Int, error)) (*big.Int, error) {
+	v, err, shared := e.flight.Do(ctx, n, func() (v *big.Int, err error) {
+		// Recover here, rather than further up, so that callers waiting on
+		// this computation see the panic as their error too.
+		defer recoverPanic(&err)
+		return fn()
+	})
+	if shared {
+		e.counts.shared.Add(1)
+		trace.SpanFromContext(ctx).SetAttributes(attrShared.Bool(true))
+	}
+	// A waiter that gave up has ctx's bare error.
+	return v, contextError(err)
+}
+
+// The built-in algorithms.
+var (
+	// Naive is plain doubly-recursive evaluation. It takes time exponential
+	// in n and exists only for comparison.
+	Naive Algorithm = naive{}
+	// Memoized is recursive evaluation with every sub-result cached, the
+	// strategy the original demo used. It is the default.
+	Memoized Algorithm = memoized{}
+	// Iterative walks the sequence upward from F(0) and F(1).
+	Iterative Algorithm = iterative{}
+	// FastDoubling uses the identities F(2k) = F(k)(2F(k+1) - F(k)) and
+	// F(2k+1) = F(k)² + F(k+1)², the closed form of the matrix-power method,
+	// and takes O(log n) steps.
+	FastDoubling Algorithm = fastDoubling{}
+)
+
+// Algorithms lists the built-in algorithms.
+func Algorithms() []Algorithm {
+	return []Algorithm{Naive, Memoized, Iterative, FastDoubling}
+}
+
+// ParseAlgorithm returns the built-in algorithm with the given name.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	var names []string
+	for _, a := range Algorithms() {
+		if a.Name() == name {
+			return a, nil
+		}
+		names = append(names, a.Name())
+	}
+	return nil, fmt.Errorf("fib: unknown algorithm %q (want one of %s)", name, strings.Join(names, ", "))
+}
+
+type naive struct{}
+
+func (naive) Name() string { return "naive" }
+
+func (a naive) Compute(ctx context.Context, n int, env *Env) (*big.Int, error) {
+	if env.MachineInts() {
+		v, err := a.int64(ctx, n, env)
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(v), nil
+	}
+	if n < 2 {
+		return big.NewInt(int64(n)), nil
+	}
+	if err := env.Work(ctx); err != nil {
+		return nil, err
+	}
+	res1, err := a.Compute(ctx, n-1, env)
+	if err != nil {
+		return nil, err
+	}
+	res2, err := a.Compute(ctx, n-2, env)
+	if err != nil {
+		return nil, err
+	}
+	return res1.Add(res1, res2), nil
+}
+
+func (a naive) int64(ctx context.Context, n int, env *Env) (int64, error) {
+	if n < 2 {
+		return int64(n), nil
+	}
+	if err := env.Work(ctx); err != nil {
+		return 0, err
+	}
+	res1, err := a.int64(ctx, n-1, env)
+	if err != nil {
+		return 0, err
+	}
+	res2, err := a.int64(ctx, n-2, env)
+	if err != nil {
+		return 0, err
+	}
+	sum, ok := addInt64(res1, res2)
+	if !ok {
+		return 0, overflowError(n)
+	}
+	return sum, nil
+}
+
+type memoized struct{}
+
+func (memoized) Name() string { return "memoized" }
+
+// Compute reads sub-results from the cache where possible and stores every
+// value it computes. Values are always cached as *big.Int; with machine ints
+// the additions are done in int64 arithmetic instead.
+//
+// Earlier versions spawned two goroutines per recursive call, which exploded to
+// thousands of goroutines for modest n. Concurrency now comes from running
+// several of these calls side by side on a bounded worker pool instead.
+func (a memoized) Compute(ctx context.Context, n int, env *Env) (*big.Int, error) {
+	// Don't start any more work once the run has been cancelled.
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(err)
+	}
+	if n < 2 {
+		return big.NewInt(int64(n)), nil
+	}
+
+	// Check cache. The Calculator has normally just looked n up already, so
+	// this re-check is left out of the hit/miss counts.
+	if val, ok := env.cache.Load(n); ok {
+		return val, nil
+	}
+	// Fill the cache in steps on the way to a large n, so that the
+	// recursion is never more than memoStride calls deep.
+	for m := memoStride; m < n-1; m += memoStride {
+		if _, err := a.sub(ctx, m, env); err != nil {
+			return nil, err
+		}
+	}
+	return a.compute(ctx, n, env)
+}
+
+// memoStride bounds the depth of Memoized's recursion.
+const memoStride = 1 << 12
+
+// sub returns F(n) for a sub-problem of the value being computed. Sub-problems
+// go through Env.Once, so that when several workers need the same uncached n
+// one of them computes it while the others wait, rather than all of them
+// doing the same work. (The top-level n is deduplicated by the Calculator.)
+func (a memoized) sub(ctx context.Context, n int, env *Env) (v *big.Int, err error) {
+	if n < 2 {
+		return big.NewInt(int64(n)), nil
+	}
+	ctx, span := startSpan(ctx, "fib.sub", n)
+	defer func() { endSpan(span, err) }()
+	if val, ok := env.Load(n); ok {
+		span.SetAttributes(attrCached.Bool(true))
+		return val, nil
+	}
+	return env.Once(ctx, n, func() (*big.Int, error) {
+		// Another goroutine may have finished n between our cache miss and
+		// winning the right to compute it.
+		if val, ok := env.cache.Load(n); ok {
+			return val, nil
+		}
+		return a.compute(ctx, n, env)
+	})
+}
+
+// compute does the work for an n that is known to be uncached.
+func (a memoized) compute(ctx context.Context, n int, env *Env) (*big.Int, error) {
+	// Simulate work
+	if err := env.Work(ctx); err != nil {
+		return nil, err
+	}
+
+	// Computing n-1 first leaves n-2 in the cache, so the second call is a hit.
+	res1, err := a.sub(ctx, n-1, env)
+	if err != nil {
+		return nil, err
+	}
+	res2, err := a.sub(ctx, n-2, env)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *big.Int
+	if env.MachineInts() {
+		sum, ok := addInt64(res1.Int64(), res2.Int64())
+		if !ok || !res1.IsInt64() || !res2.IsInt64() {
+			return nil, overflowError(n)
+		}
+		result = big.NewInt(sum)
+	} else {
+		result = new(big.Int).Add(res1, res2)
+	}
+	env.Store(n, result) // Store in cache
+	return result, nil
+}
+
+type iterative struct{}
+
+func (iterative) Name() string { return "iterative" }
+
+func (iterative) Compute(ctx context.Context, n int, env *Env) (*big.Int, error) {
+	if env.MachineInts() {
+		var a, b int64 = 0, 1
+		for i := 0; i < n; i++ {
+			if err := env.Work(ctx); err != nil {
+				return nil, err
+			}
+			next, ok := addInt64(a, b)
+			// next is F(i+2), which only matters if it is still to become a.
+			if !ok && i+2 <= n {
+				return nil, overflowError(i + 2)
+			}
+			a, b = b, next
+		}
+		return big.NewInt(a), nil
+	}
+
+	a, b := big.NewInt(0), big.NewInt(1)
+	for i := 0; i < n; i++ {
+		if err := env.Work(ctx); err != nil {
+			return nil, err
+		}
+		a.Add(a, b)
+		a, b = b, a
+	}
+	return a, nil
+}
+
+type fastDoubling struct{}
+
+// fastDoublingEven returns F(2k) = F(k)(2F(k+1) - F(k)) from a = F(k) and
+// b = F(k+1), reporting whether it fits in an int64.
+func fastDoublingEven(a, b int64) (int64, bool) {
+	t, ok := addInt64(b, b-a) // b >= a, so b-a can't overflow
+	if !ok {
+		return 0, false
+	}
+	return mulInt64(a, t)
+}
+
+func (fastDoubling) Name() string { return "doubling" }
+
+func (fastDoubling) Compute(ctx context.Context, n int, env *Env) (*big.Int, error) {
+	// Walk the bits of n from the most significant down, keeping
+	// a = F(k) and b = F(k+1) for the prefix k of n seen so far.
+	top := 0
+	for m := n; m > 0; m >>= 1 {
+		top++
+	}
+
+	if env.MachineInts() {
+		var a, b int64 = 0, 1
+		for i := top - 1; i >= 0; i-- {
+			if err := env.Work(ctx); err != nil {
+				return nil, err
+			}
+			c, okC := fastDoublingEven(a, b) // F(2k)
+			aa, okAA := mulInt64(a, a)
+			bb, okBB := mulInt64(b, b)
+			d, okD := addInt64(aa, bb) // F(2k+1)
+			okD = okD && okAA && okBB
+			// On the last step only the new a, F(n), has to fit.
+			last := i == 0
+			if n>>i&1 == 0 {
+				if !okC || !okD && !last {
+					return nil, overflowError(n)
+				}
+				a, b = c, d
+			} else {
+				cd, okCD := addInt64(c, d)
+				if !okD || !(okC && okCD) && !last {
+					return nil, overflowError(n)
+				}
+				a, b = d, cd
+			}
+		}
+		return big.NewInt(a), nil
+	}
+
+	a, b := big.NewInt(0), big.NewInt(1)
+	c, d, t := new(big.Int), new(big.Int), new(big.Int)
+	for i := top - 1; i >= 0; i-- {
+		if err := env.Work(ctx); err != nil {
+			return nil, err
+		}
+		t.Lsh(b, 1).Sub(t, a)
+		c.Mul(a, t) // F(2k)
+		d.Mul(a, a)
+		t.Mul(b, b)
+		d.Add(d, t) // F(2k+1)
+		if n>>i&1 == 0 {
+			a.Set(c)
+			b.Set(d)
+		} else {
+			a.Set(d)
+			b.Add(c, d)
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,45 @@
+package fib
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestOnceLeaderCancelled checks that a caller waiting on a computation of
+// F(n) doesn't fail because the caller running it was cancelled.
+func TestOnceLeaderCancelled(t *testing.T) {
+	c := New(Config{Work: time.Millisecond})
+	ctx, cancel := context.WithCancel(t.Context())
+	leader := make(chan struct{})
+	go func() {
+		c.Fibonacci(ctx, 60)
+		close(leader)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	v, err := c.Fibonacci(t.Context(), 60)
+	if err != nil || v.Cmp(reference(60)) != 0 {
+		t.Errorf("F(60) = %v, %v while another caller was cancelled; want %v", v, err, reference(60))
+	}
+	<-leader
+}
+
+// TestOnceWaiterTimeout checks that a caller giving up on a computation it
+// was waiting for fails with ErrTimeout, like one running it would.
+func TestOnceWaiterTimeout(t *testing.T) {
+	c := New(Config{Work: time.Millisecond})
+	leader := make(chan struct{})
+	go func() {
+		c.Fibonacci(t.Context(), 60)
+		close(leader)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Fibonacci(ctx, 60); !errors.Is(err, ErrTimeout) {
+		t.Errorf("F(60) with a deadline while another caller computes it: got error %v, want ErrTimeout", err)
+	}
+	<-leader
+}
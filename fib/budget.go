@@ -0,0 +1,41 @@
+package fib
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// heapCheckInterval is how often a run with Config.MaxHeap checks the size
+// of the heap.
+const heapCheckInterval = 10 * time.Millisecond
+
+// watchHeap cancels the run of ctx through cancel, with an error matching
+// ErrMemoryBudget, as soon as the heap grows past Config.MaxHeap. The
+// returned function stops watching.
+func (c *Calculator) watchHeap(ctx context.Context, cancel context.CancelCauseFunc) (stop func()) {
+	quit, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		t := time.NewTicker(heapCheckInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if heap := heapBytes(); heap > c.cfg.MaxHeap {
+					c.log.WarnContext(ctx, "memory budget exceeded, cancelling the run", "heap", heap, "max_heap", c.cfg.MaxHeap)
+					cancel(fmt.Errorf("%w: heap reached %s of %s allowed", ErrMemoryBudget, formatBytes(heap), formatBytes(c.cfg.MaxHeap)))
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-done
+	}
+}
@@ -0,0 +1,106 @@
+package fib
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+)
+
+// Cache memoizes computed Fibonacci numbers by index. Implementations must be
+// safe for concurrent use. Values handed to Store, and returned by Load, are
+// shared between goroutines and must not be modified.
+type Cache interface {
+	// Load returns the cached value of F(n), if present.
+	Load(n int) (*big.Int, bool)
+	// Store records v as the value of F(n).
+	Store(n int, v *big.Int)
+	// Len reports the number of cached entries.
+	Len() int
+	// Reset removes every entry.
+	Reset()
+}
+
+// CacheStats describes how a cache has been used.
+type CacheStats struct {
+	// Hits and Misses count lookups that did and did not find a value.
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// Stores counts values written to the cache.
+	Stores uint64 `json:"stores"`
+	// Shared counts lookups that missed but, instead of recomputing, waited
+	// for another goroutine already computing the same value.
+	Shared uint64 `json:"shared"`
+	// Evictions counts entries dropped to make room for new ones.
+	Evictions uint64 `json:"evictions"`
+	// Size is the number of entries currently cached.
+	Size int `json:"size"`
+}
+
+// HitRate returns the fraction of lookups that were hits, or 0 if there were
+// none.
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// String formats s as a one-line cache-efficiency summary.
+func (s CacheStats) String() string {
+	return fmt.Sprintf("%d hits, %d misses (%.1f%% hit rate), %d shared, %d stores, %d evictions, %d entries",
+		s.Hits, s.Misses, 100*s.HitRate(), s.Shared, s.Stores, s.Evictions, s.Size)
+}
+
+// statser is implemented by caches that keep their own statistics, such as
+// LRUCache's eviction count.
+type statser interface {
+	Stats() CacheStats
+}
+
+// MapCache is the default Cache, an unbounded map backed by sync.Map. The
+// zero value is an empty cache ready to use.
+type MapCache struct {
+	m   sync.Map
+	len atomic.Int64
+	// reset is held by Reset, and shared by Store, so that no store can
+	// land between clearing the map and zeroing len.
+	reset sync.RWMutex
+}
+
+// NewMapCache returns an empty MapCache.
+func NewMapCache() *MapCache { return new(MapCache) }
+
+// Load implements Cache.
+func (c *MapCache) Load(n int) (*big.Int, bool) {
+	v, ok := c.m.Load(n)
+	if !ok {
+		return nil, false
+	}
+	return v.(*big.Int), true // Type assertion required for sync.Map values
+}
+
+// Store implements Cache.
+func (c *MapCache) Store(n int, v *big.Int) {
+	c.reset.RLock()
+	defer c.reset.RUnlock()
+	if _, loaded := c.m.Swap(n, v); !loaded {
+		c.len.Add(1)
+	}
+}
+
+// Len implements Cache.
+func (c *MapCache) Len() int { return int(c.len.Load()) }
+
+// Reset implements Cache.
+func (c *MapCache) Reset() {
+	c.reset.Lock()
+	defer c.reset.Unlock()
+	c.m.Clear()
+	c.len.Store(0)
+}
+
+// Range implements Ranger.
+func (c *MapCache) Range(f func(n int, v *big.Int) bool) {
+	c.m.Range(func(k, v any) bool { return f(k.(int), v.(*big.Int)) })
+}
@@ -0,0 +1,74 @@
+package fib
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// BenchmarkCache compares the cache implementations under a read-mostly
+// load from many goroutines, the access pattern of a large concurrent run.
+// Run with -cpu to vary GOMAXPROCS.
+func BenchmarkCache(b *testing.B) {
+	const keys = 1024
+	caches := []struct {
+		name string
+		new  func() Cache
+	}{
+		{"sync.Map", func() Cache { return NewMapCache() }},
+		{"sharded-8", func() Cache { return NewShardedCache(8) }},
+		{"sharded-32", func() Cache { return NewShardedCache(32) }},
+		{"sharded-128", func() Cache { return NewShardedCache(128) }},
+		{"lru", func() Cache { return NewLRUCache(keys) }},
+	}
+	for _, tc := range caches {
+		for _, goroutinesPerCPU := range []int{1, 16, 256} {
+			name := fmt.Sprintf("%s/goroutines=%dxGOMAXPROCS", tc.name, goroutinesPerCPU)
+			b.Run(name, func(b *testing.B) {
+				c := tc.new()
+				v := big.NewInt(1)
+				for n := 0; n < keys; n++ {
+					c.Store(n, v)
+				}
+				b.SetParallelism(goroutinesPerCPU)
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					n := 0
+					for pb.Next() {
+						n = (n + 7) % keys
+						if n%10 == 0 {
+							c.Store(n, v)
+						} else {
+							c.Load(n)
+						}
+					}
+				})
+			})
+		}
+	}
+}
+
+// TestMapCacheResetWhileStoring checks that Len still counts the entries
+// after a reset races with stores.
+func TestMapCacheResetWhileStoring(t *testing.T) {
+	v := big.NewInt(1)
+	for range 1000 {
+		c := NewMapCache()
+		var wg sync.WaitGroup
+		for g := range 4 {
+			wg.Go(func() {
+				for n := range 64 {
+					c.Store(g*64+n, v)
+				}
+			})
+		}
+		wg.Go(c.Reset)
+		wg.Wait()
+		entries := 0
+		c.Range(func(int, *big.Int) bool { entries++; return true })
+		if c.Len() != entries {
+			t.Fatalf("Len() = %d, but the cache holds %d entries", c.Len(), entries)
+		}
+	}
+}
@@ -0,0 +1,914 @@
+package fib
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"runtime"
+	rtrace "runtime/trace"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+// Config controls how a Calculator computes Fibonacci numbers.
+type Config struct {
+	// Workers bounds how many computations run at once, up to MaxWorkers.
+	// Zero means runtime.GOMAXPROCS(0).
+	Workers int
+
+	// Adaptive, if set, lets batch runs tune how many computations run at
+	// once, starting from Workers. Submit is not affected.
+	Adaptive *Adaptive
+
+	// Deterministic runs batch computations one at a time on the calling
+	// goroutine, in order of Priority and then n, all as worker 1, so that
+	// a run turns out the same every time: for tests and teaching. Workers,
+	// Adaptive and PauseRuns have no effect on such runs. A zero Seed
+	// means 1, and a nil Clock a virtual one starting at the Unix epoch
+	// that only moves when slept on, making simulated work instant.
+	Deterministic bool
+
+	// MachineInts makes the calculator add with int64 arithmetic instead of
+	// math/big. It is faster, but only n up to MaxMachineN can be computed:
+	// every step is checked, and a computation whose result would not fit
+	// fails with ErrOverflow, unless AutoBig is set.
+	MachineInts bool
+
+	// AutoBig makes a machine-int computation that overflows start over
+	// with math/big, instead of failing.
+	AutoBig bool
+
+	// Work is how long each step of a computation sleeps, to simulate real
+	// work. Zero means DefaultWork; a negative value disables the simulated
+	// work entirely. It is ignored if Workload is set.
+	Work time.Duration
+
+	// Workload is the simulated work performed for each step of a
+	// computation. Nil means Sleep{Constant(Work)}.
+	Workload Workload
+
+	// Seed seeds the random numbers drawn by the workload, by Chaos and for
+	// retry jitter, so that their sequence can be reproduced. Zero picks a
+	// random seed.
+	Seed uint64
+
+	// Clock is the source of time for timings and all simulated pauses.
+	// Nil means SystemClock. Timeouts are always measured in real time.
+	Clock Clock
+
+	// Timeout, if positive, bounds each computation. One that takes longer
+	// fails with ErrTimeout, which in a batch run cancels the rest of the
+	// run like any other failure.
+	Timeout time.Duration
+
+	// RunTimeout, if positive, bounds each batch run such as Calculate.
+	// When it passes, outstanding work is cancelled and the results so far
+	// are returned along with an error matching ErrTimeout.
+	RunTimeout time.Duration
+
+	// MaxHeap, if positive, is the most heap memory in bytes, counted over
+	// the whole process, that a batch run may grow to. Past it, the run is
+	// cancelled and returns the results so far with an error matching
+	// ErrMemoryBudget, instead of exhausting the host's memory.
+	MaxHeap uint64
+
+	// MaxGoroutines, if positive, caps the goroutines c runs at once for
+	// the workers of its batch runs and for Submit. A run that would take
+	// it past the cap fails straight away, every result carrying an error
+	// matching ErrGoroutineLimit, rather than leaving the scheduler to
+	// thrash; so does a Submit, and SetWorkers refuses to grow past it.
+	MaxGoroutines int
+
+	// StallTimeout, if positive, is how long a batch run may go without
+	// any computation finishing, while it isn't paused, before it is taken
+	// to be hung. The run is then aborted with a *StallError, which matches
+	// ErrStalled and holds the stacks of every goroutine at that moment.
+	StallTimeout time.Duration
+
+	// RateLimit, if positive, caps how many computations start per
+	// second, as if each called an external service with a quota. It is
+	// paced by Clock, and computations waiting for their turn hold their
+	// worker.
+	RateLimit float64
+
+	// RateBurst is how many computations may start back to back before
+	// RateLimit applies, after a quiet spell. Zero means 1.
+	RateBurst int
+
+	// Retry is how failed computations are retried. The zero value never
+	// retries.
+	Retry RetryPolicy
+
+	// Chaos injects failures, panics and latency spikes into the simulated
+	// work. The zero value injects nothing.
+	Chaos Chaos
+
+	// Algorithm is the strategy used for each computation. Nil means
+	// Memoized.
+	Algorithm Algorithm
+
+	// Sequence is what the calculator computes. Nil means
+	// FibonacciSequence, computed with Algorithm; any other sequence is
+	// computed with its own Compute, Algorithm and MachineInts being
+	// ignored. A cache holds the terms of one sequence only, so one
+	// shared or persisted across calculators must not be used with
+	// different sequences.
+	Sequence Sequence
+
+	// Cache memoizes computed values. Nil means a new MapCache. Sharing one
+	// Cache between calculators lets them reuse each other's results.
+	Cache Cache
+
+	// Priority, if set, orders the computations of a batch run: while
+	// every worker is busy, the waiting n with the highest Priority(n)
+	// starts next. Nil means ascending n, as with SmallestFirst, so that
+	// each computation finds its predecessors cached.
+	Priority func(n int) int
+
+	// OnResult, if set, is called with every Result the calculator
+	// produces, from the goroutine that produced it. It must be safe for
+	// concurrent use and should return quickly.
+	OnResult func(Result)
+
+	// OnProgress, if set, is called by batch runs after each n completes,
+	// with the number of results so far and the total the run will
+	// produce. Calls for one run are made one at a time, from the
+	// goroutine that started it, so the hook may drive a progress bar.
+	OnProgress func(done, total int)
+
+	// ResultBuffer is how many finished results a batch run holds for its
+	// consumer. Once the buffer is full, workers wait for the consumer to
+	// catch up, or, with the DropWhenFull policy, discard their results,
+	// so a slow consumer of ComputeStream or Results bounds memory use
+	// rather than letting the workers race ahead. Zero means Workers.
+	ResultBuffer int
+
+	// BufferPolicy says what happens to a streamed result when the buffer
+	// is full. The zero value is BlockWhenFull.
+	BufferPolicy BufferPolicy
+
+	// Scheduler is how batch runs hand their computations to the workers.
+	// The zero value is SharedQueue.
+	Scheduler Scheduler
+
+	// Logger receives the calculator's diagnostics: invalid input at
+	// slog.LevelWarn, the start and end of every batch run at
+	// slog.LevelDebug and each computation at LevelTrace. Nil discards
+	// them.
+	Logger *slog.Logger
+}
+
+// SmallestFirst and LargestFirst are Config.Priority orders.
+var (
+	SmallestFirst = func(n int) int { return -n }
+	LargestFirst  = func(n int) int { return n }
+)
+
+// BufferPolicy is what a streaming run does with a result that does not fit
+// in its consumer's buffer.
+type BufferPolicy int
+
+const (
+	// BlockWhenFull makes the worker wait until the consumer has room.
+	BlockWhenFull BufferPolicy = iota
+	// DropWhenFull discards the result, counting it in
+	// Calculator.Dropped. The computed value is still cached.
+	DropWhenFull
+)
+
+// MaxWorkers is the most workers a Calculator may have.
+const MaxWorkers = 1 << 16
+
+// Calculator computes Fibonacci numbers according to its Config.
+type Calculator struct {
+	cfg    Config
+	env    *Env
+	bigEnv *Env // for AutoBig; shares env's cache and counts
+	log    *slog.Logger
+	limit  *limiter // nil without a RateLimit
+
+	queued atomic.Int64 // tasks submitted to a pool but not yet started
+	// goroutines counts the workers and Submit goroutines running.
+	goroutines goroutineGuard
+	active     atomic.Int64  // computations in progress
+	dropped    atomic.Uint64 // streamed results discarded by DropWhenFull
+	idle       chan int      // IDs of the workers free to run a Submit
+
+	mu       sync.Mutex
+	workers  int                        // batch-run workers, after SetWorkers
+	pools    map[*pool.Pool]trackedPool // pools of the batch runs in progress
+	stealing map[*pool.Stealing]bool    // work-stealing pools of the runs in progress
+	retired  pool.Stats                 // task counts of the pools of finished runs
+	paused   bool                       // whether batch runs are paused
+}
+
+// trackedPool describes the pool of a batch run in progress.
+type trackedPool struct {
+	ctx context.Context // done once the run is over
+	// adaptive pools are sized by their tuner, not SetWorkers.
+	adaptive bool
+}
+
+// New returns a Calculator using cfg.
+func New(cfg Config) *Calculator {
+	if cfg.Workers == 0 {
+		cfg.Workers = runtime.GOMAXPROCS(0)
+	}
+	if cfg.Algorithm == nil {
+		cfg.Algorithm = Memoized
+	}
+	if cfg.Sequence == nil {
+		cfg.Sequence = FibonacciSequence
+	}
+	if cfg.Sequence != FibonacciSequence {
+		cfg.Algorithm = sequenceAlgorithm{cfg.Sequence}
+		cfg.MachineInts, cfg.AutoBig = false, false
+	}
+	if cfg.Cache == nil {
+		cfg.Cache = NewMapCache()
+	}
+	if cfg.Work == 0 {
+		cfg.Work = DefaultWork
+	}
+	if cfg.Deterministic && cfg.Clock == nil {
+		cfg.Clock = &virtualClock{now: time.Unix(0, 0)}
+	}
+	if cfg.Deterministic && cfg.Seed == 0 {
+		cfg.Seed = 1
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+	if cfg.Workload == nil {
+		cfg.Workload = Sleep{Constant(cfg.Work)}
+		if cfg.Work < 0 {
+			cfg.Workload = noWork{}
+		}
+	}
+	if cfg.ResultBuffer == 0 {
+		cfg.ResultBuffer = max(cfg.Workers, 1)
+	}
+	log := cfg.Logger
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+	env := &Env{
+		cache:       cfg.Cache,
+		machineInts: cfg.MachineInts,
+		workload:    cfg.Workload,
+		rng:         newRand(cfg.Seed),
+		clock:       cfg.Clock,
+		chaos:       cfg.Chaos,
+		counts:      new(envCounts),
+	}
+	c := &Calculator{
+		cfg:   cfg,
+		env:   env,
+		log:   log,
+		limit: newLimiter(cfg.Clock, cfg.RateLimit, cfg.RateBurst),
+		idle:  make(chan int, min(max(cfg.Workers, 0), MaxWorkers)),
+
+		workers:  cfg.Workers,
+		pools:    make(map[*pool.Pool]trackedPool),
+		stealing: make(map[*pool.Stealing]bool),
+	}
+	c.goroutines.max = int64(cfg.MaxGoroutines)
+	for id := 1; id <= cap(c.idle); id++ {
+		c.idle <- id
+	}
+	if cfg.MachineInts && cfg.AutoBig {
+		c.bigEnv = &Env{
+			cache:    env.cache,
+			workload: env.workload,
+			rng:      env.rng,
+			clock:    env.clock,
+			chaos:    env.chaos,
+			counts:   env.counts,
+		}
+	}
+	return c
+}
+
+// Fibonacci returns the nth Fibonacci number. If ctx is cancelled before the
+// result is known, the computation stops and the context's error is returned.
+func (c *Calculator) Fibonacci(ctx context.Context, n int) (*big.Int, error) {
+	r := c.Compute(ctx, n)
+	return r.Value, r.Err
+}
+
+// Compute is like Fibonacci but returns the full Result, with its timing and
+// whether it came from the cache.
+func (c *Calculator) Compute(ctx context.Context, n int) Result {
+	r := c.compute(ctx, n, 0)
+	c.observe(r)
+	return r
+}
+
+// observe reports r to the OnResult hook, if there is one.
+func (c *Calculator) observe(r Result) {
+	if c.cfg.OnResult != nil {
+		c.cfg.OnResult(r)
+	}
+}
+
+// QueueDepth reports how many computations are waiting for a free worker
+// across all of c's runs and submissions in progress.
+func (c *Calculator) QueueDepth() int { return int(c.queued.Load()) }
+
+// Active reports how many computations are in progress, including any
+// waiting on the rate limit, across all of c's runs and submissions.
+func (c *Calculator) Active() int { return int(c.active.Load()) }
+
+// Workers reports how many workers c's batch runs use.
+func (c *Calculator) Workers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.workers
+}
+
+// SetWorkers changes how many workers c's batch runs use, including the
+// runs in progress, whose pools grow or shrink to match; computations
+// already running are unaffected. Adaptive runs, which tune their own
+// concurrency, and Submit keep to Config.Workers.
+func (c *Calculator) SetWorkers(n int) error {
+	if err := checkWorkers(n); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	grow := 0
+	for p, t := range c.pools {
+		if !t.adaptive {
+			grow += n - p.Workers()
+		}
+	}
+	if grow > 0 {
+		if err := c.goroutines.acquire(grow); err != nil {
+			return err
+		}
+	} else {
+		c.goroutines.release(-grow)
+	}
+	c.workers = n
+	for p, t := range c.pools {
+		if !t.adaptive {
+			p.Resize(n)
+		}
+	}
+	c.log.Info("workers resized", "workers", n, "runs", len(c.pools))
+	return nil
+}
+
+// Dropped reports how many streamed results c has discarded under the
+// DropWhenFull policy.
+func (c *Calculator) Dropped() uint64 { return c.dropped.Load() }
+
+// Algorithm returns the strategy c computes with. For a sequence other than
+// the Fibonacci numbers, it is named after the sequence.
+func (c *Calculator) Algorithm() Algorithm { return c.cfg.Algorithm }
+
+// Sequence returns the sequence c computes.
+func (c *Calculator) Sequence() Sequence { return c.cfg.Sequence }
+
+// Cache returns the cache c memoizes into.
+func (c *Calculator) Cache() Cache { return c.cfg.Cache }
+
+// CacheStats reports how c has used its cache since it was created. Hits,
+// misses, stores and shared computations are counted by c itself, so they
+// cover every lookup made by c and its algorithm whichever Cache is in use;
+// evictions are only known for caches that report them, such as LRUCache.
+func (c *Calculator) CacheStats() CacheStats {
+	var s CacheStats
+	if st, ok := c.cfg.Cache.(statser); ok {
+		s = st.Stats()
+	}
+	s.Hits = c.env.counts.hits.Load()
+	s.Misses = c.env.counts.misses.Load()
+	s.Stores = c.env.counts.stores.Load()
+	s.Shared = c.env.counts.shared.Load()
+	s.Size = c.cfg.Cache.Len()
+	return s
+}
+
+// fibonacci returns F(n) from the cache if it is there, reporting whether it
+// was, and otherwise computes it with c's algorithm and caches the outcome.
+func (c *Calculator) fibonacci(ctx context.Context, n int) (v *big.Int, cached bool, err error) {
+	defer recoverPanic(&err)
+	if err := c.checkInput(n); err != nil {
+		return nil, false, err
+	}
+	if v, ok := c.env.Load(n); ok {
+		return v, true, nil
+	}
+	v, err = c.computeIn(ctx, n, c.env)
+	if errors.Is(err, ErrOverflow) && c.bigEnv != nil {
+		c.log.DebugContext(ctx, "promoting to math/big after overflow", "n", n)
+		v, err = c.computeIn(ctx, n, c.bigEnv)
+	}
+	return v, false, err
+}
+
+// computeIn computes F(n) with c's algorithm in env and caches it.
+func (c *Calculator) computeIn(ctx context.Context, n int, env *Env) (*big.Int, error) {
+	return env.Once(ctx, n, func() (*big.Int, error) {
+		v, err := c.cfg.Algorithm.Compute(ctx, n, env)
+		if err != nil {
+			return nil, err
+		}
+		// Memoizing algorithms store what they compute themselves.
+		if _, ok := env.cache.Load(n); !ok {
+			env.Store(n, v)
+		}
+		return v, nil
+	})
+}
+
+// WarmCache fills c's cache with F(0) through F(upTo), or the same terms of
+// c's Sequence, computed iteratively without any simulated work. Warming
+// before a run leaves only scheduling and cache overhead to be measured.
+// Values already cached are overwritten, and the stores are not counted in
+// CacheStats.
+func (c *Calculator) WarmCache(ctx context.Context, upTo int) error {
+	if err := c.checkInput(upTo); err != nil {
+		return err
+	}
+	if c.cfg.Sequence != FibonacciSequence {
+		for n := 0; n <= upTo; n++ {
+			v, err := c.cfg.Sequence.Compute(ctx, n)
+			if err != nil {
+				return contextError(err)
+			}
+			c.cfg.Cache.Store(n, v)
+		}
+		return nil
+	}
+	if c.cfg.MachineInts {
+		// Values past MaxMachineN would have overflowed; leave them uncached.
+		upTo = min(upTo, MaxMachineN)
+		var a, b int64 = 0, 1
+		for n := 0; n <= upTo; n++ {
+			if err := ctx.Err(); err != nil {
+				return contextError(err)
+			}
+			c.cfg.Cache.Store(n, big.NewInt(a))
+			a, b = b, a+b
+		}
+		return nil
+	}
+	a, b := big.NewInt(0), big.NewInt(1)
+	for n := 0; n <= upTo; n++ {
+		if err := ctx.Err(); err != nil {
+			return contextError(err)
+		}
+		c.cfg.Cache.Store(n, a)
+		a, b = b, new(big.Int).Add(a, b)
+	}
+	return nil
+}
+
+// compute calculates F(n) on the given worker (0 if not on a pool), once
+// the rate limit allows, retrying according to c's policy, and wraps the
+// outcome, with timing and cache information, in a Result. The value is
+// copied so callers may modify it without corrupting the cache.
+func (c *Calculator) compute(ctx context.Context, n, worker int) Result {
+	c.active.Add(1)
+	defer c.active.Add(-1)
+	ctx, task := startTask(ctx, "fib.Compute", "n", n)
+	defer task.End()
+	var err error
+	rtrace.WithRegion(ctx, "fib.RateLimit", func() { err = c.limit.wait(ctx) })
+	if err != nil {
+		return Result{N: n, Worker: worker, Err: contextError(err)}
+	}
+	ctx, span := startSpan(ctx, "fib.Compute", n)
+	start := c.cfg.Clock.Now()
+	region := rtrace.StartRegion(ctx, regionName(n))
+	var (
+		v       *big.Int
+		cached  bool
+		attempt int
+	)
+	for attempt = 1; ; attempt++ {
+		v, cached, err = c.attempt(ctx, n)
+		if err == nil || attempt >= c.cfg.Retry.MaxAttempts || !retryable(err) || ctx.Err() != nil {
+			break
+		}
+		c.log.DebugContext(ctx, "retrying computation", "n", n, "attempt", attempt, "error", err)
+		var slept error
+		rtrace.WithRegion(ctx, "fib.Backoff", func() {
+			slept = c.cfg.Clock.Sleep(ctx, c.cfg.Retry.delay(attempt, c.env.rng))
+		})
+		if slept != nil {
+			break
+		}
+	}
+	region.End()
+	r := Result{N: n, Duration: c.cfg.Clock.Now().Sub(start), Cached: cached, Worker: worker, Attempts: attempt, Err: err}
+	if err == nil {
+		r.Value = new(big.Int).Set(v)
+	}
+	span.SetAttributes(attrCached.Bool(cached), attrWorker.Int(worker), attrAttempts.Int(attempt))
+	endSpan(span, err)
+	if pe := (*PanicError)(nil); errors.As(err, &pe) {
+		c.log.ErrorContext(ctx, "computation panicked", "n", n, "panic", pe.Value, "stack", string(pe.Stack))
+	}
+	c.log.Log(ctx, LevelTrace, "computed", "n", n, "cached", cached, "worker", worker, "attempts", attempt, "duration", r.Duration, "error", err)
+	return r
+}
+
+// attempt makes a single attempt at F(n), within c's per-computation
+// timeout.
+func (c *Calculator) attempt(ctx context.Context, n int) (*big.Int, bool, error) {
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+	return c.fibonacci(ctx, n)
+}
+
+// Calculate calculates the Fibonacci numbers 0 through maxN on a pool of
+// c's workers. Values already in c's cache are reused; call Cache().Reset()
+// first for a cold run. Each n is queued as one task, so at most Workers
+// computations run at any moment. It returns the results indexed by n, so
+// results[n].N == n, and the total time taken.
+//
+// A computation that panics fails with a *PanicError instead of crashing the
+// program. The first computation to fail cancels all outstanding work, and
+// its error is returned as an *Error naming the offending n. Cancelling ctx,
+// or letting its deadline pass, likewise stops the workers and returns the
+// context's error, as does exceeding Config.RunTimeout. Either way every n
+// still has a Result; those that were aborted carry the cancellation as their
+// Err.
+func (c *Calculator) Calculate(ctx context.Context, maxN int) ([]Result, time.Duration, error) {
+	if err := c.checkRange(maxN); err != nil {
+		return nil, 0, err
+	}
+	results := make([]Result, maxN+1)
+	elapsed, err := c.calculate(ctx, maxN, func(r Result) { results[r.N] = r }, runHooks{})
+	return results, elapsed, err
+}
+
+// CalculateOrdered is like Calculate, but instead of collecting the results
+// it passes each one to fn as soon as it and every result for a smaller n
+// are available, so fn sees them in ascending order of n while later ones
+// are still being computed. fn is called from the calling goroutine.
+func (c *Calculator) CalculateOrdered(ctx context.Context, maxN int, fn func(Result)) (time.Duration, error) {
+	if err := c.checkRange(maxN); err != nil {
+		return 0, err
+	}
+	next := 0
+	pending := make(map[int]Result)
+	return c.calculate(ctx, maxN, func(r Result) {
+		pending[r.N] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				return
+			}
+			delete(pending, next)
+			fn(r)
+			next++
+		}
+	}, runHooks{})
+}
+
+// PauseRuns stops the workers of c's batch runs, those in progress and any
+// started until ResumeRuns, from starting any more computations, like
+// Job.Pause. The queued work and the cache are kept as they are. Submit and
+// Compute are unaffected.
+func (c *Calculator) PauseRuns() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = true
+	for p, t := range c.pools {
+		p.Pause()
+		// A run that is over has to drain.
+		if t.ctx.Err() != nil {
+			p.Resume()
+		}
+	}
+	c.log.Info("batch runs paused", "runs", len(c.pools))
+}
+
+// ResumeRuns undoes PauseRuns, and Job.Pause of the jobs of c.
+func (c *Calculator) ResumeRuns() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = false
+	for p := range c.pools {
+		p.Resume()
+	}
+	c.log.Info("batch runs resumed", "runs", len(c.pools))
+}
+
+// RunsPaused reports whether c's batch runs are paused by PauseRuns.
+func (c *Calculator) RunsPaused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// track registers p as the pool of the batch run ending with ctx, for
+// SetWorkers to resize unless it is adaptive and for PauseRuns to pause,
+// until the returned function is called. The pool's workers are counted
+// against MaxGoroutines until then.
+func (c *Calculator) track(ctx context.Context, p *pool.Pool, adaptive bool) (untrack func()) {
+	c.mu.Lock()
+	c.pools[p] = trackedPool{ctx: ctx, adaptive: adaptive}
+	if !adaptive && p.Workers() != c.workers {
+		// SetWorkers was called since p was made.
+		if d := c.workers - p.Workers(); d > 0 {
+			c.goroutines.force(d)
+		} else {
+			c.goroutines.release(-d)
+		}
+		p.Resize(c.workers)
+	}
+	if c.paused {
+		p.Pause()
+	}
+	c.mu.Unlock()
+	return func() {
+		s := p.Stats()
+		c.mu.Lock()
+		c.goroutines.release(p.Workers())
+		delete(c.pools, p)
+		c.retire(s)
+		c.mu.Unlock()
+	}
+}
+
+// trackStealing registers s as the pool of a work-stealing batch run, for
+// PoolStats, until the returned function is called. The pool's workers are
+// counted against MaxGoroutines until then.
+func (c *Calculator) trackStealing(s *pool.Stealing) (untrack func()) {
+	c.mu.Lock()
+	c.stealing[s] = true
+	c.mu.Unlock()
+	return func() {
+		st := s.Stats()
+		c.mu.Lock()
+		c.goroutines.release(s.Workers())
+		delete(c.stealing, s)
+		c.retire(st)
+		c.mu.Unlock()
+	}
+}
+
+// retire adds the task counts of the pool of a finished run to c's. c.mu
+// must be held.
+func (c *Calculator) retire(s pool.Stats) {
+	c.retired.Started += s.Started
+	c.retired.Completed += s.Completed
+	c.retired.Wait += s.Wait
+	c.retired.Steals += s.Steals
+}
+
+// PoolStats sums the statistics of the worker pools of c's batch runs. The
+// worker and queue counts cover the runs in progress; the task counts, wait
+// time and steals cover every run so far.
+func (c *Calculator) PoolStats() pool.Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := c.retired
+	add := func(s pool.Stats) {
+		total.Workers += s.Workers
+		total.Active += s.Active
+		total.Idle += s.Idle
+		total.Queued += s.Queued
+		total.Started += s.Started
+		total.Completed += s.Completed
+		total.Wait += s.Wait
+		total.Steals += s.Steals
+	}
+	for p := range c.pools {
+		add(p.Stats())
+	}
+	for s := range c.stealing {
+		add(s.Stats())
+	}
+	return total
+}
+
+// checkRange validates the arguments shared by the batch methods.
+func (c *Calculator) checkRange(maxN int) error {
+	if err := checkWorkers(c.cfg.Workers); err != nil {
+		return err
+	}
+	if a := c.cfg.Adaptive; a != nil && (a.Min < 0 || a.Min > MaxWorkers || a.Max < 0 || a.Max > MaxWorkers) {
+		return fmt.Errorf("fib: adaptive concurrency must be between 0 and %d, got min %d and max %d", MaxWorkers, a.Min, a.Max)
+	}
+	if c.cfg.ResultBuffer < 0 {
+		return fmt.Errorf("fib: result buffer must not be negative, got %d", c.cfg.ResultBuffer)
+	}
+	if !slices.Contains(Schedulers(), c.cfg.Scheduler) {
+		return fmt.Errorf("fib: unknown scheduler %d", int(c.cfg.Scheduler))
+	}
+	return c.checkInput(maxN)
+}
+
+// checkWorkers rejects worker counts a pool can't have.
+func checkWorkers(n int) error {
+	if n < 1 || n > MaxWorkers {
+		return fmt.Errorf("fib: workers must be between 1 and %d, got %d", MaxWorkers, n)
+	}
+	return nil
+}
+
+// bufferSize is the capacity of the result channel of a run up to maxN:
+// Config.ResultBuffer, though no more than the run has results.
+func (c *Calculator) bufferSize(maxN int) int {
+	return min(c.cfg.ResultBuffer, maxN+1)
+}
+
+// runHooks customize a batch run.
+type runHooks struct {
+	// onPool, if set, is passed the pool before any computation is queued.
+	onPool func(*pool.Pool)
+	// skip holds the n whose results are already known; they are neither
+	// computed nor emitted.
+	skip map[int]bool
+}
+
+// calculate runs the computations for 0 through maxN on a worker pool, or
+// inline if Config.Deterministic is set, handing each Result to emit, in
+// completion order, from the calling goroutine. It returns the total time
+// taken and the run's error as described for Calculate.
+func (c *Calculator) calculate(ctx context.Context, maxN int, emit func(Result), hooks runHooks) (elapsed time.Duration, err error) {
+	start := c.cfg.Clock.Now()
+	if c.cfg.RunTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.RunTimeout)
+		defer cancel()
+	}
+	workers := c.Workers()
+	ctx, task := startTask(ctx, "fib.Calculate", "max_n", maxN)
+	defer task.End()
+	ctx, span := tracer.Start(ctx, "fib.Calculate", trace.WithAttributes(
+		attrMaxN.Int(maxN),
+		attrWorkers.Int(workers),
+		attrAlgorithm.String(c.cfg.Algorithm.Name()),
+	))
+	defer func() { endSpan(span, err) }()
+	c.log.DebugContext(ctx, "calculation started", "max_n", maxN, "workers", workers, "algorithm", c.cfg.Algorithm.Name())
+	defer func() {
+		c.log.DebugContext(ctx, "calculation finished", "max_n", maxN, "elapsed", elapsed, "error", err)
+	}()
+
+	// runCtx is cancelled, with the failure as its cause, as soon as any
+	// computation fails or the heap outgrows MaxHeap.
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if c.cfg.MaxHeap > 0 {
+		defer c.watchHeap(runCtx, cancel)()
+	}
+
+	done, total := len(hooks.skip), maxN+1
+	deliver := func(res Result) {
+		emit(res)
+		done++
+		if c.cfg.OnProgress != nil {
+			c.cfg.OnProgress(done, total)
+		}
+	}
+	if c.cfg.Deterministic {
+		c.runInline(runCtx, cancel, maxN, hooks, deliver)
+	} else if err := c.runOnPool(ctx, runCtx, cancel, workers, maxN, hooks, deliver); err != nil {
+		return c.cfg.Clock.Now().Sub(start), err
+	}
+
+	elapsed = c.cfg.Clock.Now().Sub(start)
+	if err := ctx.Err(); err != nil {
+		return elapsed, contextError(err)
+	}
+	if runCtx.Err() != nil {
+		return elapsed, context.Cause(runCtx)
+	}
+	return elapsed, nil
+}
+
+// runOnPool runs the computations of calculate on a pool of workers,
+// passing their results to deliver as they complete. It fails only if the
+// pool's goroutines would exceed Config.MaxGoroutines, after delivering a
+// failed result for each n.
+func (c *Calculator) runOnPool(ctx, runCtx context.Context, cancel context.CancelCauseFunc, workers, maxN int, hooks runHooks, deliver func(Result)) error {
+	// Workers block on a full channel until deliver catches up.
+	resultsChan := make(chan Result, c.bufferSize(maxN))
+
+	var tune *tuner
+	if c.cfg.Adaptive != nil {
+		// Start enough workers for the tuner to admit as many computations
+		// as it could ever want; it holds back the surplus.
+		tune = newTuner(*c.cfg.Adaptive, c.cfg.Workers)
+		workers = tune.max
+		defer tune.start(ctx)()
+	}
+
+	if err := c.goroutines.acquire(workers); err != nil {
+		for n := 0; n <= maxN; n++ {
+			if !hooks.skip[n] {
+				deliver(Result{N: n, Err: err})
+			}
+		}
+		return err
+	}
+	c.queued.Add(int64(maxN + 1 - len(hooks.skip)))
+	run := func(n, worker int) {
+		tune.acquire()
+		c.queued.Add(-1)
+		r := c.compute(runCtx, n, worker)
+		tune.release(r.Duration)
+		c.observe(r)
+		if r.Err != nil && runCtx.Err() == nil {
+			cancel(&Error{N: n, Err: r.Err})
+		}
+		resultsChan <- r
+	}
+	var wait func()
+	if c.cfg.Scheduler == WorkStealing {
+		wait = c.startStealing(runCtx, cancel, workers, maxN, hooks, run)
+	} else {
+		wait = c.startShared(runCtx, cancel, workers, maxN, hooks, run)
+	}
+
+	// Close the channel once the queue has drained and all workers have
+	// exited, to signal that no more results will be sent.
+	go func() {
+		wait()
+		close(resultsChan)
+	}()
+	for res := range resultsChan {
+		deliver(res)
+	}
+	return nil
+}
+
+// startShared queues run for each n of a batch run on a new shared-queue
+// pool of workers, returning a function that waits for them all to finish.
+func (c *Calculator) startShared(runCtx context.Context, cancel context.CancelCauseFunc, workers, maxN int, hooks runHooks, run func(n, worker int)) (wait func()) {
+	p := pool.New(workers)
+	untrack := c.track(runCtx, p, c.cfg.Adaptive != nil)
+	stopWatching := func() {}
+	if c.cfg.StallTimeout > 0 {
+		stopWatching = c.watchStalls(runCtx, cancel, p.Stats, p.Paused, maxN+1-len(hooks.skip))
+	}
+	// A paused pool never drains; let the remaining tasks run, and fail
+	// fast, once the run is over.
+	stopResuming := context.AfterFunc(runCtx, p.Resume)
+	if hooks.onPool != nil {
+		hooks.onPool(p)
+	}
+	for n := 0; n <= maxN; n++ {
+		if hooks.skip[n] {
+			continue
+		}
+		priority := 0
+		if c.cfg.Priority != nil {
+			priority = c.cfg.Priority(n)
+		}
+		p.SubmitPriority(func(worker int) { run(n, worker) }, priority)
+	}
+	p.Close()
+	return func() {
+		p.Wait()
+		stopResuming()
+		stopWatching()
+		untrack()
+	}
+}
+
+// runInline runs the computations of calculate one after another on the
+// calling goroutine, for Config.Deterministic: in descending order of
+// Config.Priority, and ascending order of n among equal priorities, as the
+// pool would with a single worker.
+func (c *Calculator) runInline(runCtx context.Context, cancel context.CancelCauseFunc, maxN int, hooks runHooks, deliver func(Result)) {
+	order := make([]int, 0, maxN+1-len(hooks.skip))
+	for n := 0; n <= maxN; n++ {
+		if !hooks.skip[n] {
+			order = append(order, n)
+		}
+	}
+	if c.cfg.Priority != nil {
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(c.cfg.Priority(b), c.cfg.Priority(a))
+		})
+	}
+	for _, n := range order {
+		r := c.compute(runCtx, n, 1)
+		c.observe(r)
+		if r.Err != nil && runCtx.Err() == nil {
+			cancel(&Error{N: n, Err: r.Err})
+		}
+		deliver(r)
+	}
+}
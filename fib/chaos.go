@@ -0,0 +1,54 @@
+package fib
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultSpike is the latency of a Chaos spike unless Chaos.Spike says
+// otherwise.
+const DefaultSpike = 100 * time.Millisecond
+
+// Chaos injects faults into the simulated work, so that error propagation,
+// retries and cancellation can be exercised on demand. Each probability
+// applies independently to every unit of work; the zero value injects
+// nothing.
+type Chaos struct {
+	// FailRate is the probability that a unit of work fails with
+	// ErrInjected.
+	FailRate float64
+
+	// PanicRate is the probability that a unit of work panics.
+	PanicRate float64
+
+	// SpikeRate is the probability that a unit of work takes an extra
+	// Spike to complete.
+	SpikeRate float64
+
+	// Spike is the latency added by a spike. Zero means DefaultSpike.
+	Spike time.Duration
+}
+
+// enabled reports whether ch injects anything at all.
+func (ch Chaos) enabled() bool {
+	return ch.FailRate > 0 || ch.PanicRate > 0 || ch.SpikeRate > 0
+}
+
+// inject applies ch to one unit of work done in env.
+func (ch Chaos) inject(ctx context.Context, env *Env) error {
+	rng := env.rng
+	if ch.PanicRate > 0 && rng.Float64() < ch.PanicRate {
+		panic(ErrInjected)
+	}
+	if ch.FailRate > 0 && rng.Float64() < ch.FailRate {
+		return ErrInjected
+	}
+	if ch.SpikeRate > 0 && rng.Float64() < ch.SpikeRate {
+		spike := ch.Spike
+		if spike <= 0 {
+			spike = DefaultSpike
+		}
+		return env.clock.Sleep(ctx, spike)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package fib
+
+import (
+	"fmt"
+	"math"
+)
+
+// Checked int64 arithmetic for the machine-int code paths. Each reports
+// whether the result is exact; the operands are always non-negative here.
+
+func addInt64(a, b int64) (int64, bool) {
+	s := a + b
+	return s, s >= a
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	return a * b, a <= math.MaxInt64/b
+}
+
+// overflowError reports that F(n) does not fit in an int64.
+func overflowError(n int) error {
+	return fmt.Errorf("%w: F(%d) does not fit in an int64", ErrOverflow, n)
+}
@@ -0,0 +1,145 @@
+package fib
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+// Checkpoint is the progress of a batch run: the results computed so far
+// and the contents of the cache. Saved from time to time with
+// SaveCheckpoint, it lets an interrupted run be continued with Resume
+// instead of starting over.
+type Checkpoint struct {
+	MaxN int
+	// Sequence names the sequence the run computes.
+	Sequence string
+	// Results holds the successful results so far, in no particular order.
+	Results []Result
+	// Cache holds the entries of the run's cache, if it implements Ranger.
+	Cache map[int]*big.Int
+}
+
+// Checkpoint captures the progress of a run of c up to maxN that has
+// produced results so far. Failed results are left out, so that Resume
+// computes them again.
+func (c *Calculator) Checkpoint(maxN int, results []Result) Checkpoint {
+	cp := Checkpoint{MaxN: maxN, Sequence: c.cfg.Sequence.Name(), Cache: map[int]*big.Int{}}
+	for _, r := range results {
+		if r.Err == nil && r.Value != nil {
+			cp.Results = append(cp.Results, r)
+		}
+	}
+	if r, ok := c.cfg.Cache.(Ranger); ok {
+		r.Range(func(n int, v *big.Int) bool {
+			cp.Cache[n] = v
+			return true
+		})
+	}
+	return cp
+}
+
+// Done reports how many of the run's results cp holds.
+func (cp Checkpoint) Done() int { return len(cp.Results) }
+
+// Resume continues the run saved in cp. It fills c's cache from cp, then
+// calculates the n from 0 through cp.MaxN that cp has no result for, as
+// Calculate does. The results include those from cp, with their original
+// timings; the elapsed time covers only the resumed part of the run.
+func (c *Calculator) Resume(ctx context.Context, cp Checkpoint) ([]Result, time.Duration, error) {
+	if cp.Sequence != c.cfg.Sequence.Name() {
+		return nil, 0, fmt.Errorf("fib: checkpoint is of the %s sequence, not %s", cp.Sequence, c.cfg.Sequence.Name())
+	}
+	if err := c.checkRange(cp.MaxN); err != nil {
+		return nil, 0, err
+	}
+	for n, v := range cp.Cache {
+		c.cfg.Cache.Store(n, v)
+	}
+	results := make([]Result, cp.MaxN+1)
+	skip := make(map[int]bool, len(cp.Results))
+	for _, r := range cp.Results {
+		if r.N >= 0 && r.N <= cp.MaxN {
+			results[r.N] = r
+			skip[r.N] = true
+		}
+	}
+	c.log.InfoContext(ctx, "resuming from checkpoint", "max_n", cp.MaxN, "done", len(skip))
+	elapsed, err := c.calculate(ctx, cp.MaxN, func(r Result) { results[r.N] = r }, runHooks{skip: skip})
+	return results, elapsed, err
+}
+
+// checkpointVersion is bumped whenever checkpointFile changes incompatibly.
+const checkpointVersion = 1
+
+// checkpointFile is the gob-encoded form of a Checkpoint; Result itself
+// can't be encoded because of its error.
+type checkpointFile struct {
+	Version  int
+	MaxN     int
+	Sequence string
+	Results  []checkpointResult
+	Cache    []checkpointEntry
+}
+
+type checkpointResult struct {
+	N        int
+	Value    *big.Int
+	Duration time.Duration
+	Cached   bool
+	Worker   int
+	Attempts int
+}
+
+type checkpointEntry struct {
+	N     int
+	Value *big.Int
+}
+
+// SaveCheckpoint writes cp to the file at path, replacing it atomically.
+func SaveCheckpoint(path string, cp Checkpoint) error {
+	f := checkpointFile{Version: checkpointVersion, MaxN: cp.MaxN, Sequence: cp.Sequence}
+	for _, r := range cp.Results {
+		f.Results = append(f.Results, checkpointResult{r.N, r.Value, r.Duration, r.Cached, r.Worker, r.Attempts})
+	}
+	for n, v := range cp.Cache {
+		f.Cache = append(f.Cache, checkpointEntry{n, v})
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(f); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// LoadCheckpoint reads a checkpoint written by SaveCheckpoint.
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	var f checkpointFile
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&f); err != nil {
+		return Checkpoint{}, fmt.Errorf("fib: reading checkpoint %s: %w", path, err)
+	}
+	if f.Version != checkpointVersion {
+		return Checkpoint{}, fmt.Errorf("fib: reading checkpoint %s: version %d, want %d", path, f.Version, checkpointVersion)
+	}
+	cp := Checkpoint{MaxN: f.MaxN, Sequence: f.Sequence, Cache: make(map[int]*big.Int, len(f.Cache))}
+	for _, r := range f.Results {
+		if r.Value == nil {
+			return Checkpoint{}, fmt.Errorf("fib: reading checkpoint %s: no value for n=%d", path, r.N)
+		}
+		cp.Results = append(cp.Results, Result{N: r.N, Value: r.Value, Duration: r.Duration, Cached: r.Cached, Worker: r.Worker, Attempts: r.Attempts})
+	}
+	for _, e := range f.Cache {
+		if e.Value != nil {
+			cp.Cache[e.N] = e.Value
+		}
+	}
+	return cp, nil
+}
@@ -0,0 +1,62 @@
+package fib
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for a Calculator: the timestamps behind
+// Result.Duration and the pauses of simulated work, retry backoff and chaos
+// spikes. Swapping in a fake clock, such as fibtest.Clock, makes runs
+// instant and their timings reproducible.
+type Clock interface {
+	Now() time.Time
+	// Sleep pauses for d, returning early with the context's error if ctx
+	// is done first.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// SystemClock is the real wall clock.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return contextError(ctx.Err())
+	}
+}
+
+// virtualClock is the clock of a Deterministic calculator not given one.
+// Its time only moves when it is slept on.
+type virtualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *virtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *virtualClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return contextError(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(max(d, 0))
+	return nil
+}
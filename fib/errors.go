@@ -0,0 +1,95 @@
+package fib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// Sentinel errors describing why a computation failed. Errors returned by
+// this package wrap one of them where applicable, so callers can branch on
+// the cause with errors.Is.
+var (
+	// ErrNegativeInput is returned for n < 0.
+	ErrNegativeInput = errors.New("fib: input must be a non-negative integer")
+	// ErrInputTooLarge is returned for n > MaxN.
+	ErrInputTooLarge = errors.New("fib: input too large")
+	// ErrOverflow is returned when a result does not fit in machine
+	// integers; see Config.MachineInts.
+	ErrOverflow = errors.New("fib: integer overflow")
+	// ErrCancelled is returned when the context was cancelled. The error
+	// also matches context.Canceled.
+	ErrCancelled = errors.New("fib: computation cancelled")
+	// ErrTimeout is returned when the context's deadline passed. The error
+	// also matches context.DeadlineExceeded.
+	ErrTimeout = errors.New("fib: computation timed out")
+	// ErrPanic is returned when a computation panicked. The error is a
+	// *PanicError carrying the panic value and stack.
+	ErrPanic = errors.New("fib: computation panicked")
+	// ErrInjected is the failure injected by Config.Chaos.
+	ErrInjected = errors.New("fib: injected failure")
+	// ErrMemoryBudget is returned when a batch run was stopped for using
+	// more heap than Config.MaxHeap allows.
+	ErrMemoryBudget = errors.New("fib: memory budget exceeded")
+	// ErrGoroutineLimit is returned when work would need more goroutines
+	// than Config.MaxGoroutines allows.
+	ErrGoroutineLimit = errors.New("fib: goroutine limit exceeded")
+	// ErrStalled is returned when a batch run made no progress for
+	// Config.StallTimeout. The error is a *StallError.
+	ErrStalled = errors.New("fib: run stalled")
+)
+
+// MaxN is the largest n a Calculator accepts. F(MaxN) has over 200,000
+// digits, and a batch run up to it holds a million results.
+const MaxN = 1 << 20
+
+// MaxMachineN is the largest n for which F(n) fits in an int64.
+const MaxMachineN = 92
+
+// contextError translates an error from a context into ErrCancelled or
+// ErrTimeout, keeping the original in the chain. Other errors are returned
+// unchanged.
+func contextError(err error) error {
+	switch {
+	case errors.Is(err, ErrCancelled), errors.Is(err, ErrTimeout):
+		return err
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("%w: %w", ErrCancelled, err)
+	}
+	return err
+}
+
+// Error reports that computing F(N) failed.
+type Error struct {
+	N   int
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("fib: computing F(%d): %v", e.N, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// PanicError reports a panic recovered from a computation.
+type PanicError struct {
+	Value any    // the value passed to panic
+	Stack []byte // the stack of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrPanic, e.Value)
+}
+
+func (e *PanicError) Is(target error) bool { return target == ErrPanic }
+
+// recoverPanic turns a panic in the calling function into a *PanicError
+// stored in *err. It must be called directly by a deferred statement.
+func recoverPanic(err *error) {
+	if v := recover(); v != nil {
+		*err = &PanicError{Value: v, Stack: debug.Stack()}
+	}
+}
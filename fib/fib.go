@@ -0,0 +1,58 @@
+// Package fib calculates Fibonacci numbers concurrently on a bounded worker
+// pool backed by a thread-safe memoization cache. It is the importable form of
+// the Go junk demo; see cmd/massjunk for the command-line driver.
+package fib
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+)
+
+// DefaultWork is the simulated work charged per computation step unless
+// Config.Work says otherwise.
+const DefaultWork = time.Millisecond
+
+// LevelTrace is the level, below slog.LevelDebug, at which a Calculator logs
+// every single computation.
+const LevelTrace = slog.LevelDebug - 4
+
+// simulateWorkGo performs d worth of simulated work on clock, returning
+// early with the context's error if it is cancelled first.
+func simulateWorkGo(ctx context.Context, clock Clock, d time.Duration) error {
+	return clock.Sleep(ctx, d)
+}
+
+// checkInput rejects indices the Fibonacci sequence isn't defined for, and
+// those too large to compute.
+func (c *Calculator) checkInput(n int) error {
+	if n < 0 {
+		c.log.Warn("input must be a non-negative integer", "n", n)
+		return fmt.Errorf("%w, got %d", ErrNegativeInput, n)
+	}
+	if n > MaxN {
+		c.log.Warn("input exceeds the largest supported n", "n", n, "max_n", MaxN)
+		return fmt.Errorf("%w: %d, the most is %d", ErrInputTooLarge, n, MaxN)
+	}
+	return nil
+}
+
+// Fibonacci returns the nth Fibonacci number as an arbitrary-precision
+// integer, using a fresh memoization cache. If ctx is cancelled
+// before the result is known, the computation stops and the context's error is
+// returned.
+func Fibonacci(ctx context.Context, n int) (*big.Int, error) {
+	return New(Config{}).Fibonacci(ctx, n)
+}
+
+// CalculateConcurrent calculates the Fibonacci numbers 0 through maxN on a
+// pool of numThreads workers using the default arbitrary-precision
+// arithmetic. It is shorthand for New(Config{Workers: numThreads}).Calculate.
+func CalculateConcurrent(ctx context.Context, maxN, numThreads int) ([]Result, time.Duration, error) {
+	if numThreads < 1 {
+		return nil, 0, fmt.Errorf("fib: numThreads must be at least 1, got %d", numThreads)
+	}
+	return New(Config{Workers: numThreads}).Calculate(ctx, maxN)
+}
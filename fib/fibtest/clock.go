@@ -0,0 +1,53 @@
+// Package fibtest provides helpers for testing code built on package fib.
+package fibtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// Clock is a fake fib.Clock whose time only moves when told to. Sleep
+// returns at once, advancing the clock by the time slept, so simulated work
+// costs nothing in real time but still shows up in Result.Duration.
+//
+// With Config.Workers set to 1 and a fixed Config.Seed, a calculator on a
+// Clock produces the same results and timings on every run.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+var _ fib.Clock = (*Clock)(nil)
+
+// NewClock returns a Clock reading start.
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sleep advances the clock by d, unless ctx is already done.
+func (c *Clock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d > 0 {
+		c.Advance(d)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package fib
+
+import "context"
+
+// Future is the pending outcome of a computation started with
+// Calculator.Submit.
+type Future struct {
+	n    int
+	done chan struct{}
+	r    Result // set before done is closed
+}
+
+// N reports the index the future is computing.
+func (f *Future) N() int { return f.n }
+
+// Done returns a channel that is closed once the computation has finished,
+// successfully or not.
+func (f *Future) Done() <-chan struct{} { return f.done }
+
+// Result returns the outcome of the computation, and whether it has
+// finished; until it has, the Result is the zero value.
+func (f *Future) Result() (Result, bool) {
+	select {
+	case <-f.done:
+		return f.r, true
+	default:
+		return Result{}, false
+	}
+}
+
+// Wait blocks until the computation has finished and returns its Result and
+// error. If ctx is done first, Wait gives up, leaving the computation
+// running, and returns the context's error.
+func (f *Future) Wait(ctx context.Context) (Result, error) {
+	select {
+	case <-f.done:
+		return f.r, f.r.Err
+	case <-ctx.Done():
+		return Result{N: f.n}, contextError(ctx.Err())
+	}
+}
+
+// Submit starts computing F(n) in the background and returns a Future for
+// its outcome, so callers can start many computations and join them as they
+// please rather than batching them with Calculate. Submitted computations
+// share c's workers: at most Workers of them run at once, and the rest wait
+// their turn. Cancelling ctx cancels the computation, whether it is waiting
+// or running.
+func (c *Calculator) Submit(ctx context.Context, n int) *Future {
+	f := &Future{n: n, done: make(chan struct{})}
+	if err := c.checkRange(n); err != nil {
+		f.r = Result{N: n, Err: err}
+		close(f.done)
+		return f
+	}
+	if err := c.goroutines.acquire(1); err != nil {
+		f.r = Result{N: n, Err: err}
+		close(f.done)
+		return f
+	}
+	c.queued.Add(1)
+	go func() {
+		defer close(f.done)
+		defer c.goroutines.release(1)
+		select {
+		case worker := <-c.idle:
+			c.queued.Add(-1)
+			f.r = c.compute(ctx, n, worker)
+			c.idle <- worker
+		case <-ctx.Done():
+			c.queued.Add(-1)
+			f.r = Result{N: n, Err: contextError(ctx.Err())}
+		}
+		c.observe(f.r)
+	}()
+	return f
+}
@@ -0,0 +1,110 @@
+package fib
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// fuzzDeadline bounds each input, so that slow inputs end with ErrTimeout
+// rather than being mistaken for hangs.
+const fuzzDeadline = 200 * time.Millisecond
+
+// reference returns F(n) computed independently of the package.
+func reference(n int) *big.Int {
+	a, b := big.NewInt(0), big.NewInt(1)
+	for range n {
+		a.Add(a, b)
+		a, b = b, a
+	}
+	return a
+}
+
+func FuzzFibonacci(f *testing.F) {
+	for _, n := range []int{0, 1, 2, 10, 92, 93, 500, -1, -1 << 63, 1<<63 - 1, 1 << 40} {
+		for algorithm := range len(Algorithms()) {
+			f.Add(n, uint8(algorithm), false, true)
+		}
+		f.Add(n, uint8(0), true, false)
+	}
+	f.Fuzz(func(t *testing.T, n int, algorithm uint8, machineInts, noWork bool) {
+		cfg := Config{Algorithm: Algorithms()[int(algorithm)%len(Algorithms())], MachineInts: machineInts}
+		if noWork {
+			cfg.Work = -1
+		}
+		ctx, cancel := context.WithTimeout(t.Context(), fuzzDeadline)
+		defer cancel()
+		v, err := New(cfg).Fibonacci(ctx, n)
+		switch {
+		case n < 0:
+			if !errors.Is(err, ErrNegativeInput) {
+				t.Fatalf("F(%d): got error %v, want ErrNegativeInput", n, err)
+			}
+		case err != nil:
+			if !errors.Is(err, ErrTimeout) && !errors.Is(err, ErrOverflow) && !errors.Is(err, ErrInputTooLarge) {
+				t.Fatalf("F(%d) with %s: unexpected error %v", n, cfg.Algorithm.Name(), err)
+			}
+			if errors.Is(err, ErrOverflow) && (!machineInts || n <= MaxMachineN) {
+				t.Fatalf("F(%d) with %s: unexpected overflow", n, cfg.Algorithm.Name())
+			}
+		case v == nil:
+			t.Fatalf("F(%d) with %s: no value and no error", n, cfg.Algorithm.Name())
+		case n <= 10000 && v.Cmp(reference(n)) != 0:
+			t.Fatalf("F(%d) with %s = %v, want %v", n, cfg.Algorithm.Name(), v, reference(n))
+		}
+	})
+}
+
+func FuzzRunConfig(f *testing.F) {
+	f.Add(20, 4, 0, 0, 0, 0.0, 0, 0.0, 0, 0, 0, false)
+	f.Add(20, 0, -1, -5, -3, -1.0, -2, -0.5, -1, -1, -1, false)
+	f.Add(-1, -4, 1<<40, 1<<40, 1<<40, 1e300, 1<<40, 2.0, 1<<40, 1<<40, 1<<40, true)
+	f.Add(1<<40, 1<<30, 1, 1, 1, 1e-300, 1, 0.5, 1, 1, 1, false)
+	f.Add(30, 3, 1, 0, 2, 1000.0, 3, 0.3, 3, 1, 8, true)
+	f.Add(10, 2, 0, 0, 0, math.Inf(1), 2, math.NaN(), 0, 0, 0, false)
+	f.Add(10, 2, 0, 0, 0, math.NaN(), 2, math.Inf(-1), 0, 0, 0, false)
+	f.Fuzz(func(t *testing.T, maxN, workers, resultBuffer, maxGoroutines, rateBurst int, rateLimit float64,
+		maxAttempts int, failRate float64, adaptiveMin, adaptiveMax, maxHeapMiB int, deterministic bool) {
+		cfg := Config{
+			Workers:       workers,
+			Deterministic: deterministic,
+			Work:          -1,
+			Seed:          1,
+			MaxHeap:       uint64(maxHeapMiB) << 20,
+			MaxGoroutines: maxGoroutines,
+			StallTimeout:  time.Second,
+			RateLimit:     rateLimit,
+			RateBurst:     rateBurst,
+			Retry:         RetryPolicy{MaxAttempts: maxAttempts, Backoff: time.Millisecond, Jitter: failRate},
+			Chaos:         Chaos{FailRate: failRate, PanicRate: failRate / 2, SpikeRate: failRate, Spike: time.Millisecond},
+			ResultBuffer:  resultBuffer,
+		}
+		if adaptiveMin != 0 || adaptiveMax != 0 {
+			cfg.Adaptive = &Adaptive{Min: adaptiveMin, Max: adaptiveMax, Interval: 10 * time.Millisecond}
+		}
+		ctx, cancel := context.WithTimeout(t.Context(), fuzzDeadline)
+		defer cancel()
+		c := New(cfg)
+		results, _, err := c.Calculate(ctx, maxN)
+		if err == nil && len(results) != maxN+1 {
+			t.Fatalf("Calculate(%d) returned %d results and no error", maxN, len(results))
+		}
+		for _, r := range results {
+			if err == nil && r.Err != nil {
+				t.Fatalf("F(%d) failed with %v but the run did not", r.N, r.Err)
+			}
+		}
+		if err == nil {
+			violations, err := c.SelfCheck(t.Context(), results)
+			if err != nil || len(violations) > 0 {
+				t.Fatalf("Calculate(%d) passed, but its self-check found %v, error %v", maxN, violations, err)
+			}
+		}
+		if maxN < 0 && err == nil {
+			t.Fatalf("Calculate(%d) succeeded", maxN)
+		}
+	})
+}
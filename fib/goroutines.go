@@ -0,0 +1,54 @@
+package fib
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// goroutineGuard counts the goroutines a Calculator has running against
+// Config.MaxGoroutines, and remembers the most it has had at once.
+type goroutineGuard struct {
+	max     int64 // 0 means no limit
+	n, peak atomic.Int64
+}
+
+// acquire counts k more goroutines, failing with ErrGoroutineLimit instead
+// if that would exceed the limit.
+func (g *goroutineGuard) acquire(k int) error {
+	for {
+		n := g.n.Load()
+		if g.max > 0 && n+int64(k) > g.max {
+			return fmt.Errorf("%w: %d needed with %d running, limit %d", ErrGoroutineLimit, k, n, g.max)
+		}
+		if g.n.CompareAndSwap(n, n+int64(k)) {
+			g.notePeak(n + int64(k))
+			return nil
+		}
+	}
+}
+
+// force counts k more goroutines whatever the limit, for those already
+// committed to.
+func (g *goroutineGuard) force(k int) {
+	g.notePeak(g.n.Add(int64(k)))
+}
+
+// release uncounts k goroutines.
+func (g *goroutineGuard) release(k int) { g.n.Add(-int64(k)) }
+
+func (g *goroutineGuard) notePeak(n int64) {
+	for {
+		peak := g.peak.Load()
+		if n <= peak || g.peak.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+// Goroutines reports how many goroutines c is running for the workers of its
+// batch runs and for Submit.
+func (c *Calculator) Goroutines() int { return int(c.goroutines.n.Load()) }
+
+// PeakGoroutines reports the most goroutines c has run at once for the
+// workers of its batch runs and for Submit, as counted by Goroutines.
+func (c *Calculator) PeakGoroutines() int { return int(c.goroutines.peak.Load()) }
@@ -0,0 +1,39 @@
+package fib
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ZapGaming/Mass-Junk-Code/dag"
+)
+
+// Graph returns the calculation of F(0) through F(n) as a task graph: F(0)
+// and F(1) stand alone, and every later F(i) depends on F(i-1) and F(i-2).
+// Running it with dag's Run yields the whole sequence keyed by index. The
+// chain leaves little to run in parallel; it serves as an example of the
+// structure the memoized algorithm walks implicitly.
+func Graph(n int) (*dag.Graph[int, *big.Int], error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w, got %d", ErrNegativeInput, n)
+	}
+	g := dag.New[int, *big.Int]()
+	for i := 0; i <= n; i++ {
+		var err error
+		switch i {
+		case 0, 1:
+			v := big.NewInt(int64(i))
+			err = g.Add(i, func(context.Context, map[int]*big.Int) (*big.Int, error) {
+				return v, nil
+			})
+		default:
+			err = g.Add(i, func(_ context.Context, deps map[int]*big.Int) (*big.Int, error) {
+				return new(big.Int).Add(deps[i-1], deps[i-2]), nil
+			}, i-1, i-2)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,133 @@
+package fib
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+// Job is a batch run started with Calculator.Start, which can be paused,
+// resumed and cancelled while it is in progress.
+type Job struct {
+	maxN    int
+	ctx     context.Context
+	cancel  context.CancelFunc
+	results chan Result
+	done    chan struct{}
+
+	completed atomic.Int64
+
+	mu     sync.Mutex
+	pool   *pool.Pool // nil until the run has made it
+	paused bool
+
+	// Set before done is closed.
+	elapsed time.Duration
+	err     error
+}
+
+// Start begins calculating the Fibonacci numbers 0 through maxN in the
+// background, like ComputeStream, and returns the Job, whose Results
+// channel delivers each Result as it completes. The consumer must keep
+// receiving until the channel is closed, or cancel the job.
+func (c *Calculator) Start(ctx context.Context, maxN int) *Job {
+	ctx, cancel := context.WithCancel(ctx)
+	j := &Job{
+		maxN:   maxN,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	if err := c.checkRange(maxN); err != nil {
+		j.err = err
+		j.results = make(chan Result)
+		close(j.results)
+		close(j.done)
+		cancel()
+		return j
+	}
+	j.results = make(chan Result, c.bufferSize(maxN))
+	go func() {
+		defer close(j.done)
+		defer cancel()
+		defer close(j.results)
+		j.elapsed, j.err = c.calculate(ctx, maxN, func(r Result) {
+			j.completed.Add(1)
+			select {
+			case j.results <- r:
+			case <-ctx.Done():
+			}
+		}, runHooks{onPool: j.setPool})
+	}()
+	return j
+}
+
+// setPool is called by the run with its pool, before any work is queued.
+func (j *Job) setPool(p *pool.Pool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.pool = p
+	if j.paused {
+		p.Pause()
+	}
+}
+
+// Results returns the channel on which the job's results are delivered, in
+// completion order. It is closed once the job is over.
+func (j *Job) Results() <-chan Result { return j.results }
+
+// Total reports how many results the job will produce.
+func (j *Job) Total() int { return j.maxN + 1 }
+
+// Completed reports how many results the job has produced so far.
+func (j *Job) Completed() int { return int(j.completed.Load()) }
+
+// Pause stops the job's workers from starting any more computations.
+// Those in progress finish, and everything queued waits, along with the
+// cache, for Resume. Cancelling a paused job lets it wind down.
+func (j *Job) Pause() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.paused = true
+	if j.pool != nil {
+		j.pool.Pause()
+		// Pausing after the run was cancelled would keep it from draining.
+		if j.ctx.Err() != nil {
+			j.pool.Resume()
+		}
+	}
+}
+
+// Resume undoes Pause.
+func (j *Job) Resume() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.paused = false
+	if j.pool != nil {
+		j.pool.Resume()
+	}
+}
+
+// Paused reports whether the job is paused.
+func (j *Job) Paused() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.paused
+}
+
+// Cancel stops the job. Computations still queued fail with ErrCancelled,
+// and Wait returns an error matching it.
+func (j *Job) Cancel() { j.cancel() }
+
+// Done returns a channel that is closed once the job is over.
+func (j *Job) Done() <-chan struct{} { return j.done }
+
+// Wait blocks until the job is over and returns the total time it took and
+// its error, as described for Calculate.
+func (j *Job) Wait() (time.Duration, error) {
+	<-j.done
+	return j.elapsed, j.err
+}
@@ -0,0 +1,104 @@
+package fib
+
+import (
+	"container/list"
+	"math/big"
+	"sync"
+)
+
+// LRUCache is a Cache holding at most a fixed number of entries. When it is
+// full, storing a new entry evicts the least recently used one.
+type LRUCache struct {
+	mu        sync.Mutex
+	capacity  int
+	ll        *list.List // front is most recently used
+	items     map[int]*list.Element
+	evictions uint64
+}
+
+type lruEntry struct {
+	n int
+	v *big.Int
+}
+
+// NewLRUCache returns an empty LRUCache holding at most capacity entries.
+// It panics if capacity is less than 1.
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		panic("fib: LRU cache capacity must be at least 1")
+	}
+	return &LRUCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[int]*list.Element),
+	}
+}
+
+// Load implements Cache. A hit marks the entry as most recently used.
+func (c *LRUCache) Load(n int) (*big.Int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[n]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*lruEntry).v, true
+}
+
+// Store implements Cache.
+func (c *LRUCache) Store(n int, v *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[n]; ok {
+		e.Value.(*lruEntry).v = v
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[n] = c.ll.PushFront(&lruEntry{n: n, v: v})
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).n)
+		c.evictions++
+	}
+}
+
+// Len implements Cache.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// Reset implements Cache. The eviction counter is left untouched.
+func (c *LRUCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	clear(c.items)
+}
+
+// Range implements Ranger, visiting entries from most to least recently used
+// without changing their order.
+func (c *LRUCache) Range(f func(n int, v *big.Int) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for e := c.ll.Front(); e != nil; e = e.Next() {
+		ent := e.Value.(*lruEntry)
+		if !f(ent.n, ent.v) {
+			return
+		}
+	}
+}
+
+// Capacity reports the maximum number of entries c holds.
+func (c *LRUCache) Capacity() int { return c.capacity }
+
+// Stats returns the current size and eviction count. Lookup counters are
+// left zero; they are tracked by the Calculator using the cache.
+func (c *LRUCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Size: c.ll.Len(), Evictions: c.evictions}
+}
@@ -0,0 +1,250 @@
+package fib
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+// MaxPisanoModulus is the largest modulus whose Pisano period FibMod looks
+// for. Finding it means factoring numbers near the modulus by trial
+// division, which beyond this bound takes longer than it could save.
+const MaxPisanoModulus = 1 << 40
+
+// ErrModulus reports a modulus that FibMod or PisanoPeriod cannot use.
+var ErrModulus = errors.New("fib: invalid modulus")
+
+// pisanoCache remembers the periods already found, by modulus.
+var pisanoCache sync.Map // uint64 → uint64
+
+// FibMod returns F(n) mod m for any non-negative n, however large, in
+// O(log n) steps of machine arithmetic, by fast doubling with every
+// intermediate value reduced mod m. When m is at most MaxPisanoModulus, n
+// is first reduced modulo the Pisano period of m, the period with which the
+// Fibonacci sequence repeats mod m, so that huge indices cost no more than
+// small ones.
+func FibMod(n *big.Int, m uint64) (uint64, error) {
+	if n.Sign() < 0 {
+		return 0, fmt.Errorf("%w, got %v", ErrNegativeInput, n)
+	}
+	if m == 0 {
+		return 0, fmt.Errorf("%w: modulus must be positive", ErrModulus)
+	}
+	if m == 1 {
+		return 0, nil
+	}
+	if m <= MaxPisanoModulus {
+		period, err := PisanoPeriod(m)
+		if err != nil {
+			return 0, err
+		}
+		r := new(big.Int).Rem(n, new(big.Int).SetUint64(period))
+		f, _ := fibModPair(r, m)
+		return f, nil
+	}
+	f, _ := fibModPair(n, m)
+	return f, nil
+}
+
+// fibModPair returns F(n) and F(n+1) mod m, by fast doubling over the bits
+// of n.
+func fibModPair(n *big.Int, m uint64) (uint64, uint64) {
+	a, b := uint64(0), 1%m // F(0), F(1)
+	for i := n.BitLen() - 1; i >= 0; i-- {
+		// F(2k) = F(k)(2F(k+1) - F(k)), F(2k+1) = F(k)² + F(k+1)²
+		c := mulMod(a, subMod(addMod(b, b, m), a, m), m)
+		d := addMod(mulMod(a, a, m), mulMod(b, b, m), m)
+		if n.Bit(i) == 0 {
+			a, b = c, d
+		} else {
+			a, b = d, addMod(c, d, m)
+		}
+	}
+	return a, b
+}
+
+func addMod(a, b, m uint64) uint64 {
+	s, carry := bits.Add64(a, b, 0)
+	if carry != 0 || s >= m {
+		s -= m
+	}
+	return s
+}
+
+func subMod(a, b, m uint64) uint64 {
+	if a >= b {
+		return a - b
+	}
+	return m - (b - a)
+}
+
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+}
+
+// PisanoPeriod returns the period with which the Fibonacci numbers repeat
+// modulo m, for 1 <= m <= MaxPisanoModulus. It factors m, finds the period
+// of each prime p among the divisors of p-1 or 2(p+1), where number theory
+// confines it, and combines them: the period of p^k is taken to be
+// p^(k-1) times that of p, which holds for every prime ever checked, and
+// the period of m is the least common multiple of those of its prime
+// powers.
+func PisanoPeriod(m uint64) (uint64, error) {
+	if m == 0 || m > MaxPisanoModulus {
+		return 0, fmt.Errorf("%w: Pisano periods are found for 1 <= m <= %d, got %d", ErrModulus, uint64(MaxPisanoModulus), m)
+	}
+	if p, ok := pisanoCache.Load(m); ok {
+		return p.(uint64), nil
+	}
+	period := uint64(1)
+	for _, f := range factor(m) {
+		pk := primePeriod(f.p)
+		for range f.k - 1 {
+			pk *= f.p
+		}
+		period = lcm(period, pk)
+	}
+	pisanoCache.Store(m, period)
+	return period, nil
+}
+
+// primePeriod returns the Pisano period of the prime p.
+func primePeriod(p uint64) uint64 {
+	switch p {
+	case 2:
+		return 3
+	case 5:
+		return 20
+	}
+	// The period divides p-1 when p ≡ ±1 (mod 5), and 2(p+1) otherwise.
+	d := 2 * (p + 1)
+	if p%5 == 1 || p%5 == 4 {
+		d = p - 1
+	}
+	// The periods mod p are the multiples of the least one, so strip prime
+	// factors from d for as long as what remains is still a period.
+	for _, f := range factor(d) {
+		for d%f.p == 0 && isPeriod(d/f.p, p) {
+			d /= f.p
+		}
+	}
+	return d
+}
+
+// isPeriod reports whether F(d) ≡ 0 and F(d+1) ≡ 1 (mod m).
+func isPeriod(d, m uint64) bool {
+	a, b := fibModPair(new(big.Int).SetUint64(d), m)
+	return a == 0 && b == 1%m
+}
+
+// primePower is a prime factor p occurring k times.
+type primePower struct {
+	p uint64
+	k int
+}
+
+// factor returns the prime factorization of n by trial division.
+func factor(n uint64) []primePower {
+	var fs []primePower
+	for p := uint64(2); p*p <= n; p++ {
+		if n%p != 0 {
+			continue
+		}
+		f := primePower{p: p}
+		for n%p == 0 {
+			n /= p
+			f.k++
+		}
+		fs = append(fs, f)
+	}
+	if n > 1 {
+		fs = append(fs, primePower{p: n, k: 1})
+	}
+	return fs
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func lcm(a, b uint64) uint64 { return a / gcd(a, b) * b }
+
+// MaxIndexBits bounds the size of the indices ParseIndex accepts. FibMod
+// is cheap for any of them, but building the index itself is not: powers
+// such as "1e1000^20000" would take seconds and gigabytes to expand.
+const MaxIndexBits = 1 << 16
+
+// ParseIndex parses an index that may be too large for an int: a decimal
+// integer such as "1000000000000000000", a power such as "10^18", or
+// scientific notation with an integer value such as "1e18". Indices of
+// more than MaxIndexBits bits are rejected with ErrInputTooLarge before
+// they are expanded.
+func ParseIndex(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if base, exp, ok := strings.Cut(s, "^"); ok {
+		b, err1 := ParseIndex(base)
+		e, err2 := ParseIndex(exp)
+		if err := errors.Join(err1, err2); err != nil {
+			return nil, indexError(s, err)
+		}
+		// b^e has at most e times as many bits as b, and 0 and 1 stay put.
+		if b.Cmp(big.NewInt(1)) > 0 {
+			if err := checkIndexBits(s, e, uint64(b.BitLen()), 0); err != nil {
+				return nil, err
+			}
+		}
+		return new(big.Int).Exp(b, e, nil), nil
+	}
+	if mant, exp, ok := strings.Cut(strings.ToLower(s), "e"); ok {
+		m, err1 := ParseIndex(mant)
+		e, err2 := ParseIndex(exp)
+		if err := errors.Join(err1, err2); err != nil {
+			return nil, indexError(s, err)
+		}
+		if m.Sign() == 0 {
+			return m, nil
+		}
+		// Each power of ten adds less than 4 bits to those of m.
+		if err := checkIndexBits(s, e, 4, uint64(m.BitLen())); err != nil {
+			return nil, err
+		}
+		return m.Mul(m, new(big.Int).Exp(big.NewInt(10), e, nil)), nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("fib: invalid index %q", s)
+	}
+	if n.Sign() < 0 {
+		return nil, fmt.Errorf("%w, got %v", ErrNegativeInput, n)
+	}
+	if n.BitLen() > MaxIndexBits {
+		return nil, fmt.Errorf("%w: index %q has more than %d bits", ErrInputTooLarge, s, MaxIndexBits)
+	}
+	return n, nil
+}
+
+// indexError reports an invalid index s, keeping ErrInputTooLarge from
+// one of its parts so that callers can tell it apart.
+func indexError(s string, err error) error {
+	if errors.Is(err, ErrInputTooLarge) {
+		return err
+	}
+	return fmt.Errorf("fib: invalid index %q", s)
+}
+
+// checkIndexBits rejects s, whose value has at most extra + e*perUnit bits,
+// if that could exceed MaxIndexBits. It is called before the value is
+// built, so that e is never used as an exponent unchecked.
+func checkIndexBits(s string, e *big.Int, perUnit, extra uint64) error {
+	if extra > MaxIndexBits || !e.IsUint64() || e.Uint64() > (MaxIndexBits-extra)/perUnit {
+		return fmt.Errorf("%w: index %q has more than %d bits", ErrInputTooLarge, s, MaxIndexBits)
+	}
+	return nil
+}
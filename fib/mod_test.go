@@ -0,0 +1,113 @@
+package fib
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestParseIndex(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{"0", "0", nil},
+		{" 42 ", "42", nil},
+		{"1000000000000000000000", "1000000000000000000000", nil},
+		{"10^18", "1000000000000000000", nil},
+		{"2^10^2", "1267650600228229401496703205376", nil},
+		{"1e18", "1000000000000000000", nil},
+		{"3E2", "300", nil},
+		{"0e100000000", "0", nil},
+		{"1^100000000", "1", nil},
+		{"2^65536", "", ErrInputTooLarge},
+		{"1e1000^20000", "", ErrInputTooLarge},
+		{"1e1000^200000", "", ErrInputTooLarge},
+		{"1e100000", "", ErrInputTooLarge},
+		{"10^10^10", "", ErrInputTooLarge},
+		{"-5", "", ErrNegativeInput},
+		{"2^-1", "", nil},
+		{"1.5e3", "", nil},
+		{"abc", "", nil},
+		{"", "", nil},
+	}
+	for _, tt := range tests {
+		start := time.Now()
+		got, err := ParseIndex(tt.in)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("ParseIndex(%q) took %v", tt.in, elapsed)
+		}
+		switch {
+		case tt.want != "":
+			if err != nil || got.String() != tt.want {
+				t.Errorf("ParseIndex(%q) = %v, %v; want %s", tt.in, got, err, tt.want)
+			}
+		case err == nil:
+			t.Errorf("ParseIndex(%q) = %v, want an error", tt.in, got)
+		case tt.err != nil && !errors.Is(err, tt.err):
+			t.Errorf("ParseIndex(%q): got error %v, want %v", tt.in, err, tt.err)
+		}
+	}
+}
+
+func TestFibMod(t *testing.T) {
+	for _, m := range []uint64{1, 2, 7, 10, 1000, 1_000_000_007, MaxPisanoModulus, MaxPisanoModulus + 1, 1<<64 - 59, 1<<64 - 1} {
+		mod := new(big.Int).SetUint64(m)
+		for n := range 300 {
+			got, err := FibMod(big.NewInt(int64(n)), m)
+			want := new(big.Int).Rem(reference(n), mod).Uint64()
+			if err != nil || got != want {
+				t.Fatalf("FibMod(%d, %d) = %d, %v; want %d", n, m, got, err, want)
+			}
+		}
+	}
+
+	// Reducing a huge index by the Pisano period must agree with doubling
+	// over all of its bits.
+	n, _ := ParseIndex("7^5000")
+	for _, m := range []uint64{2, 10, 1_000_000_007, 1 << 20} {
+		got, err := FibMod(n, m)
+		want, _ := fibModPair(n, m)
+		if err != nil || got != want {
+			t.Errorf("FibMod(7^5000, %d) = %d, %v; want %d", m, got, err, want)
+		}
+	}
+
+	if _, err := FibMod(big.NewInt(-1), 10); !errors.Is(err, ErrNegativeInput) {
+		t.Errorf("FibMod(-1, 10): got error %v, want ErrNegativeInput", err)
+	}
+	if _, err := FibMod(big.NewInt(1), 0); !errors.Is(err, ErrModulus) {
+		t.Errorf("FibMod(1, 0): got error %v, want ErrModulus", err)
+	}
+}
+
+func TestPisanoPeriod(t *testing.T) {
+	// bruteForce finds the period by stepping through the sequence mod m.
+	bruteForce := func(m uint64) uint64 {
+		a, b := uint64(0), 1%m
+		for i := uint64(1); ; i++ {
+			a, b = b, (a+b)%m
+			if a == 0 && b == 1%m {
+				return i
+			}
+		}
+	}
+	for m := uint64(1); m <= 500; m++ {
+		got, err := PisanoPeriod(m)
+		if want := bruteForce(m); err != nil || got != want {
+			t.Fatalf("PisanoPeriod(%d) = %d, %v; want %d", m, got, err, want)
+		}
+	}
+	for m, want := range map[uint64]uint64{1000: 1500, 1_000_000: 1_500_000, 1_000_000_000: 1_500_000_000} {
+		if got, err := PisanoPeriod(m); err != nil || got != want {
+			t.Errorf("PisanoPeriod(%d) = %d, %v; want %d", m, got, err, want)
+		}
+	}
+	for _, m := range []uint64{0, MaxPisanoModulus + 1} {
+		if _, err := PisanoPeriod(m); !errors.Is(err, ErrModulus) {
+			t.Errorf("PisanoPeriod(%d): got error %v, want ErrModulus", m, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package fib
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// Option sets a field of the Config used by Run. Options are applied in
+// order, so a later one overrides an earlier one setting the same field.
+type Option func(*Config)
+
+// Run calculates the Fibonacci numbers 0 through maxN like Calculate, on a
+// Calculator configured by opts, so callers need only mention what they
+// want to change from the defaults:
+//
+//	sum, err := fib.Run(ctx, 40, fib.WithWorkers(8), fib.WithAlgorithm(fib.FastDoubling))
+//
+// The Summary holds the results along with what the run cost. It is
+// returned even when err is not nil, describing the results obtained.
+func Run(ctx context.Context, maxN int, opts ...Option) (Summary, error) {
+	c := New(NewConfig(opts...))
+	stop := MeasureMemory()
+	results, elapsed, err := c.Calculate(ctx, maxN)
+	sum := Summary{
+		Elapsed:    elapsed,
+		CacheStats: c.CacheStats(),
+		PerN:       results,
+		MemStats:   stop(),
+		NumCPU:     runtime.NumCPU(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+
+		PeakGoroutines: c.PeakGoroutines(),
+	}
+	for _, r := range results {
+		if r.Err == nil {
+			sum.Completed++
+		} else {
+			sum.Errors++
+		}
+	}
+	return sum, err
+}
+
+// NewConfig returns the Config that results from applying opts to the zero
+// Config, for passing to New.
+func NewConfig(opts ...Option) Config {
+	var cfg Config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithWorkers sets Config.Workers.
+func WithWorkers(n int) Option {
+	return func(c *Config) { c.Workers = n }
+}
+
+// WithCache sets Config.Cache.
+func WithCache(cache Cache) Option {
+	return func(c *Config) { c.Cache = cache }
+}
+
+// WithAlgorithm sets Config.Algorithm.
+func WithAlgorithm(a Algorithm) Option {
+	return func(c *Config) { c.Algorithm = a }
+}
+
+// WithWorkload sets Config.Workload.
+func WithWorkload(w Workload) Option {
+	return func(c *Config) { c.Workload = w }
+}
+
+// WithLogger sets Config.Logger.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Config) { c.Logger = l }
+}
+
+// WithMachineInts sets Config.MachineInts, and Config.AutoBig to autoBig.
+func WithMachineInts(autoBig bool) Option {
+	return func(c *Config) { c.MachineInts, c.AutoBig = true, autoBig }
+}
+
+// WithTimeout sets Config.Timeout and Config.RunTimeout, bounding each
+// computation and the whole run respectively. Zero leaves either
+// unbounded.
+func WithTimeout(each, run time.Duration) Option {
+	return func(c *Config) { c.Timeout, c.RunTimeout = each, run }
+}
+
+// WithRetry sets Config.Retry.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Config) { c.Retry = p }
+}
+
+// WithSeed sets Config.Seed.
+func WithSeed(seed uint64) Option {
+	return func(c *Config) { c.Seed = seed }
+}
+
+// WithClock sets Config.Clock.
+func WithClock(clock Clock) Option {
+	return func(c *Config) { c.Clock = clock }
+}
+
+// WithMaxHeap sets Config.MaxHeap, in bytes.
+func WithMaxHeap(bytes uint64) Option {
+	return func(c *Config) { c.MaxHeap = bytes }
+}
+
+// WithMaxGoroutines sets Config.MaxGoroutines.
+func WithMaxGoroutines(n int) Option {
+	return func(c *Config) { c.MaxGoroutines = n }
+}
+
+// WithDeterministic sets Config.Deterministic.
+func WithDeterministic() Option {
+	return func(c *Config) { c.Deterministic = true }
+}
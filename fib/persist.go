@@ -0,0 +1,100 @@
+package fib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Ranger is implemented by caches that can enumerate their entries, which is
+// what SaveCache needs. All the caches in this package implement it.
+type Ranger interface {
+	// Range calls f for each cached entry until f returns false.
+	Range(f func(n int, v *big.Int) bool)
+}
+
+// cacheFile is the on-disk form of a cache. Values are decimal strings so the
+// file stays readable and tools like jq don't round them to float64.
+type cacheFile struct {
+	Entries []cacheFileEntry `json:"entries"`
+}
+
+type cacheFileEntry struct {
+	N     int    `json:"n"`
+	Value string `json:"value"`
+}
+
+// SaveCache writes every entry of c to the JSON file at path, replacing it
+// atomically. c must implement Ranger.
+func SaveCache(c Cache, path string) error {
+	r, ok := c.(Ranger)
+	if !ok {
+		return fmt.Errorf("fib: cache %T cannot be saved: it does not implement Ranger", c)
+	}
+	var f cacheFile
+	r.Range(func(n int, v *big.Int) bool {
+		f.Entries = append(f.Entries, cacheFileEntry{N: n, Value: v.String()})
+		return true
+	})
+	sort.Slice(f.Entries, func(i, j int) bool { return f.Entries[i].N < f.Entries[j].N })
+
+	data, err := json.MarshalIndent(f, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so that readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadCache stores every entry from the JSON file at path, as written by
+// SaveCache, into c. Entries already in c are kept unless the file has a value
+// for the same n.
+func LoadCache(c Cache, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var f cacheFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("fib: reading cache file %s: %w", path, err)
+	}
+	for _, e := range f.Entries {
+		v, ok := new(big.Int).SetString(e.Value, 10)
+		if !ok || e.N < 0 {
+			return fmt.Errorf("fib: reading cache file %s: bad entry for n=%d", path, e.N)
+		}
+		c.Store(e.N, v)
+	}
+	return nil
+}
+
+// LoadCacheIfExists is like LoadCache but treats a missing file as an empty
+// cache, which is convenient for a first, cold run.
+func LoadCacheIfExists(c Cache, path string) error {
+	err := LoadCache(c, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package fib
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket in the manner of golang.org/x/time/rate, but
+// measured on a Clock so that simulated time paces it too. Tokens accrue
+// every interval up to burst, and each computation takes one before it
+// starts.
+type limiter struct {
+	clock    Clock
+	interval time.Duration
+	burst    float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter returns a limiter allowing rate starts per second in bursts of
+// up to burst, or nil, which never waits, if rate is not positive.
+func newLimiter(clock Clock, rate float64, burst int) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	b := float64(max(burst, 1))
+	return &limiter{
+		clock:    clock,
+		interval: time.Duration(float64(time.Second) / rate),
+		burst:    b,
+		tokens:   b,
+		last:     clock.Now(),
+	}
+}
+
+// wait takes a token, sleeping until one is available. If ctx is done
+// first, the token is given back and the context's error returned.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := l.clock.Now()
+	if now.After(l.last) {
+		l.tokens = min(l.burst, l.tokens+float64(now.Sub(l.last))/float64(l.interval))
+		l.last = now
+	}
+	// Take the token now, even if it has yet to accrue, so that waiters are
+	// served in the order they arrived.
+	l.tokens--
+	delay := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	if err := l.clock.Sleep(ctx, delay); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Package rediscache provides a fib.Cache backed by Redis, so several
+// processes can share memoized Fibonacci values.
+//
+// Entries are kept as fields of a single Redis hash, keyed by n, with values
+// stored as decimal strings.
+package rediscache
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// DefaultKey is the Redis hash used when Options.Key is empty.
+const DefaultKey = "massjunk:fib"
+
+// Options configures a Cache.
+type Options struct {
+	// Addr is the Redis server address, such as "localhost:6379".
+	Addr string
+	// Password and DB select the Redis database to use.
+	Password string
+	DB       int
+	// Key names the hash that holds the entries. Empty means DefaultKey.
+	Key string
+	// Timeout bounds each Redis round trip. Zero means one second.
+	Timeout time.Duration
+}
+
+// Cache is a fib.Cache stored in a Redis hash.
+//
+// The fib.Cache methods cannot report errors, so a failed Load is treated as
+// a miss and a failed Store is dropped. The most recent failure is available
+// from Err.
+type Cache struct {
+	client  *redis.Client
+	key     string
+	timeout time.Duration
+
+	mu  sync.Mutex
+	err error
+}
+
+var (
+	_ fib.Cache  = (*Cache)(nil)
+	_ fib.Ranger = (*Cache)(nil)
+)
+
+// New connects to the Redis server described by opts and returns a Cache
+// using it. It fails if the server cannot be reached.
+func New(opts Options) (*Cache, error) {
+	c := &Cache{
+		client: redis.NewClient(&redis.Options{
+			Addr:     opts.Addr,
+			Password: opts.Password,
+			DB:       opts.DB,
+		}),
+		key:     opts.Key,
+		timeout: opts.Timeout,
+	}
+	if c.key == "" {
+		c.key = DefaultKey
+	}
+	if c.timeout == 0 {
+		c.timeout = time.Second
+	}
+	ctx, cancel := c.context()
+	defer cancel()
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		c.client.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cache) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// record remembers err, if non-nil, for Err.
+func (c *Cache) record(err error) {
+	if err == nil {
+		return
+	}
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+}
+
+// Err returns the most recent error from Redis, or nil.
+func (c *Cache) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Load implements fib.Cache.
+func (c *Cache) Load(n int) (*big.Int, bool) {
+	ctx, cancel := c.context()
+	defer cancel()
+	s, err := c.client.HGet(ctx, c.key, strconv.Itoa(n)).Result()
+	if err != nil {
+		if err != redis.Nil {
+			c.record(err)
+		}
+		return nil, false
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	return v, ok
+}
+
+// Store implements fib.Cache.
+func (c *Cache) Store(n int, v *big.Int) {
+	ctx, cancel := c.context()
+	defer cancel()
+	c.record(c.client.HSet(ctx, c.key, strconv.Itoa(n), v.String()).Err())
+}
+
+// Len implements fib.Cache.
+func (c *Cache) Len() int {
+	ctx, cancel := c.context()
+	defer cancel()
+	l, err := c.client.HLen(ctx, c.key).Result()
+	c.record(err)
+	return int(l)
+}
+
+// Reset implements fib.Cache by deleting the hash.
+func (c *Cache) Reset() {
+	ctx, cancel := c.context()
+	defer cancel()
+	c.record(c.client.Del(ctx, c.key).Err())
+}
+
+// Range implements fib.Ranger.
+func (c *Cache) Range(f func(n int, v *big.Int) bool) {
+	ctx, cancel := c.context()
+	defer cancel()
+	all, err := c.client.HGetAll(ctx, c.key).Result()
+	if err != nil {
+		c.record(err)
+		return
+	}
+	for k, s := range all {
+		n, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		v, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			continue
+		}
+		if !f(n, v) {
+			return
+		}
+	}
+}
+
+// Close closes the connection to Redis.
+func (c *Cache) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,392 @@
+// Package redisqueue distributes Fibonacci computations through Redis
+// streams, so independent worker processes can share a range between them.
+//
+// Publish adds one task per n to a task stream, which workers read as
+// members of a consumer group. A worker acknowledges a task only once its
+// result is on the result stream, and tasks left unacknowledged by a worker
+// that died are claimed by another after Options.ClaimIdle, so every task is
+// processed at least once. A task processed twice is answered from the
+// cache when the workers share one, such as a rediscache.Cache, and Collect
+// keeps only the first result for each n.
+//
+// Neither stream grows without bound: a task is deleted once it has been
+// acknowledged, and a result once Collect has read it. Results nobody
+// collects, such as those of a run published without waiting for them, are
+// trimmed once the result stream holds more than Options.MaxResults.
+package redisqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+const (
+	// DefaultStream is the task stream used when Options.Stream is empty.
+	DefaultStream = "massjunk:tasks"
+	// DefaultResults is the result stream used when Options.Results is
+	// empty.
+	DefaultResults = "massjunk:results"
+	// DefaultGroup is the consumer group used when Options.Group is empty.
+	DefaultGroup = "massjunk:workers"
+	// DefaultClaimIdle is used when Options.ClaimIdle is zero.
+	DefaultClaimIdle = 30 * time.Second
+	// DefaultMaxResults is used when Options.MaxResults is zero.
+	DefaultMaxResults = 100_000
+)
+
+// poll bounds how long a read blocks on Redis, and so how long Work and
+// Collect take to notice that their context is done.
+const poll = time.Second
+
+// Options configures a Queue.
+type Options struct {
+	// Addr is the Redis server address, such as "localhost:6379".
+	Addr string
+	// Password and DB select the Redis database to use.
+	Password string
+	DB       int
+	// Stream, Results and Group name the task stream, the result stream and
+	// the workers' consumer group. Empty means the defaults above.
+	Stream, Results, Group string
+	// Consumer names this process within the group. Empty means its host
+	// name and process ID.
+	Consumer string
+	// ClaimIdle is how long a task may go unacknowledged before another
+	// worker takes it over.
+	ClaimIdle time.Duration
+	// MaxResults is roughly how many entries the result stream keeps; the
+	// oldest are trimmed as new ones are added, collected or not.
+	MaxResults int64
+}
+
+// Queue is a connection to the task and result streams.
+type Queue struct {
+	client *redis.Client
+	opts   Options
+}
+
+// New connects to the Redis server described by opts, creating the task
+// stream and consumer group if they don't exist. It fails if the server
+// cannot be reached.
+func New(opts Options) (*Queue, error) {
+	if opts.Stream == "" {
+		opts.Stream = DefaultStream
+	}
+	if opts.Results == "" {
+		opts.Results = DefaultResults
+	}
+	if opts.Group == "" {
+		opts.Group = DefaultGroup
+	}
+	if opts.Consumer == "" {
+		host, _ := os.Hostname()
+		opts.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if opts.ClaimIdle == 0 {
+		opts.ClaimIdle = DefaultClaimIdle
+	}
+	if opts.MaxResults == 0 {
+		opts.MaxResults = DefaultMaxResults
+	}
+	q := &Queue{
+		client: redis.NewClient(&redis.Options{
+			Addr:     opts.Addr,
+			Password: opts.Password,
+			DB:       opts.DB,
+		}),
+		opts: opts,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := q.client.XGroupCreateMkStream(ctx, opts.Stream, opts.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		q.client.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// Consumer returns the name this process reads tasks under.
+func (q *Queue) Consumer() string { return q.opts.Consumer }
+
+// Close closes the connection to Redis.
+func (q *Queue) Close() error {
+	return q.client.Close()
+}
+
+// Run identifies a batch of published tasks, for Collect.
+type Run struct {
+	ID       string
+	From, To int
+	// after is the last entry of the result stream before the tasks were
+	// published; the run's results all come after it.
+	after string
+}
+
+// Total reports how many results the run will produce.
+func (r Run) Total() int { return r.To - r.From + 1 }
+
+// Publish adds a task for each n from from through to to the task stream.
+func (q *Queue) Publish(ctx context.Context, from, to int) (Run, error) {
+	if from < 0 || to < from {
+		return Run{}, fmt.Errorf("redisqueue: invalid range [%d, %d]", from, to)
+	}
+	run := Run{ID: rand.Text()[:12], From: from, To: to, after: "0"}
+	last, err := q.client.XRevRangeN(ctx, q.opts.Results, "+", "-", 1).Result()
+	if err != nil {
+		return Run{}, err
+	}
+	if len(last) > 0 {
+		run.after = last[0].ID
+	}
+	_, err = q.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for n := from; n <= to; n++ {
+			p.XAdd(ctx, &redis.XAddArgs{
+				Stream: q.opts.Stream,
+				Values: []any{"run", run.ID, "n", n},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return Run{}, err
+	}
+	return run, nil
+}
+
+// Result is the outcome of one task.
+type Result struct {
+	fib.Result
+	Run string
+	// Consumer is the worker that computed it.
+	Consumer string
+}
+
+// Work processes tasks with calc until ctx is done, running as many at once
+// as calc has workers. onResult, if not nil, is called with each result
+// once it has been published; it may be called concurrently.
+//
+// A task whose computation was cut short by ctx is left unacknowledged, so
+// that another worker picks it up.
+func (q *Queue) Work(ctx context.Context, calc *fib.Calculator, onResult func(Result)) error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for range calc.Workers() {
+		wg.Go(func() {
+			if err := q.work(ctx, calc, onResult); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// work is one of Work's loops, taking a task at a time.
+func (q *Queue) work(ctx context.Context, calc *fib.Calculator, onResult func(Result)) error {
+	for ctx.Err() == nil {
+		msg, ok, err := q.next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if !ok {
+			continue
+		}
+		run, _ := msg.Values["run"].(string)
+		n, err := strconv.Atoi(fmt.Sprint(msg.Values["n"]))
+		if err != nil {
+			// A malformed task would only be claimed again and again.
+			q.done(ctx, msg.ID)
+			continue
+		}
+		r := calc.Compute(ctx, n)
+		if r.Err != nil && ctx.Err() != nil {
+			return nil
+		}
+		// A finished result is worth delivering even if ctx ends meanwhile.
+		done := context.WithoutCancel(ctx)
+		res := Result{Result: r, Run: run, Consumer: q.opts.Consumer}
+		if err := q.publishResult(done, res); err != nil {
+			return err
+		}
+		if err := q.done(done, msg.ID); err != nil {
+			return err
+		}
+		if onResult != nil {
+			onResult(res)
+		}
+	}
+	return nil
+}
+
+// done acknowledges the task with the given ID and deletes it from the task
+// stream, which no consumer needs it in any more.
+func (q *Queue) done(ctx context.Context, id string) error {
+	_, err := q.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.XAck(ctx, q.opts.Stream, q.opts.Group, id)
+		p.XDel(ctx, q.opts.Stream, id)
+		return nil
+	})
+	return err
+}
+
+// next returns a task for this consumer: one abandoned by another worker if
+// there is any, otherwise a new one. It reports false if none arrived
+// within poll.
+func (q *Queue) next(ctx context.Context) (redis.XMessage, bool, error) {
+	claimed, _, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   q.opts.Stream,
+		Group:    q.opts.Group,
+		Consumer: q.opts.Consumer,
+		MinIdle:  q.opts.ClaimIdle,
+		Start:    "0",
+		Count:    1,
+	}).Result()
+	if err != nil {
+		return redis.XMessage{}, false, err
+	}
+	if len(claimed) > 0 {
+		return claimed[0], true, nil
+	}
+	streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    q.opts.Group,
+		Consumer: q.opts.Consumer,
+		Streams:  []string{q.opts.Stream, ">"},
+		Count:    1,
+		Block:    poll,
+	}).Result()
+	if err == redis.Nil {
+		return redis.XMessage{}, false, nil
+	}
+	if err != nil {
+		return redis.XMessage{}, false, err
+	}
+	if len(streams) == 0 || len(streams[0].Messages) == 0 {
+		return redis.XMessage{}, false, nil
+	}
+	return streams[0].Messages[0], true, nil
+}
+
+// publishResult adds r to the result stream.
+func (q *Queue) publishResult(ctx context.Context, r Result) error {
+	values := []any{
+		"run", r.Run,
+		"n", r.N,
+		"duration_ns", r.Duration.Nanoseconds(),
+		"cached", r.Cached,
+		"attempts", r.Attempts,
+		"consumer", r.Consumer,
+	}
+	if r.Err != nil {
+		values = append(values, "error", r.Err.Error())
+	} else {
+		values = append(values, "value", r.Value.String())
+	}
+	return q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.opts.Results,
+		MaxLen: q.opts.MaxResults,
+		Approx: true,
+		Values: values,
+	}).Err()
+}
+
+// Collect calls fn with the result of each of run's tasks, in the order they
+// arrive, until it has them all or ctx is done. A task processed more than
+// once is reported only the first time. The run's results are deleted from
+// the result stream as they are read.
+func (q *Queue) Collect(ctx context.Context, run Run, fn func(Result)) error {
+	seen := make(map[int]bool, run.Total())
+	after := run.after
+	for len(seen) < run.Total() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		streams, err := q.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{q.opts.Results, after},
+			Count:   512,
+			Block:   poll,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		var read []string
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				after = msg.ID
+				r, err := parseResult(msg.Values)
+				if err != nil {
+					return fmt.Errorf("redisqueue: result %s: %w", msg.ID, err)
+				}
+				if r.Run != run.ID || r.N < run.From || r.N > run.To {
+					continue
+				}
+				read = append(read, msg.ID)
+				if seen[r.N] {
+					continue
+				}
+				seen[r.N] = true
+				fn(r)
+			}
+		}
+		if len(read) > 0 {
+			if err := q.client.XDel(context.WithoutCancel(ctx), q.opts.Results, read...).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseResult decodes an entry of the result stream.
+func parseResult(values map[string]any) (Result, error) {
+	field := func(k string) string { s, _ := values[k].(string); return s }
+	var r Result
+	var err error
+	r.Run, r.Consumer = field("run"), field("consumer")
+	if r.N, err = strconv.Atoi(field("n")); err != nil {
+		return Result{}, err
+	}
+	ns, _ := strconv.ParseInt(field("duration_ns"), 10, 64)
+	r.Duration = time.Duration(ns)
+	r.Cached = field("cached") == "1"
+	r.Attempts, _ = strconv.Atoi(field("attempts"))
+	if e := field("error"); e != "" {
+		r.Err = errors.New(e)
+		return r, nil
+	}
+	v, ok := new(big.Int).SetString(field("value"), 10)
+	if !ok {
+		return Result{}, fmt.Errorf("F(%d) = %q is not a decimal integer", r.N, field("value"))
+	}
+	r.Value = v
+	return r, nil
+}
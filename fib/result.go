@@ -0,0 +1,53 @@
+package fib
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+)
+
+// Result is the outcome of computing a single Fibonacci number.
+type Result struct {
+	// N is the index that was requested.
+	N int
+	// Value is F(N). It is nil when Err is set.
+	Value *big.Int
+	// Duration is how long the computation of F(N) took, including any time
+	// spent computing uncached sub-problems.
+	Duration time.Duration
+	// Cached reports whether F(N) was already in the cache when requested.
+	Cached bool
+	// Worker is the ID, starting at 1, of the pool worker that computed
+	// F(N), or 0 if it was not computed on a pool.
+	Worker int
+	// Attempts is how many times F(N) was attempted; more than one means
+	// earlier attempts failed and were retried. See Config.Retry.
+	Attempts int
+	// Err is the reason the computation failed, if it did.
+	Err error
+}
+
+// resultJSON is the JSON form of a Result. The value is a decimal string so
+// that consumers using float64 numbers, such as JavaScript, don't round it.
+type resultJSON struct {
+	N          int    `json:"n"`
+	Value      string `json:"value,omitempty"`
+	DurationNS int64  `json:"duration_ns"`
+	Cached     bool   `json:"cached"`
+	Worker     int    `json:"worker,omitempty"`
+	Attempts   int    `json:"attempts,omitempty"`
+	Err        string `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes r as an object with the fields n, value (a decimal
+// string), duration_ns, cached, worker, attempts and, for failures, error.
+func (r Result) MarshalJSON() ([]byte, error) {
+	j := resultJSON{N: r.N, DurationNS: r.Duration.Nanoseconds(), Cached: r.Cached, Worker: r.Worker, Attempts: r.Attempts}
+	if r.Value != nil {
+		j.Value = r.Value.String()
+	}
+	if r.Err != nil {
+		j.Err = r.Err.Error()
+	}
+	return json.Marshal(j)
+}
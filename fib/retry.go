@@ -0,0 +1,68 @@
+package fib
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// DefaultBackoff is the delay before the first retry unless
+// RetryPolicy.Backoff says otherwise.
+const DefaultBackoff = 10 * time.Millisecond
+
+// RetryPolicy says how often, and after what delays, a failed computation is
+// attempted again. The zero value never retries.
+//
+// Invalid input and overflow are never retried, since another attempt
+// would fail the same way, and neither is anything once the run itself has
+// been cancelled. Everything else, including a computation exceeding
+// Config.Timeout, is.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry. Zero means
+	// DefaultBackoff.
+	Backoff time.Duration
+
+	// Multiplier scales the delay after every retry. Values below 1 mean
+	// 2, doubling it each time.
+	Multiplier float64
+
+	// MaxBackoff, if positive, caps the delay.
+	MaxBackoff time.Duration
+
+	// Jitter randomizes each delay by up to this fraction of it in either
+	// direction, so that workers failing together don't retry in lockstep.
+	// It is clamped to [0, 1].
+	Jitter float64
+}
+
+// delay returns how long to wait before the given retry, counting from 1,
+// drawing the jitter from rng.
+func (p RetryPolicy) delay(retry int, rng *rand.Rand) time.Duration {
+	d := p.Backoff
+	if d <= 0 {
+		d = DefaultBackoff
+	}
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 2
+	}
+	f := float64(d) * math.Pow(mult, float64(retry-1))
+	if p.MaxBackoff > 0 {
+		f = min(f, float64(p.MaxBackoff))
+	}
+	if j := min(max(p.Jitter, 0), 1); j > 0 {
+		f *= 1 + j*(2*rng.Float64()-1)
+	}
+	return time.Duration(f)
+}
+
+// retryable reports whether a computation that failed with err is worth
+// another attempt.
+func retryable(err error) bool {
+	return !errors.Is(err, ErrNegativeInput) && !errors.Is(err, ErrInputTooLarge) && !errors.Is(err, ErrOverflow)
+}
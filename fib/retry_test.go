@@ -0,0 +1,19 @@
+package fib
+
+import (
+	"testing"
+	"time"
+)
+
+// TestInvalidInputNotRetried checks that input no attempt can compute
+// fails at once.
+func TestInvalidInputNotRetried(t *testing.T) {
+	c := New(Config{Retry: RetryPolicy{MaxAttempts: 5, Backoff: time.Second}})
+	for _, n := range []int{-1, MaxN + 1} {
+		start := time.Now()
+		r := c.Compute(t.Context(), n)
+		if r.Err == nil || r.Attempts != 1 || time.Since(start) > 100*time.Millisecond {
+			t.Errorf("F(%d) failed with %v after %d attempts and %v; want one attempt", n, r.Err, r.Attempts, time.Since(start))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package fib
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+// Scheduler is how a batch run hands its computations to the workers.
+type Scheduler int
+
+const (
+	// SharedQueue queues every computation of a run in one priority queue
+	// that all the workers take from, as a pool.Pool. Its runs follow
+	// Config.Priority exactly, and can be resized by SetWorkers and paused
+	// by PauseRuns and Job.Pause.
+	SharedQueue Scheduler = iota
+	// WorkStealing deals the computations out to the deques of the workers
+	// of a pool.Stealing. Each worker runs the computation of its own with
+	// the highest Priority first, and once it has none left steals the
+	// one with the lowest from another worker: with the default order, the
+	// largest n, and so the biggest subtree for the naive algorithm, whose
+	// costs vary the most. Its runs keep the workers they started with and
+	// can't be paused.
+	WorkStealing
+)
+
+// Schedulers lists the schedulers.
+func Schedulers() []Scheduler { return []Scheduler{SharedQueue, WorkStealing} }
+
+// Name returns the name ParseScheduler accepts for s.
+func (s Scheduler) Name() string {
+	switch s {
+	case SharedQueue:
+		return "shared"
+	case WorkStealing:
+		return "stealing"
+	}
+	return fmt.Sprintf("Scheduler(%d)", int(s))
+}
+
+// ParseScheduler returns the scheduler with the given name.
+func ParseScheduler(name string) (Scheduler, error) {
+	var names []string
+	for _, s := range Schedulers() {
+		if s.Name() == name {
+			return s, nil
+		}
+		names = append(names, s.Name())
+	}
+	return 0, fmt.Errorf("fib: unknown scheduler %q (want one of %s)", name, strings.Join(names, ", "))
+}
+
+// startStealing is startShared for WorkStealing: it deals run for each n
+// of a batch run out to a new work-stealing pool of workers, returning a
+// function that waits for them all to finish.
+func (c *Calculator) startStealing(runCtx context.Context, cancel context.CancelCauseFunc, workers, maxN int, hooks runHooks, run func(n, worker int)) (wait func()) {
+	s := pool.NewStealing(workers)
+	untrack := c.trackStealing(s)
+	stopWatching := func() {}
+	if c.cfg.StallTimeout > 0 {
+		never := func() bool { return false }
+		stopWatching = c.watchStalls(runCtx, cancel, s.Stats, never, maxN+1-len(hooks.skip))
+	}
+	order := make([]int, 0, maxN+1-len(hooks.skip))
+	for n := 0; n <= maxN; n++ {
+		if !hooks.skip[n] {
+			order = append(order, n)
+		}
+	}
+	priority := c.cfg.Priority
+	if priority == nil {
+		priority = SmallestFirst
+	}
+	// Workers run their newest task first and thieves take the oldest, so
+	// queue the lowest priorities, and the largest n among equals, first.
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Or(cmp.Compare(priority(a), priority(b)), cmp.Compare(b, a))
+	})
+	for _, n := range order {
+		s.Submit(func(worker int) { run(n, worker) })
+	}
+	s.Close()
+	return func() {
+		s.Wait()
+		stopWatching()
+		untrack()
+	}
+}
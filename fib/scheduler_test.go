@@ -0,0 +1,63 @@
+package fib
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWorkStealing(t *testing.T) {
+	for _, priority := range []func(int) int{nil, LargestFirst} {
+		c := New(Config{Workers: 4, Work: -1, Scheduler: WorkStealing, Priority: priority, StallTimeout: time.Second})
+		results, _, err := c.Calculate(t.Context(), 300)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for n, r := range results {
+			if r.N != n || r.Err != nil || r.Value.Cmp(reference(n)) != 0 {
+				t.Fatalf("results[%d] = F(%d) = %v, error %v; want F(%d) = %v", n, r.N, r.Value, r.Err, n, reference(n))
+			}
+		}
+		s := c.PoolStats()
+		if s.Started != 301 || s.Completed != 301 || s.Workers != 0 {
+			t.Errorf("after the run, PoolStats() = %+v; want 301 tasks started and completed, and no workers", s)
+		}
+	}
+}
+
+func TestSharedQueueStats(t *testing.T) {
+	c := New(Config{Workers: 4, Work: -1, Scheduler: SharedQueue})
+	if _, _, err := c.Calculate(t.Context(), 300); err != nil {
+		t.Fatal(err)
+	}
+	s := c.PoolStats()
+	if s.Started != 301 || s.Completed != 301 || s.Workers != 0 {
+		t.Errorf("after the run, PoolStats() = %+v; want 301 tasks started and completed, and no workers", s)
+	}
+}
+
+// BenchmarkScheduler compares the shared-queue and work-stealing
+// schedulers on the naive algorithm, whose computations range from
+// nothing for small n to most of the run for the largest, so that the
+// workers' loads only even out if the scheduler balances them. Run with
+// -cpu to vary GOMAXPROCS.
+func BenchmarkScheduler(b *testing.B) {
+	const maxN = 24
+	for _, sched := range Schedulers() {
+		for _, workers := range []int{1, 4, 16} {
+			b.Run(fmt.Sprintf("%s/workers=%d", sched.Name(), workers), func(b *testing.B) {
+				var steals, started uint64
+				for b.Loop() {
+					c := New(Config{Workers: workers, Work: -1, Algorithm: Naive, Scheduler: sched})
+					if _, _, err := c.Calculate(b.Context(), maxN); err != nil {
+						b.Fatal(err)
+					}
+					s := c.PoolStats()
+					steals += s.Steals
+					started += s.Started
+				}
+				b.ReportMetric(float64(steals)/float64(started), "steals/task")
+			})
+		}
+	}
+}
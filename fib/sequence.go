@@ -0,0 +1,152 @@
+package fib
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// Sequence is an integer sequence a Calculator can compute in place of the
+// Fibonacci numbers, with the same workers, cache and simulated work.
+type Sequence interface {
+	// Name identifies the sequence, as in the -sequence flag.
+	Name() string
+	// Compute returns the nth term of the sequence, n >= 0.
+	Compute(ctx context.Context, n int) (*big.Int, error)
+}
+
+// The built-in sequences.
+var (
+	// FibonacciSequence is the Fibonacci numbers, 0, 1, 1, 2, 3, 5, …. A
+	// Calculator configured with it computes with its Algorithm as usual.
+	FibonacciSequence Sequence = &linear{"fibonacci", []int64{0, 1}}
+	// Lucas is the Lucas numbers, 2, 1, 3, 4, 7, 11, …, which follow the
+	// Fibonacci recurrence from different starting values.
+	Lucas Sequence = &linear{"lucas", []int64{2, 1}}
+	// Tribonacci is the Tribonacci numbers, 0, 0, 1, 1, 2, 4, 7, 13, …,
+	// each the sum of the three before it.
+	Tribonacci Sequence = &linear{"tribonacci", []int64{0, 0, 1}}
+	// Factorial is n!, 1, 1, 2, 6, 24, ….
+	Factorial Sequence = factorial{}
+	// Catalan is the Catalan numbers, 1, 1, 2, 5, 14, 42, ….
+	Catalan Sequence = catalan{}
+)
+
+var (
+	sequencesMu sync.RWMutex
+	sequences   = []Sequence{FibonacciSequence, Lucas, Tribonacci, Factorial, Catalan}
+)
+
+// RegisterSequence makes s available to ParseSequence under its name, which
+// must not already be taken.
+func RegisterSequence(s Sequence) error {
+	sequencesMu.Lock()
+	defer sequencesMu.Unlock()
+	for _, t := range sequences {
+		if t.Name() == s.Name() {
+			return fmt.Errorf("fib: sequence %q is already registered", s.Name())
+		}
+	}
+	sequences = append(sequences, s)
+	return nil
+}
+
+// Sequences lists the registered sequences, the built-in ones first.
+func Sequences() []Sequence {
+	sequencesMu.RLock()
+	defer sequencesMu.RUnlock()
+	return append([]Sequence(nil), sequences...)
+}
+
+// ParseSequence returns the registered sequence with the given name.
+func ParseSequence(name string) (Sequence, error) {
+	var names []string
+	for _, s := range Sequences() {
+		if s.Name() == name {
+			return s, nil
+		}
+		names = append(names, s.Name())
+	}
+	return nil, fmt.Errorf("fib: unknown sequence %q (want one of %s)", name, strings.Join(names, ", "))
+}
+
+// linear is a sequence in which each term is the sum of the len(start)
+// terms before it.
+type linear struct {
+	name  string
+	start []int64
+}
+
+func (s *linear) Name() string { return s.name }
+
+func (s *linear) Compute(ctx context.Context, n int) (*big.Int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w, got %d", ErrNegativeInput, n)
+	}
+	window := make([]*big.Int, len(s.start))
+	for i, v := range s.start {
+		window[i] = big.NewInt(v)
+	}
+	if n < len(window) {
+		return window[n], nil
+	}
+	for i := len(window); i <= n; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		next := new(big.Int)
+		for _, v := range window {
+			next.Add(next, v)
+		}
+		window = append(window[1:], next)
+	}
+	return window[len(window)-1], nil
+}
+
+type factorial struct{}
+
+func (factorial) Name() string { return "factorial" }
+
+func (factorial) Compute(ctx context.Context, n int) (*big.Int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w, got %d", ErrNegativeInput, n)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return new(big.Int).MulRange(1, int64(n)), nil
+}
+
+type catalan struct{}
+
+func (catalan) Name() string { return "catalan" }
+
+// Compute uses C(n) = (2n choose n) / (n + 1).
+func (catalan) Compute(ctx context.Context, n int) (*big.Int, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w, got %d", ErrNegativeInput, n)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	v := new(big.Int).Binomial(int64(2*n), int64(n))
+	return v.Quo(v, big.NewInt(int64(n+1))), nil
+}
+
+// sequenceAlgorithm adapts a Sequence other than the Fibonacci numbers to
+// the Algorithm interface, charging one unit of simulated work per
+// computation.
+type sequenceAlgorithm struct {
+	seq Sequence
+}
+
+func (a sequenceAlgorithm) Name() string { return a.seq.Name() }
+
+func (a sequenceAlgorithm) Compute(ctx context.Context, n int, env *Env) (*big.Int, error) {
+	if err := env.Work(ctx); err != nil {
+		return nil, err
+	}
+	return a.seq.Compute(ctx, n)
+}
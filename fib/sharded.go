@@ -0,0 +1,102 @@
+package fib
+
+import (
+	"math/big"
+	"sync"
+)
+
+// DefaultShards is the shard count NewShardedCache uses when given zero.
+const DefaultShards = 32
+
+// ShardedCache is an unbounded Cache split across a number of independently
+// locked maps, so goroutines working on different n rarely contend for the
+// same lock.
+type ShardedCache struct {
+	shards []cacheShard
+}
+
+type cacheShard struct {
+	mu sync.RWMutex
+	m  map[int]*big.Int
+	_  [32]byte // pad to a 64-byte cache line so neighbouring locks don't false-share
+}
+
+// NewShardedCache returns an empty ShardedCache with the given number of
+// shards. Zero means DefaultShards.
+func NewShardedCache(shards int) *ShardedCache {
+	if shards < 0 {
+		panic("fib: sharded cache needs a positive shard count")
+	}
+	if shards == 0 {
+		shards = DefaultShards
+	}
+	c := &ShardedCache{shards: make([]cacheShard, shards)}
+	for i := range c.shards {
+		c.shards[i].m = make(map[int]*big.Int)
+	}
+	return c
+}
+
+// shard picks the shard for n. Consecutive n, which the calculator tends to
+// touch together, are scattered with a Fibonacci hash.
+func (c *ShardedCache) shard(n int) *cacheShard {
+	h := uint64(n) * 0x9E3779B97F4A7C15
+	return &c.shards[(h>>32)%uint64(len(c.shards))]
+}
+
+// Shards reports the number of shards.
+func (c *ShardedCache) Shards() int { return len(c.shards) }
+
+// Load implements Cache.
+func (c *ShardedCache) Load(n int) (*big.Int, bool) {
+	s := c.shard(n)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[n]
+	return v, ok
+}
+
+// Store implements Cache.
+func (c *ShardedCache) Store(n int, v *big.Int) {
+	s := c.shard(n)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[n] = v
+}
+
+// Len implements Cache.
+func (c *ShardedCache) Len() int {
+	total := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		total += len(s.m)
+		s.mu.RUnlock()
+	}
+	return total
+}
+
+// Reset implements Cache.
+func (c *ShardedCache) Reset() {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		clear(s.m)
+		s.mu.Unlock()
+	}
+}
+
+// Range implements Ranger. Each shard is locked only while it is visited.
+func (c *ShardedCache) Range(f func(n int, v *big.Int) bool) {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		for n, v := range s.m {
+			if !f(n, v) {
+				s.mu.RUnlock()
+				return
+			}
+		}
+		s.mu.RUnlock()
+	}
+}
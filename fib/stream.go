@@ -0,0 +1,68 @@
+package fib
+
+import (
+	"context"
+	"iter"
+)
+
+// ComputeStream calculates the Fibonacci numbers 0 through maxN like
+// Calculate, but delivers each Result on the returned channel as soon as it
+// is computed, in completion order, so consumers can process them
+// incrementally. The channel is closed once every n has been handled.
+//
+// The channel holds up to Config.ResultBuffer results. When it is full the
+// workers wait for the consumer, or, under DropWhenFull, discard results
+// until there is room again. The consumer must keep receiving until the
+// channel is closed, or cancel ctx to stop early; results computed after
+// cancellation are dropped. Invalid arguments are reported as a single
+// Result carrying the error.
+func (c *Calculator) ComputeStream(ctx context.Context, maxN int) <-chan Result {
+	if err := c.checkRange(maxN); err != nil {
+		ch := make(chan Result, 1)
+		ch <- Result{N: maxN, Err: err}
+		close(ch)
+		return ch
+	}
+	ch := make(chan Result, c.bufferSize(maxN))
+	go func() {
+		defer close(ch)
+		c.calculate(ctx, maxN, func(r Result) {
+			if c.cfg.BufferPolicy == DropWhenFull {
+				select {
+				case ch <- r:
+				default:
+					c.dropped.Add(1)
+				}
+				return
+			}
+			select {
+			case ch <- r:
+			case <-ctx.Done():
+			}
+		}, runHooks{})
+	}()
+	return ch
+}
+
+// Results returns an iterator over the same results as ComputeStream, keyed
+// by n. Breaking out of the loop cancels the outstanding computations.
+//
+//	for n, r := range calc.Results(ctx, 1000) {
+//		...
+//	}
+func (c *Calculator) Results(ctx context.Context, maxN int) iter.Seq2[int, Result] {
+	return func(yield func(int, Result) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		ch := c.ComputeStream(ctx, maxN)
+		for r := range ch {
+			if !yield(r.N, r) {
+				cancel()
+				for range ch {
+					// Wait for the workers to stop.
+				}
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package fib
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"time"
+)
+
+// Summary is everything known about a finished batch run.
+type Summary struct {
+	Elapsed time.Duration
+	// Completed and Errors count the successful and failed computations.
+	Completed int
+	Errors    int
+	// CacheStats is the calculator's use of its cache, as of the run's end.
+	CacheStats CacheStats
+	// PerN holds the result for each n, indexed by n.
+	PerN []Result
+	// MemStats is what the run cost in memory.
+	MemStats MemStats
+	// PeakGoroutines is the most goroutines the run had working at once;
+	// see Calculator.PeakGoroutines.
+	PeakGoroutines int
+	// NumCPU and GOMAXPROCS describe the machine the run had, to tell
+	// apart results from different ones.
+	NumCPU     int
+	GOMAXPROCS int
+}
+
+// MemStats is how much memory a run allocated and how hard it worked the
+// garbage collector.
+type MemStats struct {
+	Allocs   uint64        `json:"allocs"`
+	Bytes    uint64        `json:"alloc_bytes"`
+	GCCycles uint32        `json:"gc_cycles"`
+	GCPause  time.Duration `json:"gc_pause_ns"`
+	PeakHeap uint64        `json:"peak_heap_bytes"`
+}
+
+func (m MemStats) String() string {
+	return fmt.Sprintf("%d allocs, %s allocated, %d GC cycles pausing %v, peak heap %s",
+		m.Allocs, formatBytes(m.Bytes), m.GCCycles, m.GCPause, formatBytes(m.PeakHeap))
+}
+
+// heapSampleInterval is how often MeasureMemory samples the heap size to
+// find its peak.
+const heapSampleInterval = 10 * time.Millisecond
+
+// MeasureMemory starts measuring the memory used by the whole process. The
+// returned function stops and reports what was used in between.
+func MeasureMemory() (stop func() MemStats) {
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	peak := before.HeapAlloc
+	quit, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		t := time.NewTicker(heapSampleInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-t.C:
+				peak = max(peak, heapBytes())
+			}
+		}
+	}()
+	return func() MemStats {
+		close(quit)
+		<-done
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		return MemStats{
+			Allocs:   after.Mallocs - before.Mallocs,
+			Bytes:    after.TotalAlloc - before.TotalAlloc,
+			GCCycles: after.NumGC - before.NumGC,
+			GCPause:  time.Duration(after.PauseTotalNs - before.PauseTotalNs),
+			PeakHeap: max(peak, after.HeapAlloc),
+		}
+	}
+}
+
+// heapBytes returns the current size of the heap objects from
+// runtime/metrics, which unlike runtime.ReadMemStats doesn't stop the world.
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// formatBytes formats n with a binary unit prefix.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
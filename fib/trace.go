@@ -0,0 +1,76 @@
+package fib
+
+import (
+	"context"
+	rtrace "runtime/trace"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer records the spans of every computation. It uses the global
+// OpenTelemetry tracer provider, so spans are only exported once the program
+// installs one with otel.SetTracerProvider; until then they cost next to
+// nothing.
+//
+// A run produces a "fib.Calculate" span with one "fib.Compute" child per n.
+// The memoized algorithm adds a "fib.sub" span for every sub-problem it
+// looks up, nested under the value that needed it, so a trace shows the
+// fan-out of the computation and which branches were cut short by a cache
+// hit (cached=true) or by waiting on another worker (shared=true).
+var tracer = otel.Tracer("github.com/ZapGaming/Mass-Junk-Code/fib")
+
+// Span attributes.
+var (
+	attrN         = attribute.Key("fib.n")
+	attrMaxN      = attribute.Key("fib.max_n")
+	attrWorkers   = attribute.Key("fib.workers")
+	attrAlgorithm = attribute.Key("fib.algorithm")
+	attrWorker    = attribute.Key("fib.worker")
+	attrCached    = attribute.Key("fib.cached")
+	attrShared    = attribute.Key("fib.shared")
+	attrAttempts  = attribute.Key("fib.attempts")
+)
+
+// startSpan starts a span named name for the computation of F(n).
+func startSpan(ctx context.Context, name string, n int) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrN.Int(n)))
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Computations are also annotated for execution traces, as written by
+// runtime/trace and read by go tool trace. A run is a "fib.Calculate" task
+// logging max_n, and each computation a "fib.Compute" task logging n, nested
+// under its run's. Within a computation, a region named after the value,
+// such as "F(40)", covers its attempts, a "fib.RateLimit" region the wait
+// for the rate limiter and a "fib.Backoff" region each pause before a retry.
+
+// startTask starts an execution trace task called name that logs n under
+// key.
+func startTask(ctx context.Context, name, key string, n int) (context.Context, *rtrace.Task) {
+	ctx, task := rtrace.NewTask(ctx, name)
+	if rtrace.IsEnabled() {
+		rtrace.Log(ctx, key, strconv.Itoa(n))
+	}
+	return ctx, task
+}
+
+// regionName is the name of the execution trace region of F(n); it is only
+// worth formatting while a trace is being written.
+func regionName(n int) string {
+	if !rtrace.IsEnabled() {
+		return ""
+	}
+	return "F(" + strconv.Itoa(n) + ")"
+}
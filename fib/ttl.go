@@ -0,0 +1,154 @@
+package fib
+
+import (
+	"math/big"
+	"sync"
+	"time"
+)
+
+// TTLCache is a Cache whose entries expire a fixed time after they are
+// stored. Expired entries are never returned by Load, and a background
+// sweeper goroutine periodically removes them so they stop taking up memory.
+// Call Close to stop the sweeper when the cache is no longer needed.
+type TTLCache struct {
+	mu      sync.Mutex
+	clock   Clock
+	ttl     time.Duration
+	items   map[int]ttlEntry
+	expired uint64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type ttlEntry struct {
+	v       *big.Int
+	expires time.Time
+}
+
+// minSweep is the shortest interval between sweeps of a TTLCache.
+const minSweep = time.Millisecond
+
+// NewTTLCache returns an empty TTLCache whose entries live for ttl, swept
+// every sweepEvery. A sweepEvery of zero means ttl/2. Sweeps are at least a
+// millisecond apart, however short ttl is.
+func NewTTLCache(ttl, sweepEvery time.Duration) *TTLCache {
+	return NewTTLCacheClock(ttl, sweepEvery, SystemClock)
+}
+
+// NewTTLCacheClock is like NewTTLCache, but tells the time of storage and
+// expiry by clock, such as a fibtest.Clock. The sweeper still runs on real
+// time, so with a fake clock entries are swept at most sweepEvery after the
+// clock passes their expiry. A nil clock means SystemClock.
+func NewTTLCacheClock(ttl, sweepEvery time.Duration, clock Clock) *TTLCache {
+	if ttl <= 0 {
+		panic("fib: TTL cache ttl must be positive")
+	}
+	if sweepEvery <= 0 {
+		sweepEvery = ttl / 2
+	}
+	sweepEvery = max(sweepEvery, minSweep)
+	if clock == nil {
+		clock = SystemClock
+	}
+	c := &TTLCache{
+		clock: clock,
+		ttl:   ttl,
+		items: make(map[int]ttlEntry),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go c.sweeper(sweepEvery)
+	return c
+}
+
+// Load implements Cache.
+func (c *TTLCache) Load(n int) (*big.Int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[n]
+	if !ok || !c.clock.Now().Before(e.expires) {
+		return nil, false
+	}
+	return e.v, true
+}
+
+// Store implements Cache, using the cache's default time-to-live.
+func (c *TTLCache) Store(n int, v *big.Int) { c.StoreTTL(n, v, c.ttl) }
+
+// StoreTTL records v as the value of F(n) for the given time-to-live,
+// overriding the cache's default for this entry.
+func (c *TTLCache) StoreTTL(n int, v *big.Int, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[n] = ttlEntry{v: v, expires: c.clock.Now().Add(ttl)}
+}
+
+// Len implements Cache. Entries that have expired but not yet been swept are
+// still counted.
+func (c *TTLCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Reset implements Cache.
+func (c *TTLCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.items)
+}
+
+// Range implements Ranger, skipping expired entries.
+func (c *TTLCache) Range(f func(n int, v *big.Int) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	for n, e := range c.items {
+		if now.Before(e.expires) && !f(n, e.v) {
+			return
+		}
+	}
+}
+
+// Stats returns the current size, and the number of expired entries removed
+// by the sweeper as Evictions.
+func (c *TTLCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Size: len(c.items), Evictions: c.expired}
+}
+
+// Close stops the background sweeper. The cache remains usable, but expired
+// entries are no longer removed.
+func (c *TTLCache) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+	<-c.done
+}
+
+func (c *TTLCache) sweeper(every time.Duration) {
+	defer close(c.done)
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-t.C:
+			c.sweep(c.clock.Now())
+		}
+	}
+}
+
+// sweep removes every entry that has expired by now.
+func (c *TTLCache) sweep(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n, e := range c.items {
+		if !now.Before(e.expires) {
+			delete(c.items, n)
+			c.expired++
+		}
+	}
+}
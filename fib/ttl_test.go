@@ -0,0 +1,79 @@
+package fib
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestTTLCacheExpiry(t *testing.T) {
+	clock := &virtualClock{now: time.Unix(0, 0)}
+	c := NewTTLCacheClock(time.Minute, time.Hour, clock)
+	defer c.Close()
+	c.Store(1, big.NewInt(1))
+	c.StoreTTL(2, big.NewInt(1), 2*time.Minute)
+
+	advance := func(d time.Duration) { clock.Sleep(t.Context(), d) }
+	loaded := func(n int) bool {
+		_, ok := c.Load(n)
+		return ok
+	}
+	ranged := func() int {
+		count := 0
+		c.Range(func(int, *big.Int) bool { count++; return true })
+		return count
+	}
+
+	advance(time.Minute - time.Nanosecond)
+	if !loaded(1) || !loaded(2) || ranged() != 2 {
+		t.Fatalf("before the TTL: Load(1) = %t, Load(2) = %t, Range found %d; want both, 2", loaded(1), loaded(2), ranged())
+	}
+	advance(time.Nanosecond)
+	if loaded(1) || !loaded(2) || ranged() != 1 {
+		t.Fatalf("at the TTL: Load(1) = %t, Load(2) = %t, Range found %d; want only the longer-lived entry", loaded(1), loaded(2), ranged())
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d before a sweep, want 2", c.Len())
+	}
+	advance(time.Minute)
+	if loaded(2) || ranged() != 0 {
+		t.Errorf("after both TTLs: Load(2) = %t, Range found %d; want nothing", loaded(2), ranged())
+	}
+}
+
+func TestTTLCacheSweep(t *testing.T) {
+	clock := &virtualClock{now: time.Unix(0, 0)}
+	c := NewTTLCacheClock(time.Second, time.Millisecond, clock)
+	defer c.Close()
+	for n := range 10 {
+		c.Store(n, big.NewInt(int64(n)))
+	}
+	c.StoreTTL(10, big.NewInt(10), time.Hour)
+	clock.Sleep(t.Context(), time.Second)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Len() > 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if s := c.Stats(); s.Size != 1 || s.Evictions != 10 {
+		t.Errorf("after the sweeper ran, Stats() = %+v; want 1 entry and 10 evictions", s)
+	}
+	if _, ok := c.Load(10); !ok {
+		t.Error("the sweeper removed an entry that had not expired")
+	}
+}
+
+// TestTTLCacheTinyTTL checks that a TTL too short to halve into a sweep
+// interval still gets a sweeper, rather than crashing the process.
+func TestTTLCacheTinyTTL(t *testing.T) {
+	c := NewTTLCache(time.Nanosecond, 0)
+	defer c.Close()
+	c.Store(1, big.NewInt(1))
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Len() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d after the sweeper had 5s to run, want 0", c.Len())
+	}
+}
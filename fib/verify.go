@@ -0,0 +1,115 @@
+package fib
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// Mismatch is a result that disagrees with the reference value.
+type Mismatch struct {
+	N         int
+	Got, Want *big.Int // Got is nil if the result was lost
+}
+
+func (m Mismatch) String() string {
+	if m.Got == nil {
+		return fmt.Sprintf("F(%d): missing, want %v", m.N, m.Want)
+	}
+	return fmt.Sprintf("F(%d) = %v, want %v", m.N, m.Got, m.Want)
+}
+
+// Verify checks results, as returned by Calculate, against F(0) through
+// F(len(results)-1) computed independently by plain iteration with
+// math/big, without any cache or concurrency. A result is a mismatch if its
+// value is wrong, or if it is missing or filed under the wrong n. Failed
+// results are skipped, since they carry no value to check.
+func Verify(results []Result) []Mismatch {
+	var mismatches []Mismatch
+	a, b := big.NewInt(0), big.NewInt(1)
+	for n, r := range results {
+		switch {
+		case r.Err != nil:
+		case r.N != n || r.Value == nil:
+			mismatches = append(mismatches, Mismatch{N: n, Want: new(big.Int).Set(a)})
+		case r.Value.Cmp(a) != 0:
+			mismatches = append(mismatches, Mismatch{N: n, Got: r.Value, Want: new(big.Int).Set(a)})
+		}
+		a, b = b, a.Add(a, b)
+	}
+	return mismatches
+}
+
+// Violation is a property of a set of results that SelfCheck found broken.
+type Violation struct {
+	N int
+	// Property is what was broken: "base", "recurrence", "cache" or
+	// "uncached".
+	Property string
+	Detail   string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("F(%d): %s: %s", v.N, v.Property, v.Detail)
+}
+
+// SelfCheck tests results, as returned by c's Calculate, for consistency
+// with each other and with c's cache, without an external reference. For
+// the Fibonacci sequence, F(0) and F(1) must be 0 and 1, and every F(n) the
+// sum of F(n-1) and F(n-2) wherever all three are present. For any
+// sequence, each value must match c's cache entry for n, if it has one,
+// and each value that came from the cache must match c's algorithm run
+// afresh on an empty cache. Failed results are skipped. It costs about as
+// much as one uncached run without simulated work. The error is ctx's, if
+// it is done before the check is.
+func (c *Calculator) SelfCheck(ctx context.Context, results []Result) ([]Violation, error) {
+	var violations []Violation
+	ok := func(n int) bool {
+		return n >= 0 && n < len(results) && results[n].N == n && results[n].Err == nil && results[n].Value != nil
+	}
+	if c.cfg.Sequence == FibonacciSequence {
+		for n := range min(len(results), 2) {
+			if ok(n) && results[n].Value.Cmp(big.NewInt(int64(n))) != 0 {
+				violations = append(violations, Violation{n, "base", fmt.Sprintf("got %v, want %d", results[n].Value, n)})
+			}
+		}
+		for n := 2; n < len(results); n++ {
+			if !ok(n) || !ok(n-1) || !ok(n-2) {
+				continue
+			}
+			sum := new(big.Int).Add(results[n-1].Value, results[n-2].Value)
+			if results[n].Value.Cmp(sum) != 0 {
+				violations = append(violations, Violation{n, "recurrence", fmt.Sprintf("got %v, but F(%d) + F(%d) = %v", results[n].Value, n-1, n-2, sum)})
+			}
+		}
+	}
+
+	fresh := New(Config{
+		Workers:     1,
+		Algorithm:   c.cfg.Algorithm,
+		Sequence:    c.cfg.Sequence,
+		MachineInts: c.cfg.MachineInts,
+		AutoBig:     c.cfg.AutoBig,
+		Work:        -1,
+	})
+	for n := range results {
+		if !ok(n) {
+			continue
+		}
+		r := results[n]
+		if v, cached := c.cfg.Cache.Load(n); cached && v.Cmp(r.Value) != 0 {
+			violations = append(violations, Violation{n, "cache", fmt.Sprintf("got %v, but the cache holds %v", r.Value, v)})
+		}
+		if !r.Cached {
+			continue
+		}
+		v, err := fresh.Fibonacci(ctx, n)
+		if ctx.Err() != nil {
+			return violations, contextError(ctx.Err())
+		}
+		if err == nil && v.Cmp(r.Value) != 0 {
+			violations = append(violations, Violation{n, "uncached", fmt.Sprintf("got %v from the cache, but %v computed afresh", r.Value, v)})
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,91 @@
+package fib
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+// StallError reports a batch run aborted by the Config.StallTimeout
+// watchdog.
+type StallError struct {
+	// Idle is how long the run had gone without a computation finishing.
+	Idle time.Duration
+	// Done and Total count the computations finished and queued by the run.
+	Done, Total int
+	// Active and Queued count the computations that were running and
+	// waiting for a worker.
+	Active, Queued int
+	// Stacks holds the stacks of all goroutines, as by runtime.Stack, when
+	// the stall was detected.
+	Stacks []byte
+}
+
+func (e *StallError) Error() string {
+	return fmt.Sprintf("%v: no computation finished for %v, with %d of %d done, %d running and %d queued",
+		ErrStalled, e.Idle.Round(time.Millisecond), e.Done, e.Total, e.Active, e.Queued)
+}
+
+func (e *StallError) Is(target error) bool { return target == ErrStalled }
+
+// watchStalls cancels the run of ctx through cancel with a *StallError if
+// its pool, reporting its state through stats and working through total
+// tasks, finishes none for Config.StallTimeout while it isn't paused. The
+// returned function stops watching.
+func (c *Calculator) watchStalls(ctx context.Context, cancel context.CancelCauseFunc, stats func() pool.Stats, paused func() bool, total int) (stop func()) {
+	timeout := c.cfg.StallTimeout
+	quit, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		t := time.NewTicker(max(timeout/4, time.Millisecond))
+		defer t.Stop()
+		var completed uint64
+		last := time.Now()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				s := stats()
+				if s.Completed != completed || paused() {
+					completed, last = s.Completed, now
+					continue
+				}
+				if idle := now.Sub(last); idle >= timeout {
+					err := &StallError{
+						Idle:   idle,
+						Done:   int(s.Completed),
+						Total:  total,
+						Active: s.Active,
+						Queued: s.Queued,
+						Stacks: allStacks(),
+					}
+					c.log.ErrorContext(ctx, "run stalled, aborting", "idle", idle, "done", err.Done, "total", total, "active", s.Active, "queued", s.Queued)
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-done
+	}
+}
+
+// allStacks returns the stacks of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,142 @@
+package fib
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Workload is the simulated work charged for every step of a computation.
+type Workload interface {
+	// Do performs one unit of work, drawing any randomness it needs from
+	// env.Rand and pausing with env.Clock. It returns early with the
+	// context's error if ctx is cancelled first.
+	Do(ctx context.Context, env *Env) error
+}
+
+// A Distribution draws the durations of units of work.
+type Distribution interface {
+	Draw(rng *rand.Rand) time.Duration
+}
+
+// Constant is the distribution that always draws the same duration.
+type Constant time.Duration
+
+func (d Constant) Draw(*rand.Rand) time.Duration { return time.Duration(d) }
+
+// Uniform draws durations uniformly from [Min, Max).
+type Uniform struct {
+	Min, Max time.Duration
+}
+
+func (d Uniform) Draw(rng *rand.Rand) time.Duration {
+	if d.Max <= d.Min {
+		return d.Min
+	}
+	return d.Min + time.Duration(rng.Int64N(int64(d.Max-d.Min)))
+}
+
+// Normal draws durations from a normal distribution, clamped at zero.
+type Normal struct {
+	Mean, StdDev time.Duration
+}
+
+func (d Normal) Draw(rng *rand.Rand) time.Duration {
+	return max(0, d.Mean+time.Duration(rng.NormFloat64()*float64(d.StdDev)))
+}
+
+// Exponential draws durations from an exponential distribution, which
+// models the long tail of real request latencies.
+type Exponential struct {
+	Mean time.Duration
+}
+
+func (d Exponential) Draw(rng *rand.Rand) time.Duration {
+	return time.Duration(rng.ExpFloat64() * float64(d.Mean))
+}
+
+// Sleep is an IO-bound workload: each unit sleeps, leaving the CPU free for
+// other workers. It is the workload of the original demo.
+type Sleep struct {
+	Dist Distribution
+}
+
+func (w Sleep) Do(ctx context.Context, env *Env) error {
+	return simulateWorkGo(ctx, env.clock, w.Dist.Draw(env.rng))
+}
+
+// Spin is a CPU-bound workload: each unit busy-loops, so workers beyond the
+// number of CPUs stop adding throughput. It always burns real time, whatever
+// the calculator's Clock.
+type Spin struct {
+	Dist Distribution
+}
+
+func (w Spin) Do(ctx context.Context, env *Env) error {
+	return spin(ctx, w.Dist.Draw(env.rng))
+}
+
+// Mixed spends CPUFraction of every unit spinning and the rest sleeping.
+type Mixed struct {
+	Dist        Distribution
+	CPUFraction float64
+}
+
+func (w Mixed) Do(ctx context.Context, env *Env) error {
+	d := w.Dist.Draw(env.rng)
+	cpu := time.Duration(float64(d) * min(max(w.CPUFraction, 0), 1))
+	if err := spin(ctx, cpu); err != nil {
+		return err
+	}
+	return simulateWorkGo(ctx, env.clock, d-cpu)
+}
+
+// noWork is the workload used when simulated work is disabled. It still
+// checks ctx, so that a long computation can be stopped.
+type noWork struct{}
+
+func (noWork) Do(ctx context.Context, _ *Env) error {
+	if err := ctx.Err(); err != nil {
+		return contextError(err)
+	}
+	return nil
+}
+
+// spin keeps the CPU busy for d, checking now and then whether ctx has been
+// cancelled.
+func spin(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	deadline := time.Now().Add(d)
+	for i := 0; time.Now().Before(deadline); i++ {
+		if i%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return contextError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// newRand returns a random number generator, safe for concurrent use,
+// seeded with seed, or randomly if seed is zero.
+func newRand(seed uint64) *rand.Rand {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return rand.New(&lockedSource{src: rand.NewPCG(seed, seed)})
+}
+
+// lockedSource makes a rand.Source safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
@@ -0,0 +1,245 @@
+// Package history records runs and their per-n results in a SQLite
+// database, so that runs made days apart can be listed and compared.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// ErrNotFound is returned by Store.Run for an unknown run ID.
+var ErrNotFound = errors.New("history: no such run")
+
+// Run describes one recorded run: its configuration, its environment and a
+// summary of how it went.
+type Run struct {
+	ID        int64
+	StartedAt time.Time
+
+	MaxN        int
+	Workers     int
+	Algorithm   string
+	MachineInts bool
+	Work        string
+	Workload    string
+	WorkDist    string
+	Seed        uint64
+
+	GoVersion  string
+	GOOS       string
+	GOARCH     string
+	NumCPU     int
+	GOMAXPROCS int
+
+	Elapsed   time.Duration
+	Completed int
+	Failed    int
+	// Error is the run's error, if it ended with one.
+	Error string
+	// Latency percentiles of the successful computations.
+	Mean, P50, P90, P99, Max time.Duration
+	CacheHitRate             float64
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	started_at     INTEGER NOT NULL,
+	max_n          INTEGER NOT NULL,
+	workers        INTEGER NOT NULL,
+	algorithm      TEXT NOT NULL,
+	machine_ints   INTEGER NOT NULL,
+	work           TEXT NOT NULL,
+	workload       TEXT NOT NULL,
+	work_dist      TEXT NOT NULL,
+	seed           TEXT NOT NULL,
+	go_version     TEXT NOT NULL,
+	goos           TEXT NOT NULL,
+	goarch         TEXT NOT NULL,
+	num_cpu        INTEGER NOT NULL,
+	gomaxprocs     INTEGER NOT NULL,
+	elapsed_ns     INTEGER NOT NULL,
+	completed      INTEGER NOT NULL,
+	failed         INTEGER NOT NULL,
+	error          TEXT NOT NULL,
+	mean_ns        INTEGER NOT NULL,
+	p50_ns         INTEGER NOT NULL,
+	p90_ns         INTEGER NOT NULL,
+	p99_ns         INTEGER NOT NULL,
+	max_ns         INTEGER NOT NULL,
+	cache_hit_rate REAL NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	run_id      INTEGER NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	n           INTEGER NOT NULL,
+	value       TEXT NOT NULL,
+	duration_ns INTEGER NOT NULL,
+	cached      INTEGER NOT NULL,
+	worker      INTEGER NOT NULL,
+	attempts    INTEGER NOT NULL,
+	error       TEXT NOT NULL,
+	PRIMARY KEY (run_id, n)
+);
+`
+
+// runColumns are the columns of runs in the order of Run's fields.
+const runColumns = `id, started_at, max_n, workers, algorithm, machine_ints, work, workload, work_dist, seed,
+	go_version, goos, goarch, num_cpu, gomaxprocs,
+	elapsed_ns, completed, failed, error, mean_ns, p50_ns, p90_ns, p99_ns, max_ns, cache_hit_rate`
+
+// Store is a history database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it if it doesn't exist.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time; a single connection avoids
+	// "database is locked" errors within the process.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("history: opening %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record saves run, ignoring its ID, together with its results, and
+// returns the ID it was given.
+func (s *Store) Record(ctx context.Context, run Run, results []fib.Result) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `INSERT INTO runs (`+runColumns[len("id, "):]+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.StartedAt.UnixNano(), run.MaxN, run.Workers, run.Algorithm, run.MachineInts,
+		run.Work, run.Workload, run.WorkDist, fmt.Sprint(run.Seed),
+		run.GoVersion, run.GOOS, run.GOARCH, run.NumCPU, run.GOMAXPROCS,
+		run.Elapsed, run.Completed, run.Failed, run.Error,
+		run.Mean, run.P50, run.P90, run.P99, run.Max, run.CacheHitRate)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO results
+		(run_id, n, value, duration_ns, cached, worker, attempts, error) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, r := range results {
+		var value, msg string
+		if r.Value != nil {
+			value = r.Value.String()
+		}
+		if r.Err != nil {
+			msg = r.Err.Error()
+		}
+		if _, err := stmt.ExecContext(ctx, id, r.N, value, r.Duration, r.Cached, r.Worker, r.Attempts, msg); err != nil {
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// Runs returns the most recent runs, newest first, up to limit of them; a
+// limit of zero or less means all of them.
+func (s *Store) Runs(ctx context.Context, limit int) ([]Run, error) {
+	if limit <= 0 {
+		limit = -1 // SQLite's "no limit"
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+runColumns+` FROM runs ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var runs []Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// Run returns the run with the given ID.
+func (s *Store) Run(ctx context.Context, id int64) (Run, error) {
+	run, err := scanRun(s.db.QueryRowContext(ctx, `SELECT `+runColumns+` FROM runs WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Run{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return run, err
+}
+
+// Results returns the per-n results of the run with the given ID, in order
+// of n.
+func (s *Store) Results(ctx context.Context, id int64) ([]fib.Result, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT n, value, duration_ns, cached, worker, attempts, error
+		FROM results WHERE run_id = ? ORDER BY n`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var results []fib.Result
+	for rows.Next() {
+		var (
+			r          fib.Result
+			value, msg string
+		)
+		if err := rows.Scan(&r.N, &value, &r.Duration, &r.Cached, &r.Worker, &r.Attempts, &msg); err != nil {
+			return nil, err
+		}
+		if msg != "" {
+			r.Err = errors.New(msg)
+		} else if v, ok := new(big.Int).SetString(value, 10); ok {
+			r.Value = v
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// scanRun reads a row of runColumns.
+func scanRun(row interface{ Scan(...any) error }) (Run, error) {
+	var (
+		r       Run
+		started int64
+		seed    string
+	)
+	err := row.Scan(&r.ID, &started, &r.MaxN, &r.Workers, &r.Algorithm, &r.MachineInts,
+		&r.Work, &r.Workload, &r.WorkDist, &seed,
+		&r.GoVersion, &r.GOOS, &r.GOARCH, &r.NumCPU, &r.GOMAXPROCS,
+		&r.Elapsed, &r.Completed, &r.Failed, &r.Error,
+		&r.Mean, &r.P50, &r.P90, &r.P99, &r.Max, &r.CacheHitRate)
+	if err != nil {
+		return Run{}, err
+	}
+	r.StartedAt = time.Unix(0, started)
+	// Seeds use all 64 bits, more than SQLite's signed integers hold.
+	fmt.Sscan(seed, &r.Seed)
+	return r, nil
+}
@@ -0,0 +1,142 @@
+// Package histogram records latency distributions in the style of
+// HdrHistogram: buckets that are linear within each power of two, so that
+// any recorded value is known to within about 1.5% whatever its magnitude,
+// in a fixed amount of memory.
+package histogram
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// subBits sets the precision: each power of two is split into 1<<subBits
+// linear sub-buckets.
+const (
+	subBits  = 6
+	subCount = 1 << subBits
+	buckets  = (64 - subBits + 1) * subCount
+)
+
+// Histogram counts durations. It is safe for concurrent use; the zero value
+// is ready to use.
+type Histogram struct {
+	counts [buckets]atomic.Uint64
+	total  atomic.Uint64
+	sum    atomic.Int64
+	min    atomic.Int64 // stored as ^value, so that the zero value means "none"
+	max    atomic.Int64
+}
+
+// New returns an empty histogram.
+func New() *Histogram { return new(Histogram) }
+
+// index returns the bucket holding v.
+func index(v uint64) int {
+	if v < subCount {
+		return int(v)
+	}
+	shift := bits.Len64(v) - subBits - 1
+	return (shift+1)*subCount + int(v>>shift) - subCount
+}
+
+// lowest returns the smallest value held by bucket i.
+func lowest(i int) uint64 {
+	if i < subCount {
+		return uint64(i)
+	}
+	shift := i/subCount - 1
+	return uint64(i%subCount+subCount) << shift
+}
+
+// Record adds d to the histogram. Negative durations count as zero.
+func (h *Histogram) Record(d time.Duration) {
+	v := max(int64(d), 0)
+	h.counts[index(uint64(v))].Add(1)
+	h.total.Add(1)
+	h.sum.Add(v)
+	for {
+		old := h.min.Load()
+		if old != 0 && ^old <= v || h.min.CompareAndSwap(old, ^v) {
+			break
+		}
+	}
+	for {
+		old := h.max.Load()
+		if old >= v || h.max.CompareAndSwap(old, v) {
+			break
+		}
+	}
+}
+
+// Count returns the number of values recorded.
+func (h *Histogram) Count() uint64 { return h.total.Load() }
+
+// Min returns the smallest value recorded, or 0 if there are none.
+func (h *Histogram) Min() time.Duration {
+	if m := h.min.Load(); m != 0 {
+		return time.Duration(^m)
+	}
+	return 0
+}
+
+// Max returns the largest value recorded.
+func (h *Histogram) Max() time.Duration { return time.Duration(h.max.Load()) }
+
+// Mean returns the mean of the values recorded, or 0 if there are none.
+func (h *Histogram) Mean() time.Duration {
+	n := h.total.Load()
+	if n == 0 {
+		return 0
+	}
+	return time.Duration(h.sum.Load() / int64(n))
+}
+
+// Quantile returns the value below which the fraction q of the recorded
+// values fall, such as 0.99 for the 99th percentile. The result is the
+// midpoint of the bucket the quantile lands in, capped at Max.
+func (h *Histogram) Quantile(q float64) time.Duration {
+	n := h.total.Load()
+	if n == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(min(max(q, 0), 1) * float64(n)))
+	rank = max(rank, 1)
+	var seen uint64
+	for i := range h.counts {
+		seen += h.counts[i].Load()
+		if seen >= rank {
+			lo := lowest(i)
+			hi := lo
+			if i+1 < buckets {
+				hi = lowest(i+1) - 1
+			}
+			return min(time.Duration(lo+(hi-lo)/2), h.Max())
+		}
+	}
+	return h.Max()
+}
+
+// Summary is a compact description of a histogram.
+type Summary struct {
+	Count uint64        `json:"count"`
+	Mean  time.Duration `json:"mean_ns"`
+	P50   time.Duration `json:"p50_ns"`
+	P90   time.Duration `json:"p90_ns"`
+	P99   time.Duration `json:"p99_ns"`
+	Max   time.Duration `json:"max_ns"`
+}
+
+// Summary returns the count, mean, median, 90th and 99th percentiles and
+// maximum of h.
+func (h *Histogram) Summary() Summary {
+	return Summary{
+		Count: h.Count(),
+		Mean:  h.Mean(),
+		P50:   h.Quantile(0.50),
+		P90:   h.Quantile(0.90),
+		P99:   h.Quantile(0.99),
+		Max:   h.Max(),
+	}
+}
@@ -0,0 +1,75 @@
+// Package singleflight suppresses duplicate concurrent calls: while a call for
+// a key is in flight, later callers for the same key wait for its result
+// instead of starting their own.
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// errPanicked is handed to waiters when the call they were waiting on
+// panicked; the panic itself propagates in the goroutine that made the call.
+var errPanicked = errors.New("singleflight: shared call panicked")
+
+// Group deduplicates calls by key. The zero value is ready to use.
+type Group[K comparable, V any] struct {
+	mu sync.Mutex
+	m  map[K]*call[V]
+}
+
+type call[V any] struct {
+	done chan struct{}
+	val  V
+	err  error
+}
+
+// Do runs fn and returns its results, unless a call for key is already in
+// flight, in which case it waits for that call and returns its results
+// instead. shared reports whether the results came from another caller.
+//
+// A waiting caller gives up with ctx's error if ctx is done first; the call
+// it was waiting on is unaffected. fn runs under its caller's context, so a
+// call that failed with a context's error may only have been cut short by
+// that caller leaving: a waiter whose own ctx is still live then calls
+// again, rather than taking on an error that isn't its own.
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func() (V, error)) (v V, err error, shared bool) {
+	for {
+		g.mu.Lock()
+		if g.m == nil {
+			g.m = make(map[K]*call[V])
+		}
+		c, ok := g.m[key]
+		if !ok {
+			break
+		}
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			if isContextErr(c.err) && ctx.Err() == nil {
+				continue
+			}
+			return c.val, c.err, true
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err(), true
+		}
+	}
+	c := &call[V]{done: make(chan struct{}), err: errPanicked}
+	g.m[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.m, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
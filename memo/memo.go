@@ -0,0 +1,52 @@
+// Package memo memoizes pure functions for concurrent use, the way the fib
+// package memoizes Fibonacci numbers.
+package memo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ZapGaming/Mass-Junk-Code/internal/singleflight"
+)
+
+// Memoize returns a function that computes the same results as fn but
+// remembers them, so fn is called at most once per key that succeeds.
+// The returned function is safe for concurrent use: concurrent calls for a
+// key that is not yet cached share a single call of fn. Errors are not
+// cached; the next call for the key tries again.
+//
+// fn must be pure, and may call the memoized function recursively for
+// other keys, but not for its own key, which would deadlock.
+func Memoize[K comparable, V any](fn func(K) (V, error)) func(K) (V, error) {
+	var (
+		mu     sync.RWMutex
+		cache  = make(map[K]V)
+		flight singleflight.Group[K, V]
+	)
+	load := func(k K) (V, bool) {
+		mu.RLock()
+		defer mu.RUnlock()
+		v, ok := cache[k]
+		return v, ok
+	}
+	return func(k K) (V, error) {
+		if v, ok := load(k); ok {
+			return v, nil
+		}
+		v, err, _ := flight.Do(context.Background(), k, func() (V, error) {
+			// Another call may have finished between the lookup and
+			// the flight.
+			if v, ok := load(k); ok {
+				return v, nil
+			}
+			v, err := fn(k)
+			if err == nil {
+				mu.Lock()
+				cache[k] = v
+				mu.Unlock()
+			}
+			return v, err
+		})
+		return v, err
+	}
+}
@@ -0,0 +1,89 @@
+package memo
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestMemoizeOnce checks that many callers asking for the same keys at
+// once get the right values from a single call of fn per key.
+func TestMemoizeOnce(t *testing.T) {
+	var calls [10]atomic.Int32
+	square := Memoize(func(k int) (int, error) {
+		calls[k].Add(1)
+		// Keep the call in flight long enough for the others to join it.
+		time.Sleep(time.Millisecond)
+		return k * k, nil
+	})
+	var wg sync.WaitGroup
+	for range 50 {
+		for k := range len(calls) {
+			wg.Go(func() {
+				if v, err := square(k); err != nil || v != k*k {
+					t.Errorf("square(%d) = %d, %v; want %d", k, v, err, k*k)
+				}
+			})
+		}
+	}
+	wg.Wait()
+	for k := range calls {
+		if n := calls[k].Load(); n != 1 {
+			t.Errorf("fn called %d times for %d, want once", n, k)
+		}
+	}
+}
+
+func TestMemoizeError(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	var calls int
+	f := Memoize(func(k string) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errFlaky
+		}
+		return len(k), nil
+	})
+	if _, err := f("abc"); !errors.Is(err, errFlaky) {
+		t.Fatalf("first call: got error %v, want %v", err, errFlaky)
+	}
+	// The error wasn't cached, so this calls fn again.
+	if v, err := f("abc"); err != nil || v != 3 {
+		t.Fatalf("second call = %d, %v; want 3", v, err)
+	}
+	if v, err := f("abc"); err != nil || v != 3 || calls != 2 {
+		t.Fatalf("third call = %d, %v after %d calls of fn; want 3 from the cache after 2", v, err, calls)
+	}
+}
+
+// TestMemoizeRecursive memoizes the Fibonacci numbers themselves, each
+// calling the memoized function for the two before it.
+func TestMemoizeRecursive(t *testing.T) {
+	var calls atomic.Int32
+	var fib func(int) (*big.Int, error)
+	fib = Memoize(func(n int) (*big.Int, error) {
+		calls.Add(1)
+		if n < 2 {
+			return big.NewInt(int64(n)), nil
+		}
+		a, err := fib(n - 1)
+		if err != nil {
+			return nil, err
+		}
+		b, err := fib(n - 2)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).Add(a, b), nil
+	})
+	v, err := fib(100)
+	if want, _ := new(big.Int).SetString("354224848179261915075", 10); err != nil || v.Cmp(want) != 0 {
+		t.Fatalf("fib(100) = %v, %v; want %v", v, err, want)
+	}
+	if n := calls.Load(); n != 101 {
+		t.Errorf("fn called %d times for fib(100), want once for each of 0 through 100", n)
+	}
+}
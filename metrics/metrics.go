@@ -0,0 +1,223 @@
+// Package metrics exposes the behaviour of fib calculators as Prometheus
+// metrics: computation counts and latencies per algorithm, cache hits and
+// misses, queue depth, worker pool activity, and the standard Go runtime
+// metrics such as the goroutine count. The counts and gauges are also
+// available as an expvar variable, for environments without Prometheus.
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/pool"
+)
+
+const namespace = "massjunk"
+
+// Metrics collects metrics from any number of calculators into its own
+// registry.
+type Metrics struct {
+	reg *prometheus.Registry
+
+	computations *prometheus.CounterVec
+	latency      *prometheus.HistogramVec
+
+	// Computations of every algorithm, by outcome, for Expvar.
+	computed, cached, failed atomic.Uint64
+
+	mu    sync.Mutex
+	calcs []*fib.Calculator
+}
+
+// New returns a Metrics with a fresh registry that already includes the Go
+// runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		reg: prometheus.NewRegistry(),
+		computations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "computations_total",
+			Help:      "Fibonacci computations, by algorithm and outcome (computed, cached or error).",
+		}, []string{"algorithm", "outcome"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "computation_duration_seconds",
+			Help:      "Time taken by each Fibonacci computation, by algorithm.",
+			Buckets:   prometheus.ExponentialBuckets(1e-6, 4, 12), // 1µs to ~4s
+		}, []string{"algorithm"}),
+	}
+	m.reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.computations,
+		m.latency,
+		calculatorCollector{m},
+	)
+	return m
+}
+
+// Registry returns the registry the metrics are registered with, so callers
+// can add their own.
+func (m *Metrics) Registry() *prometheus.Registry { return m.reg }
+
+// Observer returns a function suitable for fib.Config.OnResult that records
+// each result under the given algorithm name.
+func (m *Metrics) Observer(algorithm string) func(fib.Result) {
+	latency := m.latency.WithLabelValues(algorithm)
+	computed := m.computations.WithLabelValues(algorithm, "computed")
+	cached := m.computations.WithLabelValues(algorithm, "cached")
+	failed := m.computations.WithLabelValues(algorithm, "error")
+	return func(r fib.Result) {
+		switch {
+		case r.Err != nil:
+			failed.Inc()
+			m.failed.Add(1)
+		case r.Cached:
+			cached.Inc()
+			m.cached.Add(1)
+		default:
+			computed.Inc()
+			m.computed.Add(1)
+		}
+		latency.Observe(r.Duration.Seconds())
+	}
+}
+
+// Watch adds calc's cache statistics and queue depth to the metrics. The
+// values of all watched calculators are summed.
+func (m *Metrics) Watch(calc *fib.Calculator) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calcs = append(m.calcs, calc)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{Registry: m.reg})
+}
+
+var (
+	cacheHitsDesc   = prometheus.NewDesc(namespace+"_cache_hits_total", "Cache lookups that found a value.", nil, nil)
+	cacheMissesDesc = prometheus.NewDesc(namespace+"_cache_misses_total", "Cache lookups that found nothing.", nil, nil)
+	cacheSizeDesc   = prometheus.NewDesc(namespace+"_cache_entries", "Entries currently cached.", nil, nil)
+	queueDepthDesc  = prometheus.NewDesc(namespace+"_queue_depth", "Computations waiting for a free worker.", nil, nil)
+	droppedDesc     = prometheus.NewDesc(namespace+"_results_dropped_total", "Streamed results discarded because the consumer fell behind.", nil, nil)
+
+	poolWorkersDesc   = prometheus.NewDesc(namespace+"_pool_workers", "Pool workers of the batch runs in progress, by state (active or idle).", []string{"state"}, nil)
+	poolStartedDesc   = prometheus.NewDesc(namespace+"_pool_tasks_started_total", "Tasks taken from a pool's queue by a worker.", nil, nil)
+	poolCompletedDesc = prometheus.NewDesc(namespace+"_pool_tasks_completed_total", "Tasks finished by a pool's workers.", nil, nil)
+	poolWaitDesc      = prometheus.NewDesc(namespace+"_pool_wait_seconds_total", "Time started tasks spent queued; divide by the tasks started for the average wait.", nil, nil)
+	poolStealsDesc    = prometheus.NewDesc(namespace+"_pool_steals_total", "Tasks a work-stealing pool's workers took from another worker's deque.", nil, nil)
+)
+
+// calculatorCollector reads the watched calculators' state at scrape time.
+type calculatorCollector struct {
+	m *Metrics
+}
+
+func (c calculatorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheSizeDesc
+	ch <- queueDepthDesc
+	ch <- droppedDesc
+	ch <- poolWorkersDesc
+	ch <- poolStartedDesc
+	ch <- poolCompletedDesc
+	ch <- poolWaitDesc
+	ch <- poolStealsDesc
+}
+
+func (c calculatorCollector) Collect(ch chan<- prometheus.Metric) {
+	t := c.m.totals()
+	ps := t.pool
+	ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(t.hits))
+	ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(t.misses))
+	ch <- prometheus.MustNewConstMetric(cacheSizeDesc, prometheus.GaugeValue, float64(t.size))
+	ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(t.queued))
+	ch <- prometheus.MustNewConstMetric(droppedDesc, prometheus.CounterValue, float64(t.dropped))
+	ch <- prometheus.MustNewConstMetric(poolWorkersDesc, prometheus.GaugeValue, float64(ps.Active), "active")
+	ch <- prometheus.MustNewConstMetric(poolWorkersDesc, prometheus.GaugeValue, float64(ps.Idle), "idle")
+	ch <- prometheus.MustNewConstMetric(poolStartedDesc, prometheus.CounterValue, float64(ps.Started))
+	ch <- prometheus.MustNewConstMetric(poolCompletedDesc, prometheus.CounterValue, float64(ps.Completed))
+	ch <- prometheus.MustNewConstMetric(poolWaitDesc, prometheus.CounterValue, ps.Wait.Seconds())
+	ch <- prometheus.MustNewConstMetric(poolStealsDesc, prometheus.CounterValue, float64(ps.Steals))
+}
+
+// totals are the state of the watched calculators, summed.
+type totals struct {
+	hits, misses, dropped uint64
+	size, queued          int
+	pool                  pool.Stats
+}
+
+func (m *Metrics) totals() totals {
+	m.mu.Lock()
+	calcs := append([]*fib.Calculator(nil), m.calcs...)
+	m.mu.Unlock()
+
+	var t totals
+	for _, calc := range calcs {
+		s := calc.CacheStats()
+		t.hits += s.Hits
+		t.misses += s.Misses
+		t.size += s.Size
+		t.queued += calc.QueueDepth()
+		t.dropped += calc.Dropped()
+		p := calc.PoolStats()
+		t.pool.Active += p.Active
+		t.pool.Idle += p.Idle
+		t.pool.Started += p.Started
+		t.pool.Completed += p.Completed
+		t.pool.Wait += p.Wait
+		t.pool.Steals += p.Steals
+	}
+	return t
+}
+
+// Expvar returns a variable for expvar.Publish holding the computation
+// counts, summed over algorithms, and the state of the watched
+// calculators, read whenever it is served. Its JSON form is:
+//
+//	{
+//	  "computations": {"computed": 90, "cached": 12, "error": 0},
+//	  "cache": {"hits": 110, "misses": 92, "entries": 91},
+//	  "queue_depth": 0,
+//	  "results_dropped": 0,
+//	  "pool": {"workers_active": 2, "workers_idle": 2, "tasks_started": 91,
+//	           "tasks_completed": 89, "wait_seconds": 0.013, "steals": 0}
+//	}
+func (m *Metrics) Expvar() expvar.Var {
+	return expvar.Func(func() any {
+		t := m.totals()
+		return map[string]any{
+			"computations": map[string]uint64{
+				"computed": m.computed.Load(),
+				"cached":   m.cached.Load(),
+				"error":    m.failed.Load(),
+			},
+			"cache": map[string]any{
+				"hits":    t.hits,
+				"misses":  t.misses,
+				"entries": t.size,
+			},
+			"queue_depth":     t.queued,
+			"results_dropped": t.dropped,
+			"pool": map[string]any{
+				"workers_active":  t.pool.Active,
+				"workers_idle":    t.pool.Idle,
+				"tasks_started":   t.pool.Started,
+				"tasks_completed": t.pool.Completed,
+				"wait_seconds":    t.pool.Wait.Seconds(),
+				"steals":          t.pool.Steals,
+			},
+		}
+	})
+}
@@ -0,0 +1,185 @@
+// Package pipeline composes concurrent processing out of stages connected
+// by channels: a source generates values, any number of Map stages
+// transform them, each on its own number of goroutines, and a sink
+// consumes the results. FanOut and Merge split and join streams.
+//
+// A typical pipeline computing Fibonacci numbers with a fib.Calculator:
+//
+//	p, ctx := pipeline.New(ctx)
+//	ns := pipeline.Source(p, pipeline.Range(0, 90))
+//	rs := pipeline.Map(p, ns, 8, func(ctx context.Context, n int) (fib.Result, error) {
+//		r := calc.Compute(ctx, n)
+//		return r, r.Err
+//	})
+//	pipeline.Sink(p, rs, func(r fib.Result) error {
+//		fmt.Println(r.N, r.Value)
+//		return nil
+//	})
+//	err := p.Wait()
+//
+// The first stage to fail cancels the pipeline: every stage stops, and Wait
+// returns that error.
+package pipeline
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Pipeline tracks the goroutines of a set of connected stages.
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+}
+
+// New returns an empty pipeline, along with the context its stages run in,
+// which is cancelled when one of them fails or ctx is done.
+func New(ctx context.Context) (*Pipeline, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Pipeline{ctx: ctx, cancel: cancel}, ctx
+}
+
+// Wait blocks until every stage has finished and returns the error that
+// stopped the pipeline, if any: the first error returned by a stage, or
+// the error of the context New was given.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	err := context.Cause(p.ctx)
+	p.cancel(nil)
+	return err
+}
+
+// send delivers v on out, reporting false if the pipeline stopped first.
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Range returns the integers from through to inclusive, for use with
+// Source.
+func Range(from, to int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for n := from; n <= to; n++ {
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// Source starts a stage emitting the values of seq, and returns its output.
+func Source[T any](p *Pipeline, seq iter.Seq[T]) <-chan T {
+	out := make(chan T)
+	p.wg.Go(func() {
+		defer close(out)
+		for v := range seq {
+			if !send(p.ctx, out, v) {
+				return
+			}
+		}
+	})
+	return out
+}
+
+// Map starts a stage applying fn to every value from in on the given
+// number of goroutines, at least one, and returns its output. With more
+// than one goroutine, outputs may be emitted in a different order than
+// their inputs arrived. An error from fn stops the pipeline.
+func Map[In, Out any](p *Pipeline, in <-chan In, workers int, fn func(context.Context, In) (Out, error)) <-chan Out {
+	out := make(chan Out)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Go(func() {
+			for v := range in {
+				r, err := fn(p.ctx, v)
+				if err != nil {
+					p.cancel(err)
+					return
+				}
+				if !send(p.ctx, out, r) {
+					return
+				}
+			}
+		})
+	}
+	p.wg.Go(func() {
+		wg.Wait()
+		close(out)
+	})
+	return out
+}
+
+// Filter starts a stage passing on only the values from in for which keep
+// returns true, and returns its output.
+func Filter[T any](p *Pipeline, in <-chan T, keep func(T) bool) <-chan T {
+	out := make(chan T)
+	p.wg.Go(func() {
+		defer close(out)
+		for v := range in {
+			if keep(v) && !send(p.ctx, out, v) {
+				return
+			}
+		}
+	})
+	return out
+}
+
+// FanOut starts a stage dealing the values from in among n outputs, at
+// least one, each value going to whichever output is ready for it first.
+// Every output must be consumed.
+func FanOut[T any](p *Pipeline, in <-chan T, n int) []<-chan T {
+	outs := make([]<-chan T, max(n, 1))
+	for i := range outs {
+		out := make(chan T)
+		outs[i] = out
+		p.wg.Go(func() {
+			defer close(out)
+			for v := range in {
+				if !send(p.ctx, out, v) {
+					return
+				}
+			}
+		})
+	}
+	return outs
+}
+
+// Merge starts a stage combining the values from all of ins into a single
+// output, in the order they arrive.
+func Merge[T any](p *Pipeline, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		wg.Go(func() {
+			for v := range in {
+				if !send(p.ctx, out, v) {
+					return
+				}
+			}
+		})
+	}
+	p.wg.Go(func() {
+		wg.Wait()
+		close(out)
+	})
+	return out
+}
+
+// Sink starts a stage passing every value from in to fn, one at a time. An
+// error from fn stops the pipeline.
+func Sink[T any](p *Pipeline, in <-chan T, fn func(T) error) {
+	p.wg.Go(func() {
+		for v := range in {
+			if err := fn(v); err != nil {
+				p.cancel(err)
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	for _, workers := range []int{0, 1, 8} {
+		p, _ := New(t.Context())
+		ns := Source(p, Range(1, 1000))
+		sq := Map(p, ns, workers, func(_ context.Context, n int) (int, error) { return n * n, nil })
+		var got []int
+		Sink(p, sq, func(v int) error {
+			got = append(got, v)
+			return nil
+		})
+		if err := p.Wait(); err != nil {
+			t.Fatalf("with %d workers: %v", workers, err)
+		}
+		slices.Sort(got)
+		if len(got) != 1000 {
+			t.Fatalf("with %d workers, got %d values, want 1000", workers, len(got))
+		}
+		for i, v := range got {
+			if n := i + 1; v != n*n {
+				t.Fatalf("with %d workers, value %d is %d, want %d", workers, i, v, n*n)
+			}
+		}
+	}
+}
+
+// TestMapError checks that an error in one stage stops the others, even
+// one reading an endless source, and is what Wait returns.
+func TestMapError(t *testing.T) {
+	errBroken := errors.New("broken")
+	p, ctx := New(t.Context())
+	ns := Source(p, forever())
+	ms := Map(p, ns, 4, func(_ context.Context, n int) (int, error) {
+		if n == 100 {
+			return 0, errBroken
+		}
+		return n, nil
+	})
+	Sink(p, ms, func(int) error { return nil })
+	if err := p.Wait(); !errors.Is(err, errBroken) {
+		t.Fatalf("Wait() = %v, want %v", err, errBroken)
+	}
+	if ctx.Err() == nil {
+		t.Error("the pipeline's context is still live after a stage failed")
+	}
+}
+
+// TestCancel checks that cancelling the context a pipeline was made with
+// stops every stage, leaving no goroutine behind.
+func TestCancel(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(t.Context())
+	p, _ := New(ctx)
+	outs := FanOut(p, Source(p, forever()), 3)
+	ms := make([]<-chan int, len(outs))
+	for i, out := range outs {
+		ms[i] = Map(p, out, 2, func(_ context.Context, n int) (int, error) { return n, nil })
+	}
+	evens := Filter(p, Merge(p, ms...), func(n int) bool { return n%2 == 0 })
+	seen := make(chan struct{})
+	Sink(p, evens, func(n int) error {
+		if n == 1000 {
+			close(seen)
+		}
+		return nil
+	})
+	<-seen
+	cancel()
+	if err := p.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait() = %v, want context.Canceled", err)
+	}
+	// Goroutines that have returned may take a moment to be counted out.
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left after the pipeline stopped, %d before it started", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// forever yields 0, 1, 2 and so on until the consumer stops.
+func forever() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for n := 0; yield(n); n++ {
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Package pool provides bounded worker pools: Pool, whose workers drain a
+// shared priority queue of pending tasks, and Stealing, whose workers each
+// keep a deque of their own and steal from one another when it runs dry.
+package pool
+
+import (
+	"container/heap"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by Submit once the pool has been closed.
+var ErrClosed = errors.New("pool: submit on closed pool")
+
+// Task is a unit of work executed by one of the pool's workers. It is passed
+// the ID of that worker, from 1 to the largest size the pool has had. No two
+// live workers share an ID; a worker started by Resize reuses the lowest ID
+// free at the time.
+type Task func(worker int)
+
+// Pool runs submitted tasks on at most Workers goroutines at a time. Tasks
+// that arrive while every worker is busy wait in a queue, highest priority
+// first and in submission order among equal priorities.
+type Pool struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  taskQueue
+	seq    uint64 // submissions so far, to keep equal priorities FIFO
+	closed bool
+	paused bool
+	size   int    // workers wanted
+	live   int    // workers running, which exceeds size while shrinking
+	ids    []bool // ids[i] reports whether worker i+1 is running
+	wg     sync.WaitGroup
+
+	active    int // workers running a task
+	started   uint64
+	completed uint64
+	waited    time.Duration // total time started tasks spent queued
+}
+
+// New starts a pool with the given number of workers. A pool needs at least
+// one worker to make progress, so values below 1 are treated as 1.
+func New(workers int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Pool{}
+	p.cond = sync.NewCond(&p.mu)
+	p.Resize(workers)
+	return p
+}
+
+// Workers reports the number of worker goroutines the pool is sized for.
+func (p *Pool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+// Resize changes the number of workers to n, or 1 if n is smaller. Growing
+// starts new workers straight away. Shrinking lets tasks in progress run to
+// completion: the surplus workers exit as they finish, instead of taking
+// another task from the queue.
+func (p *Pool) Resize(n int) {
+	n = max(n, 1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.size = n
+	for p.live < p.size {
+		id := p.freeID()
+		p.ids[id-1] = true
+		p.live++
+		p.wg.Add(1)
+		go p.worker(id)
+	}
+	// Wake idle workers so the surplus notice and exit.
+	p.cond.Broadcast()
+}
+
+// freeID returns the lowest worker ID not in use.
+func (p *Pool) freeID() int {
+	for i, used := range p.ids {
+		if !used {
+			return i + 1
+		}
+	}
+	p.ids = append(p.ids, false)
+	return len(p.ids)
+}
+
+// Submit queues t for execution with priority 0. It never blocks; the queue
+// grows as needed.
+func (p *Pool) Submit(t Task) error {
+	return p.SubmitPriority(t, 0)
+}
+
+// SubmitPriority queues t for execution ahead of every queued task with a
+// lower priority.
+func (p *Pool) SubmitPriority(t Task, priority int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	heap.Push(&p.queue, queued{task: t, priority: priority, seq: p.seq, at: time.Now()})
+	p.seq++
+	p.cond.Signal()
+	return nil
+}
+
+// Pause stops workers from starting queued tasks until Resume is called.
+// Tasks already running carry on, and Submit still queues new ones. A
+// paused pool is not drained, so Wait blocks until it is resumed.
+func (p *Pool) Pause() {
+	p.mu.Lock()
+	p.paused = true
+	p.mu.Unlock()
+}
+
+// Resume lets workers start queued tasks again after Pause.
+func (p *Pool) Resume() {
+	p.mu.Lock()
+	p.paused = false
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+// Paused reports whether the pool is paused.
+func (p *Pool) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// Close stops the pool from accepting new tasks. Tasks already queued are
+// still executed; use Wait to block until they have finished.
+func (p *Pool) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.cond.Broadcast()
+}
+
+// Wait blocks until the pool has been closed and every worker has exited.
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// Stats is a snapshot of a pool's workers and tasks.
+type Stats struct {
+	// Workers is the number of workers the pool is sized for.
+	Workers int
+	// Active and Idle count the running workers with and without a task.
+	// Together they exceed Workers while the pool is shrinking.
+	Active int
+	Idle   int
+	// Queued counts the tasks waiting for a worker.
+	Queued int
+	// Started and Completed count the tasks taken from the queue and
+	// finished so far.
+	Started   uint64
+	Completed uint64
+	// Wait is the total time the started tasks spent queued.
+	Wait time.Duration
+	// Steals counts the started tasks a Stealing pool's workers took from
+	// another worker's deque. It is always 0 for a Pool.
+	Steals uint64
+}
+
+// AverageWait returns the mean time a task spent queued before starting, or
+// 0 if none has started.
+func (s Stats) AverageWait() time.Duration {
+	if s.Started == 0 {
+		return 0
+	}
+	return s.Wait / time.Duration(s.Started)
+}
+
+// Stats returns the current state of the pool.
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Stats{
+		Workers:   p.size,
+		Active:    p.active,
+		Idle:      p.live - p.active,
+		Queued:    len(p.queue),
+		Started:   p.started,
+		Completed: p.completed,
+		Wait:      p.waited,
+	}
+}
+
+func (p *Pool) worker(id int) {
+	defer p.wg.Done()
+	ran := false
+	for {
+		t, ok := p.next(id, ran)
+		if !ok {
+			return
+		}
+		t(id)
+		ran = true
+	}
+}
+
+// next pops the queued task for worker id to run next, blocking while the
+// queue is empty or the pool is paused; ran reports that the worker has
+// just finished a task. It reports false, retiring the worker, once the
+// pool is closed and fully drained or has more workers than it wants.
+func (p *Pool) next(id int, ran bool) (Task, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ran {
+		p.active--
+		p.completed++
+	}
+	for {
+		if p.live > p.size {
+			p.live--
+			p.ids[id-1] = false
+			return nil, false
+		}
+		if len(p.queue) > 0 && !p.paused {
+			q := heap.Pop(&p.queue).(queued)
+			p.active++
+			p.started++
+			p.waited += time.Since(q.at)
+			return q.task, true
+		}
+		if p.closed && len(p.queue) == 0 {
+			p.live--
+			return nil, false
+		}
+		p.cond.Wait()
+	}
+}
+
+// queued is a task waiting in the queue.
+type queued struct {
+	task     Task
+	priority int
+	seq      uint64
+	at       time.Time // when it was submitted
+}
+
+// taskQueue is a heap of queued tasks, implementing heap.Interface.
+type taskQueue []queued
+
+func (q taskQueue) Len() int { return len(q) }
+
+func (q taskQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q taskQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *taskQueue) Push(x any) { *q = append(*q, x.(queued)) }
+
+func (q *taskQueue) Pop() any {
+	old := *q
+	n := len(old) - 1
+	x := old[n]
+	old[n] = queued{} // let the task be collected
+	*q = old[:n]
+	return x
+}
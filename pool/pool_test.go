@@ -0,0 +1,43 @@
+package pool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	p := New(2)
+	release := make(chan struct{})
+	running := make(chan struct{}, 5)
+	for range 5 {
+		p.Submit(func(int) {
+			running <- struct{}{}
+			<-release
+		})
+	}
+	<-running
+	<-running
+	// Both workers hold a task, so the other three stay queued.
+	got := p.Stats()
+	got.Wait = 0
+	if want := (Stats{Workers: 2, Active: 2, Queued: 3, Started: 2}); got != want {
+		t.Fatalf("Stats() while busy = %+v, want %+v", got, want)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	p.Close()
+	p.Wait()
+
+	s := p.Stats()
+	if s.Active != 0 || s.Idle != 0 || s.Queued != 0 || s.Started != 5 || s.Completed != 5 {
+		t.Errorf("Stats() after Wait = %+v, want 5 started and completed, no workers or queue", s)
+	}
+	// The last three tasks waited at least the 10ms the first two held the
+	// workers for.
+	if min := 30 * time.Millisecond; s.Wait < min {
+		t.Errorf("Stats().Wait = %v, want at least %v", s.Wait, min)
+	}
+	if s.AverageWait() != s.Wait/5 {
+		t.Errorf("AverageWait() = %v, want %v", s.AverageWait(), s.Wait/5)
+	}
+}
@@ -0,0 +1,219 @@
+package pool
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// Stealing is a worker pool in which each worker keeps its own deque of
+// tasks. A worker runs its newest task first, which keeps a recursive
+// computation depth-first and its data hot in cache, and when it runs dry
+// steals the oldest task of another worker, which tends to be the root of
+// the largest remaining subtree. Tasks that fan out should queue their
+// children with Spawn, so that they land on the spawning worker's deque.
+type Stealing struct {
+	deques []deque
+
+	mu      sync.Mutex
+	cond    *sync.Cond // signalled when a task is queued or the pool closes
+	closed  bool
+	next    int          // deque for the next Submit, round robin
+	pending atomic.Int64 // tasks queued but not yet taken
+	active  atomic.Int64 // tasks taken but not yet finished
+
+	started   atomic.Uint64
+	completed atomic.Uint64
+	steals    atomic.Uint64
+	wg        sync.WaitGroup
+}
+
+// NewStealing starts a work-stealing pool with the given number of workers,
+// at least one.
+func NewStealing(workers int) *Stealing {
+	workers = max(workers, 1)
+	s := &Stealing{deques: make([]deque, workers)}
+	s.cond = sync.NewCond(&s.mu)
+	s.wg.Add(workers)
+	for id := 1; id <= workers; id++ {
+		go s.worker(id)
+	}
+	return s
+}
+
+// Workers reports the number of worker goroutines in the pool.
+func (s *Stealing) Workers() int { return len(s.deques) }
+
+// Stats returns the current state of the pool. It has no single queue to
+// time tasks in, so Wait is always 0.
+func (s *Stealing) Stats() Stats {
+	active := int(s.active.Load())
+	return Stats{
+		Workers:   len(s.deques),
+		Active:    active,
+		Idle:      len(s.deques) - active,
+		Queued:    int(max(s.pending.Load(), 0)),
+		Started:   s.started.Load(),
+		Completed: s.completed.Load(),
+		Steals:    s.steals.Load(),
+	}
+}
+
+// Submit queues t from outside the pool, spreading submissions over the
+// workers' deques in turn.
+func (s *Stealing) Submit(t Task) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	id := s.next + 1
+	s.next = (s.next + 1) % len(s.deques)
+	s.mu.Unlock()
+	s.push(id, t)
+	return nil
+}
+
+// Spawn queues t on the deque of the given worker, and should be called by
+// a task running on that worker with the ID it was passed. Unlike Submit it
+// is allowed after Close, so that work already under way can finish
+// fanning out.
+func (s *Stealing) Spawn(worker int, t Task) {
+	s.push(worker, t)
+}
+
+// Close stops the pool from accepting new tasks through Submit. Tasks
+// already queued, and those they spawn, are still executed.
+func (s *Stealing) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+// Wait blocks until the pool has been closed and every worker has exited.
+func (s *Stealing) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Stealing) push(worker int, t Task) {
+	s.deques[worker-1].pushBack(t)
+	s.pending.Add(1)
+	// Taking the lock orders the wakeup after any idle worker's check of
+	// pending, so that it can't be missed.
+	s.mu.Lock()
+	s.cond.Signal()
+	s.mu.Unlock()
+}
+
+func (s *Stealing) worker(id int) {
+	defer s.wg.Done()
+	for {
+		t, ok := s.take(id)
+		if !ok {
+			return
+		}
+		s.started.Add(1)
+		t(id)
+		s.completed.Add(1)
+		if s.active.Add(-1) == 0 {
+			// Workers waiting for the last task to finish, and any it
+			// spawns, may now be free to exit.
+			s.mu.Lock()
+			s.cond.Broadcast()
+			s.mu.Unlock()
+		}
+	}
+}
+
+// take returns the next task for worker id: its own newest, or failing that
+// the oldest of another worker. It blocks while there is nothing to take,
+// and reports false once the pool is closed and every task has finished;
+// until then a running task might still spawn more.
+func (s *Stealing) take(id int) (Task, bool) {
+	for {
+		t, ok := s.deques[id-1].popBack()
+		if !ok {
+			if t, ok = s.steal(id); ok {
+				s.steals.Add(1)
+			}
+		}
+		if ok {
+			// Count the task as active before it stops being pending, so
+			// that the two are never both zero while it is in hand.
+			s.active.Add(1)
+			s.pending.Add(-1)
+			return t, true
+		}
+		s.mu.Lock()
+		for s.pending.Load() <= 0 && !s.drained() {
+			s.cond.Wait()
+		}
+		done := s.pending.Load() <= 0 && s.drained()
+		s.mu.Unlock()
+		if done {
+			return nil, false
+		}
+	}
+}
+
+// drained reports whether the pool is closed with no task running. s.mu
+// must be held.
+func (s *Stealing) drained() bool {
+	return s.closed && s.active.Load() == 0
+}
+
+// steal takes the oldest task from the first non-empty deque of another
+// worker, starting from a random one so thieves spread out.
+func (s *Stealing) steal(id int) (Task, bool) {
+	n := len(s.deques)
+	start := rand.IntN(n)
+	for i := range n {
+		victim := (start + i) % n
+		if victim == id-1 {
+			continue
+		}
+		if t, ok := s.deques[victim].popFront(); ok {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// deque is a double-ended queue of tasks. Its owner pushes and pops at the
+// back; thieves pop at the front.
+type deque struct {
+	mu    sync.Mutex
+	tasks []Task
+}
+
+func (d *deque) pushBack(t Task) {
+	d.mu.Lock()
+	d.tasks = append(d.tasks, t)
+	d.mu.Unlock()
+}
+
+func (d *deque) popBack() (Task, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := len(d.tasks)
+	if n == 0 {
+		return nil, false
+	}
+	t := d.tasks[n-1]
+	d.tasks[n-1] = nil
+	d.tasks = d.tasks[:n-1]
+	return t, true
+}
+
+func (d *deque) popFront() (Task, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.tasks) == 0 {
+		return nil, false
+	}
+	t := d.tasks[0]
+	d.tasks[0] = nil
+	d.tasks = d.tasks[1:]
+	return t, true
+}
@@ -0,0 +1,73 @@
+package pool
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// fanOut is the recursive workload of the naive Fibonacci algorithm: the
+// task for n spawns the tasks for n-1 and n-2, down to the leaves.
+const fanOut = 20
+
+// spin stands in for the little work done at each node of the tree.
+func spin() {
+	x := 0
+	for i := range 200 {
+		x += i
+	}
+	_ = x
+}
+
+// BenchmarkFanOut compares the shared-queue Pool with the work-stealing
+// pool on the recursive fan-out workload. Run with -cpu to vary GOMAXPROCS.
+func BenchmarkFanOut(b *testing.B) {
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("shared/workers=%d", workers), func(b *testing.B) {
+			for b.Loop() {
+				p := New(workers)
+				var wg sync.WaitGroup
+				var node func(n int) Task
+				node = func(n int) Task {
+					return func(int) {
+						defer wg.Done()
+						spin()
+						if n >= 2 {
+							wg.Add(2)
+							p.Submit(node(n - 1))
+							p.Submit(node(n - 2))
+						}
+					}
+				}
+				wg.Add(1)
+				p.Submit(node(fanOut))
+				wg.Wait()
+				p.Close()
+				p.Wait()
+			}
+		})
+		b.Run(fmt.Sprintf("stealing/workers=%d", workers), func(b *testing.B) {
+			var steals, started uint64
+			for b.Loop() {
+				s := NewStealing(workers)
+				var node func(n int) Task
+				node = func(n int) Task {
+					return func(worker int) {
+						spin()
+						if n >= 2 {
+							s.Spawn(worker, node(n-1))
+							s.Spawn(worker, node(n-2))
+						}
+					}
+				}
+				s.Submit(node(fanOut))
+				s.Close()
+				s.Wait()
+				st := s.Stats()
+				steals += st.Steals
+				started += st.Started
+			}
+			b.ReportMetric(float64(steals)/float64(started), "steals/task")
+		})
+	}
+}
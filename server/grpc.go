@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+	"github.com/ZapGaming/Mass-Junk-Code/server/fibpb"
+)
+
+// errRunCancelled is the cause of a ComputeRange stopped by Cancel.
+var errRunCancelled = errors.New("run cancelled")
+
+// GRPC implements the massjunk.fib.v1.Fibonacci service defined in
+// fibpb/fib.proto on a calculator, for clients in languages other than Go.
+// ComputeRange streams results as they complete, straight off a fib.Job.
+type GRPC struct {
+	fibpb.UnimplementedFibonacciServer
+
+	calc *fib.Calculator
+
+	// MaxN bounds the n a client may ask for, as Server.MaxN does.
+	MaxN int
+
+	mu   sync.Mutex
+	runs map[string]context.CancelCauseFunc // ComputeRange calls in progress
+}
+
+// NewGRPC returns the gRPC service computing with calc.
+func NewGRPC(calc *fib.Calculator) *GRPC {
+	return &GRPC{calc: calc, MaxN: DefaultMaxN, runs: make(map[string]context.CancelCauseFunc)}
+}
+
+// Register adds the service to a gRPC server.
+func (g *GRPC) Register(s grpc.ServiceRegistrar) {
+	fibpb.RegisterFibonacciServer(s, g)
+}
+
+// ComputeRange implements fibpb.FibonacciServer.
+func (g *GRPC) ComputeRange(req *fibpb.ComputeRangeRequest, stream grpc.ServerStreamingServer[fibpb.Result]) error {
+	if req.From < 0 || req.To < req.From {
+		return status.Errorf(codes.InvalidArgument, "need 0 <= from <= to, got from=%d to=%d", req.From, req.To)
+	}
+	if err := checkN(g.MaxN, int(req.To)); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	id := req.RunId
+	if id == "" {
+		// Cancel only asks for the ID, so it mustn't be one another client
+		// could guess or pick for a run of its own.
+		id = rand.Text()
+	}
+	ctx, cancel := context.WithCancelCause(stream.Context())
+	defer cancel(nil)
+	g.mu.Lock()
+	if _, ok := g.runs[id]; ok {
+		g.mu.Unlock()
+		return status.Errorf(codes.AlreadyExists, "a run with ID %q is already in progress", id)
+	}
+	g.runs[id] = cancel
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		delete(g.runs, id)
+		g.mu.Unlock()
+	}()
+
+	job := g.calc.Start(ctx, int(req.To))
+	var sendErr error
+	for r := range job.Results() {
+		if r.N < int(req.From) || sendErr != nil {
+			continue
+		}
+		if sendErr = stream.Send(resultProto(id, r)); sendErr != nil {
+			// The client has gone; cancelling stops the workers, and the
+			// channel closes once they have.
+			cancel(sendErr)
+		}
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	if errors.Is(context.Cause(ctx), errRunCancelled) {
+		return status.Errorf(codes.Canceled, "run %q cancelled", id)
+	}
+	if _, err := job.Wait(); err != nil {
+		return status.Errorf(codeFor(err), "run %q: %v", id, err)
+	}
+	return nil
+}
+
+// codeFor picks the gRPC status code for the error that ended a run, as
+// statusFor does the HTTP status.
+func codeFor(err error) codes.Code {
+	switch {
+	case errors.Is(err, fib.ErrNegativeInput), errors.Is(err, fib.ErrInputTooLarge), errors.Is(err, fib.ErrOverflow):
+		return codes.InvalidArgument
+	case errors.Is(err, fib.ErrTimeout):
+		return codes.DeadlineExceeded
+	case errors.Is(err, fib.ErrCancelled):
+		return codes.Canceled
+	}
+	return codes.Internal
+}
+
+// GetCacheStats implements fibpb.FibonacciServer.
+func (g *GRPC) GetCacheStats(context.Context, *fibpb.GetCacheStatsRequest) (*fibpb.CacheStats, error) {
+	s := g.calc.CacheStats()
+	return &fibpb.CacheStats{
+		Hits:      s.Hits,
+		Misses:    s.Misses,
+		Stores:    s.Stores,
+		Shared:    s.Shared,
+		Evictions: s.Evictions,
+		Size:      int64(s.Size),
+		HitRate:   s.HitRate(),
+	}, nil
+}
+
+// Cancel implements fibpb.FibonacciServer.
+func (g *GRPC) Cancel(_ context.Context, req *fibpb.CancelRequest) (*fibpb.CancelResponse, error) {
+	g.mu.Lock()
+	cancel, ok := g.runs[req.RunId]
+	g.mu.Unlock()
+	if ok {
+		cancel(errRunCancelled)
+	}
+	return &fibpb.CancelResponse{Cancelled: ok}, nil
+}
+
+// resultProto converts r, of the run with the given ID, to its message.
+func resultProto(id string, r fib.Result) *fibpb.Result {
+	m := &fibpb.Result{
+		RunId:      id,
+		N:          int64(r.N),
+		DurationNs: r.Duration.Nanoseconds(),
+		Cached:     r.Cached,
+		Worker:     int64(r.Worker),
+		Attempts:   int64(r.Attempts),
+	}
+	if r.Value != nil {
+		m.Value = r.Value.String()
+	}
+	if r.Err != nil {
+		m.Error = r.Err.Error()
+	}
+	return m
+}
@@ -0,0 +1,326 @@
+// Package server exposes a fib.Calculator as a JSON web service and, with
+// GRPC, as the gRPC service in fibpb/fib.proto.
+//
+// The endpoints are:
+//
+//	GET  /fib/{n}          compute F(n), returning a fib.Result
+//	GET  /fib/{n}/mod/{m}  F(n) mod m, for n as large as 10^18 or 2^1000
+//	POST /fib/range        compute a range, e.g. {"from": 10, "to": 20}
+//	GET  /fib/range/stream stream a range, ?from=10&to=20, as Server-Sent Events
+//	GET  /ws               a WebSocket to start runs, pause, resume or cancel them and
+//	                       receive their results, e.g. {"op": "start", "to": 500}
+//	GET  /cache/stats      the calculator's fib.CacheStats
+//	DELETE /cache          empty the cache, returning {"cleared": entries}
+//	GET  /admin/workers    the number of workers batch runs use
+//	PUT  /admin/workers    change it, e.g. {"workers": 8}, resizing runs in progress
+//	GET  /admin/paused     whether batch runs are paused, as {"paused": false}
+//	PUT  /admin/paused     pause or resume them, keeping their queues, e.g. {"paused": true}
+//
+// Values are encoded as decimal strings, as by fib.Result's MarshalJSON.
+// Failures are reported with a 4xx or 5xx status and a body of the form
+// {"error": "..."}.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// DefaultMaxN is the largest n a Server computes unless told otherwise.
+const DefaultMaxN = 10000
+
+// Server is an http.Handler serving Fibonacci numbers from a calculator.
+type Server struct {
+	calc *fib.Calculator
+	mux  *http.ServeMux
+
+	// MaxN bounds the n a client may ask for, so one request can't tie up
+	// the server indefinitely.
+	MaxN int
+}
+
+// New returns a Server computing with calc.
+func New(calc *fib.Calculator) *Server {
+	s := &Server{calc: calc, mux: http.NewServeMux(), MaxN: DefaultMaxN}
+	s.mux.HandleFunc("GET /fib/{n}", s.handleFib)
+	s.mux.HandleFunc("GET /fib/{n}/mod/{m}", s.handleFibMod)
+	s.mux.HandleFunc("POST /fib/range", s.handleRange)
+	s.mux.HandleFunc("GET /fib/range/stream", s.handleRangeStream)
+	s.mux.Handle("GET /ws", websocket.Server{Handler: s.handleWS, Handshake: sameOrigin})
+	s.mux.HandleFunc("GET /cache/stats", s.handleCacheStats)
+	s.mux.HandleFunc("DELETE /cache", s.handleClearCache)
+	s.mux.HandleFunc("GET /admin/workers", s.handleWorkers)
+	s.mux.HandleFunc("PUT /admin/workers", s.handleSetWorkers)
+	s.mux.HandleFunc("GET /admin/paused", s.handlePaused)
+	s.mux.HandleFunc("PUT /admin/paused", s.handleSetPaused)
+	return s
+}
+
+// Handle registers an additional handler on the server's mux, for endpoints
+// such as metrics that live alongside the API.
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleFib(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("n must be an integer"))
+		return
+	}
+	if err := s.checkN(n); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res := s.calc.Compute(r.Context(), n)
+	if res.Err != nil {
+		writeError(w, statusFor(res.Err), res.Err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// modResponse is the reply to GET /fib/{n}/mod/{m}. N and Value are
+// decimal strings, like fib.Result's value.
+type modResponse struct {
+	N     string `json:"n"`
+	M     uint64 `json:"m"`
+	Value string `json:"value"`
+}
+
+// handleFibMod answers without MaxN, or the calculator: FibMod takes
+// O(log n) steps, and ParseIndex refuses indices too large to build.
+func (s *Server) handleFibMod(w http.ResponseWriter, r *http.Request) {
+	n, err := fib.ParseIndex(r.PathValue("n"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	m, err := strconv.ParseUint(r.PathValue("m"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("m must be a positive integer below 2^64"))
+		return
+	}
+	v, err := fib.FibMod(n, m)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, modResponse{N: n.String(), M: m, Value: strconv.FormatUint(v, 10)})
+}
+
+// rangeRequest is the body of POST /fib/range. From defaults to 0.
+type rangeRequest struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// rangeResponse is the reply to POST /fib/range.
+type rangeResponse struct {
+	ElapsedNS int64        `json:"elapsed_ns"`
+	Results   []fib.Result `json:"results"`
+}
+
+func (s *Server) handleRange(w http.ResponseWriter, r *http.Request) {
+	var req rangeRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+	if req.From < 0 || req.To < req.From {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("need 0 <= from <= to, got from=%d to=%d", req.From, req.To))
+		return
+	}
+	if err := s.checkN(req.To); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	results, elapsed, err := s.calc.Calculate(r.Context(), req.To)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rangeResponse{ElapsedNS: elapsed.Nanoseconds(), Results: results[req.From:]})
+}
+
+// handleRangeStream sends a "result" event, its data a fib.Result in JSON,
+// for each n from the from to the to query parameter as soon as it is
+// computed, then a "done" event with the elapsed time and, if the run
+// failed, its error. Unlike POST /fib/range, it can be consumed with a
+// browser's EventSource.
+func (s *Server) handleRangeStream(w http.ResponseWriter, r *http.Request) {
+	var (
+		req rangeRequest
+		err error
+		q   = r.URL.Query()
+	)
+	if from := q.Get("from"); from != "" {
+		req.From, err = strconv.Atoi(from)
+	}
+	if err == nil {
+		req.To, err = strconv.Atoi(q.Get("to"))
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("from and to must be integers"))
+		return
+	}
+	if req.From < 0 || req.To < req.From {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("need 0 <= from <= to, got from=%d to=%d", req.From, req.To))
+		return
+	}
+	if err := s.checkN(req.To); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	// The job stops once the client disconnects and cancels the request's
+	// context.
+	job := s.calc.Start(r.Context(), req.To)
+	for res := range job.Results() {
+		if res.N < req.From {
+			continue
+		}
+		writeEvent(w, "result", res)
+		rc.Flush()
+	}
+	elapsed, err := job.Wait()
+	done := streamDone{ElapsedNS: elapsed.Nanoseconds()}
+	if err != nil {
+		done.Error = err.Error()
+	}
+	writeEvent(w, "done", done)
+	rc.Flush()
+}
+
+// streamDone is the data of the event ending GET /fib/range/stream.
+type streamDone struct {
+	ElapsedNS int64  `json:"elapsed_ns"`
+	Error     string `json:"error,omitempty"`
+}
+
+// writeEvent writes v, in JSON, as a Server-Sent Event of the given type.
+func writeEvent(w http.ResponseWriter, event string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+func (s *Server) handleCacheStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.calc.CacheStats())
+}
+
+// clearedBody is the body of the response to DELETE /cache.
+type clearedBody struct {
+	Cleared int `json:"cleared"`
+}
+
+func (s *Server) handleClearCache(w http.ResponseWriter, r *http.Request) {
+	c := s.calc.Cache()
+	n := c.Len()
+	c.Reset()
+	writeJSON(w, http.StatusOK, clearedBody{Cleared: n})
+}
+
+// workersBody is the body of GET and PUT /admin/workers.
+type workersBody struct {
+	Workers int `json:"workers"`
+}
+
+func (s *Server) handleWorkers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, workersBody{Workers: s.calc.Workers()})
+}
+
+func (s *Server) handleSetWorkers(w http.ResponseWriter, r *http.Request) {
+	var req workersBody
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+	if err := s.calc.SetWorkers(req.Workers); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
+// pausedBody is the body of GET and PUT /admin/paused.
+type pausedBody struct {
+	Paused bool `json:"paused"`
+}
+
+func (s *Server) handlePaused(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, pausedBody{Paused: s.calc.RunsPaused()})
+}
+
+func (s *Server) handleSetPaused(w http.ResponseWriter, r *http.Request) {
+	var req pausedBody
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+	if req.Paused {
+		s.calc.PauseRuns()
+	} else {
+		s.calc.ResumeRuns()
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
+func (s *Server) checkN(n int) error {
+	return checkN(s.MaxN, n)
+}
+
+// checkN rejects an n above maxN, unless maxN is 0.
+func checkN(maxN, n int) error {
+	if maxN > 0 && n > maxN {
+		return fmt.Errorf("n must be at most %d, got %d", maxN, n)
+	}
+	return nil
+}
+
+// statusFor picks the HTTP status for a computation error.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, fib.ErrNegativeInput), errors.Is(err, fib.ErrInputTooLarge), errors.Is(err, fib.ErrOverflow):
+		return http.StatusBadRequest
+	case errors.Is(err, fib.ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, fib.ErrCancelled):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
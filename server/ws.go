@@ -0,0 +1,179 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// wsCommand is a message from a client of GET /ws.
+type wsCommand struct {
+	// Op is "start", "pause", "resume" or "cancel".
+	Op string `json:"op"`
+	// Run names the run to start or control. A start without one gets an
+	// ID from the server.
+	Run  string `json:"run,omitempty"`
+	From int    `json:"from,omitempty"`
+	To   int    `json:"to,omitempty"`
+}
+
+// wsEvent is a message to a client of GET /ws.
+type wsEvent struct {
+	// Type is "started", "result", "paused", "resumed", "done" or "error".
+	Type string `json:"type"`
+	Run  string `json:"run,omitempty"`
+	// Done and Total count a run's results so far and in all.
+	Done      int         `json:"done,omitempty"`
+	Total     int         `json:"total,omitempty"`
+	Result    *fib.Result `json:"result,omitempty"`
+	ElapsedNS int64       `json:"elapsed_ns,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// wsSession is one client's connection to GET /ws and the runs it started.
+type wsSession struct {
+	s   *Server
+	ws  *websocket.Conn
+	ctx context.Context
+
+	send sync.Mutex // serializes writes to ws
+
+	mu   sync.Mutex
+	jobs map[string]*fib.Job
+	next int // for run IDs the server picks
+	wg   sync.WaitGroup
+}
+
+// handleWS runs a session of GET /ws: the client sends wsCommands in JSON
+// text frames, to start runs and to pause, resume or cancel them, and
+// receives wsEvents, among them each result as it completes. Closing the
+// connection cancels the session's runs.
+func (s *Server) handleWS(ws *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	sess := &wsSession{s: s, ws: ws, ctx: ctx, jobs: make(map[string]*fib.Job)}
+	defer sess.wg.Wait()
+	defer cancel()
+	for {
+		var data []byte
+		if err := websocket.Message.Receive(ws, &data); err != nil {
+			return
+		}
+		var cmd wsCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			sess.write(wsEvent{Type: "error", Error: fmt.Sprintf("bad command: %v", err)})
+			continue
+		}
+		sess.handle(cmd)
+	}
+}
+
+func (sess *wsSession) handle(cmd wsCommand) {
+	switch cmd.Op {
+	case "start":
+		sess.start(cmd)
+		return
+	case "pause", "resume", "cancel":
+	default:
+		sess.write(wsEvent{Type: "error", Run: cmd.Run, Error: fmt.Sprintf("unknown op %q", cmd.Op)})
+		return
+	}
+	sess.mu.Lock()
+	job := sess.jobs[cmd.Run]
+	sess.mu.Unlock()
+	if job == nil {
+		sess.write(wsEvent{Type: "error", Run: cmd.Run, Error: fmt.Sprintf("no run %q in progress", cmd.Run)})
+		return
+	}
+	switch cmd.Op {
+	case "pause":
+		job.Pause()
+		sess.write(wsEvent{Type: "paused", Run: cmd.Run})
+	case "resume":
+		job.Resume()
+		sess.write(wsEvent{Type: "resumed", Run: cmd.Run})
+	case "cancel":
+		// The run's "done" event reports the cancellation.
+		job.Cancel()
+	}
+}
+
+// start starts the run cmd describes, streaming its results from a
+// goroutine of its own.
+func (sess *wsSession) start(cmd wsCommand) {
+	fail := func(err error) {
+		sess.write(wsEvent{Type: "error", Run: cmd.Run, Error: err.Error()})
+	}
+	if cmd.From < 0 || cmd.To < cmd.From {
+		fail(fmt.Errorf("need 0 <= from <= to, got from=%d to=%d", cmd.From, cmd.To))
+		return
+	}
+	if err := sess.s.checkN(cmd.To); err != nil {
+		fail(err)
+		return
+	}
+	sess.mu.Lock()
+	if cmd.Run == "" {
+		sess.next++
+		cmd.Run = fmt.Sprintf("run-%d", sess.next)
+	}
+	if sess.jobs[cmd.Run] != nil {
+		sess.mu.Unlock()
+		fail(fmt.Errorf("run %q is already in progress", cmd.Run))
+		return
+	}
+	job := sess.s.calc.Start(sess.ctx, cmd.To)
+	sess.jobs[cmd.Run] = job
+	sess.mu.Unlock()
+
+	total := cmd.To - cmd.From + 1
+	sess.write(wsEvent{Type: "started", Run: cmd.Run, Total: total})
+	sess.wg.Go(func() {
+		done := 0
+		for r := range job.Results() {
+			if r.N < cmd.From {
+				continue
+			}
+			done++
+			sess.write(wsEvent{Type: "result", Run: cmd.Run, Done: done, Total: total, Result: &r})
+		}
+		elapsed, err := job.Wait()
+		sess.mu.Lock()
+		delete(sess.jobs, cmd.Run)
+		sess.mu.Unlock()
+		ev := wsEvent{Type: "done", Run: cmd.Run, Done: done, Total: total, ElapsedNS: elapsed.Nanoseconds()}
+		if err != nil {
+			ev.Error = err.Error()
+		}
+		sess.write(ev)
+	})
+}
+
+// write sends ev to the client. Errors are left for the read loop to notice
+// when the connection has gone.
+func (sess *wsSession) write(ev wsEvent) {
+	sess.send.Lock()
+	defer sess.send.Unlock()
+	websocket.JSON.Send(sess.ws, ev)
+}
+
+// sameOrigin is the WebSocket handshake check: browsers may only connect
+// from a page served by the server itself, so that other sites cannot drive
+// its runs. Clients that send no Origin, such as command-line tools, are
+// let in.
+func sameOrigin(_ *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+		return fmt.Errorf("cross-origin WebSocket request from %q", origin)
+	}
+	return nil
+}
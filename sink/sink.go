@@ -0,0 +1,318 @@
+// Package sink delivers the results of a run, and its summary, to
+// destinations such as standard output, files and HTTP endpoints.
+//
+// Sinks are chosen with specs, so that they can come from flags or a
+// config file:
+//
+//	stdout            one line per result, then the summary
+//	json:PATH         JSON Lines: one object per result, then {"summary": ...}
+//	csv:PATH          one row per result
+//	http://URL        POST all results and the summary as one JSON document
+//	https://URL       likewise
+//	discard           nothing, for measuring the run alone
+//
+// Parse accepts a comma-separated list of specs and combines them with
+// Multi.
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ZapGaming/Mass-Junk-Code/fib"
+)
+
+// Sink receives the results of a run as they are produced, then its
+// summary. Its methods are not called concurrently.
+type Sink interface {
+	// Result is called with each result, in completion order.
+	Result(r fib.Result) error
+	// Summary is called once, after the last result.
+	Summary(s Summary) error
+	// Close releases the sink's resources, flushing anything buffered.
+	Close() error
+}
+
+// Summary describes a finished run.
+type Summary struct {
+	MaxN      int
+	Elapsed   time.Duration
+	Completed int
+	Errors    int
+	// Err is the run's error, if it ended with one.
+	Err   error
+	Cache fib.CacheStats
+}
+
+type summaryJSON struct {
+	MaxN      int            `json:"max_n"`
+	ElapsedNS int64          `json:"elapsed_ns"`
+	Completed int            `json:"completed"`
+	Errors    int            `json:"errors"`
+	Err       string         `json:"error,omitempty"`
+	Cache     fib.CacheStats `json:"cache"`
+}
+
+// MarshalJSON encodes s with the elapsed time in nanoseconds and the error
+// as a string.
+func (s Summary) MarshalJSON() ([]byte, error) {
+	j := summaryJSON{MaxN: s.MaxN, ElapsedNS: s.Elapsed.Nanoseconds(), Completed: s.Completed, Errors: s.Errors, Cache: s.Cache}
+	if s.Err != nil {
+		j.Err = s.Err.Error()
+	}
+	return json.Marshal(j)
+}
+
+// Parse returns the sink described by a comma-separated list of specs, as
+// listed in the package documentation.
+func Parse(specs string) (Sink, error) {
+	var sinks []Sink
+	for spec := range strings.SplitSeq(specs, ",") {
+		s, err := parse(strings.TrimSpace(spec))
+		if err != nil {
+			Multi(sinks...).Close()
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if len(sinks) == 1 {
+		return sinks[0], nil
+	}
+	return Multi(sinks...), nil
+}
+
+func parse(spec string) (Sink, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "stdout":
+		return Writer(os.Stdout), nil
+	case "discard":
+		return Discard, nil
+	case "json":
+		return JSONFile(arg)
+	case "csv":
+		return CSVFile(arg)
+	case "http", "https":
+		return HTTP(spec, nil), nil
+	}
+	return nil, fmt.Errorf("sink: unknown sink %q (want stdout, json:PATH, csv:PATH, an http(s) URL or discard)", spec)
+}
+
+// Discard is a sink that drops everything.
+var Discard Sink = discard{}
+
+type discard struct{}
+
+func (discard) Result(fib.Result) error { return nil }
+func (discard) Summary(Summary) error   { return nil }
+func (discard) Close() error            { return nil }
+
+// Multi returns a sink that passes everything to each of sinks in turn.
+// A failing sink doesn't stop the others; the errors are joined.
+func Multi(sinks ...Sink) Sink { return multi(sinks) }
+
+type multi []Sink
+
+func (m multi) Result(r fib.Result) error {
+	var errs []error
+	for _, s := range m {
+		errs = append(errs, s.Result(r))
+	}
+	return errors.Join(errs...)
+}
+
+func (m multi) Summary(sum Summary) error {
+	var errs []error
+	for _, s := range m {
+		errs = append(errs, s.Summary(sum))
+	}
+	return errors.Join(errs...)
+}
+
+func (m multi) Close() error {
+	var errs []error
+	for _, s := range m {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Writer returns a sink printing a line per result, and the summary, to w.
+func Writer(w io.Writer) Sink { return &writer{w: bufio.NewWriter(w)} }
+
+type writer struct {
+	w *bufio.Writer
+}
+
+func (s *writer) Result(r fib.Result) error {
+	if r.Err != nil {
+		_, err := fmt.Fprintf(s.w, "F(%d): error: %v\n", r.N, r.Err)
+		return err
+	}
+	_, err := fmt.Fprintf(s.w, "F(%d) = %v (%v, cached %t, worker %d)\n", r.N, r.Value, r.Duration, r.Cached, r.Worker)
+	return err
+}
+
+func (s *writer) Summary(sum Summary) error {
+	fmt.Fprintf(s.w, "Computed %d of %d in %v with %d errors; cache: %v\n", sum.Completed, sum.MaxN+1, sum.Elapsed, sum.Errors, sum.Cache)
+	if sum.Err != nil {
+		fmt.Fprintf(s.w, "Error: %v\n", sum.Err)
+	}
+	return s.w.Flush()
+}
+
+func (s *writer) Close() error { return s.w.Flush() }
+
+// JSONFile returns a sink writing JSON Lines to the file at path: an
+// object per result, as encoded by fib.Result's MarshalJSON, then
+// {"summary": ...}.
+func JSONFile(path string) (Sink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	return &jsonFile{f: f, w: w, enc: json.NewEncoder(w)}, nil
+}
+
+type jsonFile struct {
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (s *jsonFile) Result(r fib.Result) error { return s.enc.Encode(r) }
+
+func (s *jsonFile) Summary(sum Summary) error {
+	return s.enc.Encode(struct {
+		Summary Summary `json:"summary"`
+	}{sum})
+}
+
+func (s *jsonFile) Close() error {
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// CSVColumns are the columns of a CSV sink.
+var CSVColumns = []string{"n", "value", "duration_ns", "cached", "worker", "error"}
+
+// CSVFile returns a sink writing a row per result to the file at path,
+// under a header of CSVColumns. The summary isn't written.
+func CSVFile(path string) (Sink, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &csvFile{f: f, w: csv.NewWriter(f)}
+	s.w.Write(CSVColumns)
+	return s, nil
+}
+
+type csvFile struct {
+	f *os.File
+	w *csv.Writer
+}
+
+func (s *csvFile) Result(r fib.Result) error {
+	return s.w.Write(CSVRecord(r))
+}
+
+func (s *csvFile) Summary(Summary) error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *csvFile) Close() error {
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// CSVRecord returns the fields of r under CSVColumns.
+func CSVRecord(r fib.Result) []string {
+	value, errText := "", ""
+	if r.Value != nil {
+		value = r.Value.String()
+	}
+	if r.Err != nil {
+		errText = r.Err.Error()
+	}
+	return []string{
+		strconv.Itoa(r.N),
+		value,
+		strconv.FormatInt(r.Duration.Nanoseconds(), 10),
+		strconv.FormatBool(r.Cached),
+		strconv.Itoa(r.Worker),
+		errText,
+	}
+}
+
+// httpTimeout bounds the POST of an HTTP sink.
+const httpTimeout = 30 * time.Second
+
+// HTTP returns a sink that collects the results and, with the summary,
+// POSTs them to url as {"results": [...], "summary": {...}}. A nil client
+// means http.DefaultClient.
+func HTTP(url string, client *http.Client) Sink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpSink{url: url, client: client}
+}
+
+type httpSink struct {
+	url     string
+	client  *http.Client
+	results []fib.Result
+}
+
+func (s *httpSink) Result(r fib.Result) error {
+	s.results = append(s.results, r)
+	return nil
+}
+
+func (s *httpSink) Summary(sum Summary) error {
+	body, err := json.Marshal(struct {
+		Results []fib.Result `json:"results"`
+		Summary Summary      `json:"summary"`
+	}{s.results, sum})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sink: POST %s: %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+func (s *httpSink) Close() error { return nil }